@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+	"github.com/netdata/go.d.plugin/pkg/discovery/consul"
+	"github.com/netdata/go.d.plugin/pkg/discovery/docker"
+	"github.com/netdata/go.d.plugin/pkg/discovery/kubernetes"
+
+	"github.com/netdata/go-orchestrator"
+	"github.com/netdata/go-orchestrator/logger"
+	"github.com/netdata/go-orchestrator/pkg/multipath"
+
+	"gopkg.in/yaml.v2"
+)
+
+var discoveryLog = logger.New("go.d", "discovery", "main")
+
+// discoveryConfig is the service discovery configuration, every section is a list of the discoverer configurations.
+// Sections are kept raw to apply the discoverer defaults before unmarshalling.
+type discoveryConfig struct {
+	Consul     []map[string]interface{} `yaml:"consul"`
+	Kubernetes []map[string]interface{} `yaml:"kubernetes"`
+	Docker     []map[string]interface{} `yaml:"docker"`
+}
+
+// newDiscoverers creates discoverers from the '<plugin name>/discovery.conf' file, the file is optional.
+func newDiscoverers(plugin *orchestrator.Orchestrator) []discovery.Discoverer {
+	path, err := plugin.ConfigPath.Find(plugin.Name + "/discovery.conf")
+	if err != nil {
+		if !multipath.IsNotFound(err) {
+			discoveryLog.Error(err)
+		}
+		return nil
+	}
+
+	var conf discoveryConfig
+	if err := loadYAML(path, &conf); err != nil {
+		discoveryLog.Errorf("error on loading '%s' : %v", path, err)
+		return nil
+	}
+
+	var discoverers []discovery.Discoverer
+
+	for _, raw := range conf.Consul {
+		cfg := consul.NewDefaultConfig()
+		if err := remarshal(raw, &cfg); err != nil {
+			discoveryLog.Errorf("skipping consul discovery : %v", err)
+			continue
+		}
+
+		d, err := consul.New(cfg)
+		if err != nil {
+			discoveryLog.Errorf("skipping consul discovery : %v", err)
+			continue
+		}
+		discoverers = append(discoverers, d)
+	}
+
+	for _, raw := range conf.Kubernetes {
+		cfg := kubernetes.NewDefaultConfig()
+		if err := remarshal(raw, &cfg); err != nil {
+			discoveryLog.Errorf("skipping kubernetes discovery : %v", err)
+			continue
+		}
+
+		d, err := kubernetes.New(cfg)
+		if err != nil {
+			discoveryLog.Errorf("skipping kubernetes discovery : %v", err)
+			continue
+		}
+		discoverers = append(discoverers, d)
+	}
+
+	for _, raw := range conf.Docker {
+		cfg := docker.NewDefaultConfig()
+		if err := remarshal(raw, &cfg); err != nil {
+			discoveryLog.Errorf("skipping docker discovery : %v", err)
+			continue
+		}
+
+		d, err := docker.New(cfg)
+		if err != nil {
+			discoveryLog.Errorf("skipping docker discovery : %v", err)
+			continue
+		}
+		discoverers = append(discoverers, d)
+	}
+
+	return discoverers
+}
+
+// staticJobs returns the full names of the jobs the orchestrator creates from the modules configuration files.
+// Modules without a configuration file run one default job named after the module.
+func staticJobs(plugin *orchestrator.Orchestrator) map[string]bool {
+	jobs := make(map[string]bool)
+
+	dirName := plugin.ModulesConfigDirName
+	if dirName == "" {
+		dirName = plugin.Name
+	}
+
+	for name, creator := range plugin.Registry {
+		if !isModuleEnabled(plugin.Config, name, creator.DisabledByDefault) {
+			continue
+		}
+
+		path, err := plugin.ConfigPath.Find(fmt.Sprintf("%s/%s.conf", dirName, name))
+		if err != nil {
+			if multipath.IsNotFound(err) {
+				jobs[name] = true
+			}
+			continue
+		}
+
+		var conf struct {
+			Jobs []map[string]interface{} `yaml:"jobs"`
+		}
+		if err := loadYAML(path, &conf); err != nil {
+			continue
+		}
+
+		for _, job := range conf.Jobs {
+			jobName, _ := job["name"].(string)
+			if jobName == "" || jobName == name {
+				jobs[name] = true
+			} else {
+				jobs[name+"_"+jobName] = true
+			}
+		}
+	}
+
+	return jobs
+}
+
+// isModuleEnabled repeats the orchestrator modules enabling logic.
+func isModuleEnabled(conf *orchestrator.Config, name string, disabledByDefault bool) bool {
+	if run, ok := conf.Modules[name]; ok {
+		return run
+	}
+	return !disabledByDefault && conf.DefaultRun
+}
+
+func loadYAML(path string, dst interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return yaml.NewDecoder(f).Decode(dst)
+}
+
+func remarshal(src interface{}, dst interface{}) error {
+	b, err := yaml.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, dst)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -11,23 +12,29 @@ import (
 	"github.com/netdata/go-orchestrator/logger"
 	"github.com/netdata/go-orchestrator/pkg/multipath"
 
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+
 	_ "github.com/netdata/go.d.plugin/modules/activemq"
 	_ "github.com/netdata/go.d.plugin/modules/apache"
 	_ "github.com/netdata/go.d.plugin/modules/bind"
 	_ "github.com/netdata/go.d.plugin/modules/consul"
 	_ "github.com/netdata/go.d.plugin/modules/dnsquery"
+	_ "github.com/netdata/go.d.plugin/modules/docker"
 	_ "github.com/netdata/go.d.plugin/modules/docker_engine"
 	_ "github.com/netdata/go.d.plugin/modules/example"
 	_ "github.com/netdata/go.d.plugin/modules/fluentd"
 	_ "github.com/netdata/go.d.plugin/modules/freeradius"
 	_ "github.com/netdata/go.d.plugin/modules/httpcheck"
+	_ "github.com/netdata/go.d.plugin/modules/jolokia"
 	_ "github.com/netdata/go.d.plugin/modules/k8s_kubelet"
 	_ "github.com/netdata/go.d.plugin/modules/k8s_kubeproxy"
+	_ "github.com/netdata/go.d.plugin/modules/k8s_state"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd2"
 	_ "github.com/netdata/go.d.plugin/modules/logstash"
 	_ "github.com/netdata/go.d.plugin/modules/mysql"
 	_ "github.com/netdata/go.d.plugin/modules/nginx"
+	_ "github.com/netdata/go.d.plugin/modules/ping"
 	_ "github.com/netdata/go.d.plugin/modules/portcheck"
 	_ "github.com/netdata/go.d.plugin/modules/rabbitmq"
 	_ "github.com/netdata/go.d.plugin/modules/solr"
@@ -65,6 +72,16 @@ func main() {
 		return
 	}
 
+	// discovered jobs are not started when the plugin runs a single module (debug mode)
+	if opt.Module == "all" {
+		if discoverers := newDiscoverers(plugin); len(discoverers) > 0 {
+			manager := discovery.NewManager(plugin.Name, plugin.Out, discoverers...)
+			manager.MinUpdateEvery = opt.UpdateEvery
+			manager.StaticJobs = staticJobs(plugin)
+			go manager.Run(context.Background())
+		}
+	}
+
 	plugin.Serve()
 }
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/jolokia"
 	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
@@ -28,6 +29,15 @@ var (
 	nameReplacer = strings.NewReplacer(".", "_", " ", "")
 )
 
+const (
+	modeWebadmin = "webadmin"
+	modeJolokia  = "jolokia"
+	modeArtemis  = "artemis"
+
+	uriJolokia        = "/api/jolokia"
+	uriArtemisJolokia = "/console/jolokia"
+)
+
 const (
 	defaultMaxQueues   = 50
 	defaultMaxTopics   = 50
@@ -47,6 +57,7 @@ func New() *Activemq {
 		MaxTopics: defaultMaxTopics,
 
 		charts:       &Charts{},
+		activeBroker: make(map[string]bool),
 		activeQueues: make(map[string]bool),
 		activeTopics: make(map[string]bool),
 	}
@@ -58,6 +69,8 @@ type Activemq struct {
 
 	web.HTTP `yaml:",inline"`
 
+	// Mode is the metrics source: the webadmin XML (default), the ActiveMQ Jolokia API or the Artemis Jolokia API
+	Mode         string `yaml:"mode"`
 	Webadmin     string `yaml:"webadmin"`
 	MaxQueues    int    `yaml:"max_queues"`
 	MaxTopics    int    `yaml:"max_topics"`
@@ -65,6 +78,10 @@ type Activemq struct {
 	TopicsFilter string `yaml:"topics_filter"`
 
 	apiClient    *apiClient
+	jolokia      *jolokia.Client
+	destCharts   Charts
+	brokerCharts Charts
+	activeBroker map[string]bool
 	activeQueues map[string]bool
 	activeTopics map[string]bool
 	queuesFilter matcher.Matcher
@@ -77,8 +94,22 @@ func (Activemq) Cleanup() {}
 
 // Init makes initialization
 func (a *Activemq) Init() bool {
-	if a.Webadmin == "" {
-		a.Error("webadmin root path is not set")
+	switch a.Mode {
+	case "", modeWebadmin:
+		if a.Webadmin == "" {
+			a.Error("webadmin root path is not set")
+			return false
+		}
+		a.destCharts = charts
+	case modeJolokia:
+		a.destCharts = append(append(Charts{}, charts...), jolokiaDestCharts...)
+		a.brokerCharts = brokerCharts
+	case modeArtemis:
+		// Artemis queues have no producers and memory usage attributes
+		a.destCharts = append(append(Charts{}, charts...), jolokiaDestCharts.Get("%s_%s_expired"))
+		a.brokerCharts = artemisBrokerCharts()
+	default:
+		a.Errorf("unknown mode '%s', supported modes: %s, %s, %s", a.Mode, modeWebadmin, modeJolokia, modeArtemis)
 		return false
 	}
 
@@ -107,10 +138,20 @@ func (a *Activemq) Init() bool {
 		return false
 	}
 
-	a.apiClient = &apiClient{
-		webadmin:   a.Webadmin,
-		req:        a.Request,
-		httpClient: client,
+	switch a.Mode {
+	case modeJolokia, modeArtemis:
+		req := a.Request
+		req.URL = strings.TrimSuffix(req.URL, "/") + uriJolokia
+		if a.Mode == modeArtemis {
+			req.URL = strings.TrimSuffix(a.URL, "/") + uriArtemisJolokia
+		}
+		a.jolokia = jolokia.New(client, req)
+	default:
+		a.apiClient = &apiClient{
+			webadmin:   a.Webadmin,
+			req:        a.Request,
+			httpClient: client,
+		}
 	}
 
 	return true
@@ -128,6 +169,15 @@ func (a Activemq) Charts() *Charts {
 
 // Collect collects metrics
 func (a *Activemq) Collect() map[string]int64 {
+	if a.jolokia != nil {
+		metrics, err := a.collectJolokia()
+		if err != nil {
+			a.Error(err)
+			return nil
+		}
+		return metrics
+	}
+
 	metrics := make(map[string]int64)
 
 	var (
@@ -266,7 +316,7 @@ func (a Activemq) filterTopics(line string) bool {
 func (a *Activemq) addQueueTopicCharts(name, typ string) {
 	rname := nameReplacer.Replace(name)
 
-	charts := charts.Copy()
+	charts := a.destCharts.Copy()
 
 	for _, chart := range *charts {
 		chart.ID = fmt.Sprintf(chart.ID, typ, rname)
@@ -285,18 +335,13 @@ func (a *Activemq) addQueueTopicCharts(name, typ string) {
 func (a *Activemq) removeQueueTopicCharts(name, typ string) {
 	rname := nameReplacer.Replace(name)
 
-	chart := a.charts.Get(fmt.Sprintf("%s_%s_messages", typ, rname))
-	chart.Obsolete = true
-	chart.MarkNotCreated()
-	chart.MarkRemove()
-
-	chart = a.charts.Get(fmt.Sprintf("%s_%s_unprocessed_messages", typ, rname))
-	chart.Obsolete = true
-	chart.MarkNotCreated()
-	chart.MarkRemove()
-
-	chart = a.charts.Get(fmt.Sprintf("%s_%s_consumers", typ, rname))
-	chart.Obsolete = true
-	chart.MarkNotCreated()
-	chart.MarkRemove()
+	for _, chart := range a.destCharts {
+		chart = a.charts.Get(fmt.Sprintf(chart.ID, typ, rname))
+		if chart == nil {
+			continue
+		}
+		chart.Obsolete = true
+		chart.MarkNotCreated()
+		chart.MarkRemove()
+	}
 }
@@ -1,6 +1,7 @@
 package activemq
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -149,6 +150,16 @@ func TestActivemq_Init(t *testing.T) {
 	mod.Webadmin = "webadmin"
 	assert.True(t, mod.Init())
 	assert.NotNil(t, mod.apiClient)
+
+	// jolokia modes don't need webadmin
+	mod = New()
+	mod.Mode = modeJolokia
+	assert.True(t, mod.Init())
+	assert.NotNil(t, mod.jolokia)
+
+	mod = New()
+	mod.Mode = "jmx"
+	assert.False(t, mod.Init())
 }
 
 func TestActivemq_Check(t *testing.T) {
@@ -325,3 +336,122 @@ func TestActivemq_Collect_InvalidData(t *testing.T) {
 	require.True(t, mod.Init())
 	assert.False(t, mod.Check())
 }
+
+func newJolokiaServer(t *testing.T, path, file string) *httptest.Server {
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+
+	return httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != path {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write(data)
+			}))
+}
+
+func TestActivemq_Collect_Jolokia(t *testing.T) {
+	ts := newJolokiaServer(t, "/api/jolokia/", "testdata/jolokia.json")
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.Mode = modeJolokia
+
+	require.True(t, mod.Init())
+	require.True(t, mod.Check())
+
+	expected := map[string]int64{
+		"broker_localhost_store_usage":  12,
+		"broker_localhost_memory_usage": 5,
+		"broker_localhost_temp_usage":   0,
+		"broker_localhost_connections":  8,
+		"broker_localhost_producers":    3,
+		"broker_localhost_consumers":    4,
+		"broker_localhost_enqueued":     1000,
+		"broker_localhost_dequeued":     900,
+		"broker_localhost_expired":      4,
+		"broker_localhost_dlq_messages": 7,
+
+		"queues_orders_eu_consumers":    2,
+		"queues_orders_eu_enqueued":     500,
+		"queues_orders_eu_dequeued":     490,
+		"queues_orders_eu_unprocessed":  10,
+		"queues_orders_eu_producers":    1,
+		"queues_orders_eu_memory_usage": 1,
+		"queues_orders_eu_expired":      3,
+
+		"queues_ActiveMQ_DLQ_consumers":    0,
+		"queues_ActiveMQ_DLQ_enqueued":     7,
+		"queues_ActiveMQ_DLQ_dequeued":     0,
+		"queues_ActiveMQ_DLQ_unprocessed":  7,
+		"queues_ActiveMQ_DLQ_producers":    0,
+		"queues_ActiveMQ_DLQ_memory_usage": 0,
+		"queues_ActiveMQ_DLQ_expired":      0,
+
+		"topics_prices_consumers":    2,
+		"topics_prices_enqueued":     300,
+		"topics_prices_dequeued":     600,
+		"topics_prices_unprocessed":  -300,
+		"topics_prices_producers":    2,
+		"topics_prices_memory_usage": 2,
+		"topics_prices_expired":      1,
+	}
+
+	assert.Equal(t, expected, mod.Collect())
+	assert.Len(t, *mod.Charts(), len(brokerCharts)+3*(len(charts)+len(jolokiaDestCharts)))
+}
+
+func TestActivemq_Collect_Artemis(t *testing.T) {
+	ts := newJolokiaServer(t, "/console/jolokia/", "testdata/artemis.json")
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.Mode = modeArtemis
+
+	require.True(t, mod.Init())
+	require.True(t, mod.Check())
+
+	expected := map[string]int64{
+		"broker_0_0_0_0_store_usage":  25,
+		"broker_0_0_0_0_memory_usage": 3,
+		"broker_0_0_0_0_connections":  5,
+		"broker_0_0_0_0_consumers":    2,
+		"broker_0_0_0_0_enqueued":     120,
+		"broker_0_0_0_0_dequeued":     100,
+		"broker_0_0_0_0_expired":      4,
+		"broker_0_0_0_0_dlq_messages": 5,
+
+		"queues_orders_consumers":   2,
+		"queues_orders_enqueued":    115,
+		"queues_orders_dequeued":    100,
+		"queues_orders_unprocessed": 15,
+		"queues_orders_expired":     4,
+
+		"queues_DLQ_consumers":   0,
+		"queues_DLQ_enqueued":    5,
+		"queues_DLQ_dequeued":    0,
+		"queues_DLQ_unprocessed": 5,
+		"queues_DLQ_expired":     0,
+	}
+
+	assert.Equal(t, expected, mod.Collect())
+	assert.Len(t, *mod.Charts(), len(brokerCharts)+2*(len(charts)+1))
+	assert.NotNil(t, mod.Charts().Get("queues_orders_expired"))
+	assert.False(t, mod.Charts().Get("broker_0_0_0_0_usage").HasDim("broker_0_0_0_0_temp_usage"))
+}
+
+func TestActivemq_uniqueDestinations(t *testing.T) {
+	dests := []destination{
+		{name: "orders", broker: "eu"},
+		{name: "orders", broker: "us"},
+		{name: "prices", broker: "us"},
+	}
+	sortDestinations(dests)
+
+	unique := New().uniqueDestinations(dests, keyQueues)
+	assert.Equal(t, []destination{{name: "orders", broker: "eu"}, {name: "prices", broker: "us"}}, unique)
+}
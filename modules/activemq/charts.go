@@ -1,6 +1,10 @@
 package activemq
 
-import "github.com/netdata/go-orchestrator/module"
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/module"
+)
 
 type (
 	// Charts is an alias for module.Charts
@@ -42,3 +46,121 @@ var charts = Charts{
 		},
 	},
 }
+
+// jolokiaDestCharts are the additional per queue and per topic charts of the jolokia modes.
+var jolokiaDestCharts = Charts{
+	{
+		ID:    "%s_%s_producers",
+		Title: "%s Producers",
+		Units: "producers",
+		Fam:   "",
+		Ctx:   "activemq.producers",
+		Dims: Dims{
+			{ID: "%s_%s_producers", Name: "producers"},
+		},
+	},
+	{
+		ID:    "%s_%s_memory_usage",
+		Title: "%s Memory Usage",
+		Units: "percentage",
+		Fam:   "",
+		Ctx:   "activemq.memory_usage",
+		Dims: Dims{
+			{ID: "%s_%s_memory_usage", Name: "used"},
+		},
+	},
+	{
+		ID:    "%s_%s_expired",
+		Title: "%s Expired Messages",
+		Units: "messages/s",
+		Fam:   "",
+		Ctx:   "activemq.expired_messages",
+		Dims: Dims{
+			{ID: "%s_%s_expired", Name: "expired", Algo: module.Incremental},
+		},
+	},
+}
+
+// brokerCharts are the per broker charts of the jolokia modes, '%s' is the broker name.
+var brokerCharts = Charts{
+	{
+		ID:    "broker_%s_usage",
+		Title: "Broker %s Resources Usage",
+		Units: "percentage",
+		Fam:   "broker",
+		Ctx:   "activemq.broker_usage",
+		Dims: Dims{
+			{ID: "broker_%s_store_usage", Name: "store"},
+			{ID: "broker_%s_memory_usage", Name: "memory"},
+			{ID: "broker_%s_temp_usage", Name: "temp"},
+		},
+	},
+	{
+		ID:    "broker_%s_connections",
+		Title: "Broker %s Connections",
+		Units: "connections",
+		Fam:   "broker",
+		Ctx:   "activemq.broker_connections",
+		Dims: Dims{
+			{ID: "broker_%s_connections", Name: "connections"},
+		},
+	},
+	{
+		ID:    "broker_%s_clients",
+		Title: "Broker %s Producers And Consumers",
+		Units: "clients",
+		Fam:   "broker",
+		Ctx:   "activemq.broker_clients",
+		Dims: Dims{
+			{ID: "broker_%s_producers", Name: "producers"},
+			{ID: "broker_%s_consumers", Name: "consumers"},
+		},
+	},
+	{
+		ID:    "broker_%s_messages",
+		Title: "Broker %s Messages",
+		Units: "messages/s",
+		Fam:   "broker",
+		Ctx:   "activemq.broker_messages",
+		Dims: Dims{
+			{ID: "broker_%s_enqueued", Name: "enqueued", Algo: module.Incremental},
+			{ID: "broker_%s_dequeued", Name: "dequeued", Algo: module.Incremental},
+			{ID: "broker_%s_expired", Name: "expired", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "broker_%s_dlq_messages",
+		Title: "Broker %s Dead Letter Queues Messages",
+		Units: "messages",
+		Fam:   "broker",
+		Ctx:   "activemq.broker_dlq_messages",
+		Dims: Dims{
+			{ID: "broker_%s_dlq_messages", Name: "messages"},
+		},
+	},
+}
+
+// artemisBrokerCharts returns the broker charts without the dimensions Artemis has no attributes for.
+func artemisBrokerCharts() Charts {
+	charts := *brokerCharts.Copy()
+
+	_ = charts.Get("broker_%s_usage").RemoveDim("broker_%s_temp_usage")
+	_ = charts.Get("broker_%s_clients").RemoveDim("broker_%s_producers")
+
+	return charts
+}
+
+func newBrokerCharts(tmpl Charts, id, name string) *Charts {
+	charts := tmpl.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Title = fmt.Sprintf(chart.Title, name)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	return charts
+}
@@ -0,0 +1,332 @@
+package activemq
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/netdata/go.d.plugin/pkg/jolokia"
+)
+
+const (
+	mbeanBroker = "org.apache.activemq:type=Broker,brokerName=*"
+	mbeanDest   = "org.apache.activemq:type=Broker,brokerName=*,destinationType=%s,destinationName=*"
+
+	mbeanArtemisBroker = "org.apache.activemq.artemis:broker=*"
+	mbeanArtemisQueue  = "org.apache.activemq.artemis:broker=*,component=addresses,address=*,subcomponent=queues,routing-type=*,queue=*"
+
+	// artemisDLA is the Artemis default dead letter address
+	artemisDLA = "DLQ"
+)
+
+var (
+	brokerAttributes = []string{
+		"StorePercentUsage", "MemoryPercentUsage", "TempPercentUsage", "CurrentConnectionsCount",
+		"TotalProducerCount", "TotalConsumerCount", "TotalEnqueueCount", "TotalDequeueCount",
+	}
+	destAttributes = []string{
+		"QueueSize", "ConsumerCount", "ProducerCount", "EnqueueCount", "DequeueCount",
+		"ExpiredCount", "MemoryPercentUsage", "DLQ",
+	}
+	artemisBrokerAttributes = []string{
+		"DiskStoreUsage", "AddressMemoryUsagePercentage", "ConnectionCount", "TotalConsumerCount",
+		"TotalMessagesAdded", "TotalMessagesAcknowledged",
+	}
+	artemisQueueAttributes = []string{
+		"MessageCount", "ConsumerCount", "MessagesAdded", "MessagesAcknowledged", "MessagesExpired",
+	}
+)
+
+// destination is a queue or topic read from the broker MBeans.
+type destination struct {
+	name   string
+	broker string
+	stats  stats
+	// the jolokia modes only stats
+	producers   int64
+	memoryUsage int64
+	expired     int64
+}
+
+func (a *Activemq) collectJolokia() (map[string]int64, error) {
+	var (
+		metrics = make(map[string]int64)
+		queues  []destination
+		topics  []destination
+		err     error
+	)
+
+	if a.Mode == modeArtemis {
+		queues, err = a.collectArtemis(metrics)
+	} else {
+		queues, topics, err = a.collectClassic(metrics)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.processDestinations(queues, keyQueues, metrics)
+	a.processDestinations(topics, keyTopics, metrics)
+
+	return metrics, nil
+}
+
+func (a *Activemq) collectClassic(metrics map[string]int64) (queues, topics []destination, err error) {
+	resps, err := a.jolokia.Read(
+		jolokia.NewReadRequest(mbeanBroker, brokerAttributes...),
+		jolokia.NewReadRequest(fmt.Sprintf(mbeanDest, "Queue"), destAttributes...),
+		jolokia.NewReadRequest(fmt.Sprintf(mbeanDest, "Topic"), destAttributes...),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	brokers, err := resps[0].MBeans()
+	if err != nil {
+		return nil, nil, err
+	}
+	// there are no queues or topics if there are no matching MBeans
+	queueMBeans, _ := resps[1].MBeans()
+	topicMBeans, _ := resps[2].MBeans()
+
+	seen := make(map[string]bool)
+	for name, attrs := range brokers {
+		broker := propertyValue(name, "brokerName")
+		id := a.addBroker(broker)
+		seen[id] = true
+
+		prefix := "broker_" + id
+		metrics[prefix+"_store_usage"] = intValue(attrs["StorePercentUsage"])
+		metrics[prefix+"_memory_usage"] = intValue(attrs["MemoryPercentUsage"])
+		metrics[prefix+"_temp_usage"] = intValue(attrs["TempPercentUsage"])
+		metrics[prefix+"_connections"] = intValue(attrs["CurrentConnectionsCount"])
+		metrics[prefix+"_producers"] = intValue(attrs["TotalProducerCount"])
+		metrics[prefix+"_consumers"] = intValue(attrs["TotalConsumerCount"])
+		metrics[prefix+"_enqueued"] = intValue(attrs["TotalEnqueueCount"])
+		metrics[prefix+"_dequeued"] = intValue(attrs["TotalDequeueCount"])
+		metrics[prefix+"_expired"] = 0
+		metrics[prefix+"_dlq_messages"] = 0
+
+		for mbean, attrs := range queueMBeans {
+			if propertyValue(mbean, "brokerName") != broker {
+				continue
+			}
+			metrics[prefix+"_expired"] += intValue(attrs["ExpiredCount"])
+			if dlq, _ := attrs["DLQ"].(bool); dlq {
+				metrics[prefix+"_dlq_messages"] += intValue(attrs["QueueSize"])
+			}
+		}
+		for mbean, attrs := range topicMBeans {
+			if propertyValue(mbean, "brokerName") == broker {
+				metrics[prefix+"_expired"] += intValue(attrs["ExpiredCount"])
+			}
+		}
+	}
+	a.removeBrokers(seen)
+
+	return newDestinations(queueMBeans), newDestinations(topicMBeans), nil
+}
+
+func newDestinations(mbeans map[string]jolokia.Attributes) []destination {
+	var dests []destination
+
+	for mbean, attrs := range mbeans {
+		dests = append(dests, destination{
+			name:   propertyValue(mbean, "destinationName"),
+			broker: propertyValue(mbean, "brokerName"),
+			stats: stats{
+				Size:          intValue(attrs["QueueSize"]),
+				ConsumerCount: intValue(attrs["ConsumerCount"]),
+				EnqueueCount:  intValue(attrs["EnqueueCount"]),
+				DequeueCount:  intValue(attrs["DequeueCount"]),
+			},
+			producers:   intValue(attrs["ProducerCount"]),
+			memoryUsage: intValue(attrs["MemoryPercentUsage"]),
+			expired:     intValue(attrs["ExpiredCount"]),
+		})
+	}
+	sortDestinations(dests)
+
+	return dests
+}
+
+// collectArtemis collects the ActiveMQ Artemis broker and queues, the Artemis addresses are not charted,
+// the multicast (topic) subscriptions are queues too.
+func (a *Activemq) collectArtemis(metrics map[string]int64) ([]destination, error) {
+	resps, err := a.jolokia.Read(
+		jolokia.NewReadRequest(mbeanArtemisBroker, artemisBrokerAttributes...),
+		jolokia.NewReadRequest(mbeanArtemisQueue, artemisQueueAttributes...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	brokers, err := resps[0].MBeans()
+	if err != nil {
+		return nil, err
+	}
+	queueMBeans, _ := resps[1].MBeans()
+
+	seen := make(map[string]bool)
+	for name, attrs := range brokers {
+		broker := propertyValue(name, "broker")
+		id := a.addBroker(broker)
+		seen[id] = true
+
+		prefix := "broker_" + id
+		metrics[prefix+"_store_usage"] = int64(floatValue(attrs["DiskStoreUsage"]) * 100)
+		metrics[prefix+"_memory_usage"] = intValue(attrs["AddressMemoryUsagePercentage"])
+		metrics[prefix+"_connections"] = intValue(attrs["ConnectionCount"])
+		metrics[prefix+"_consumers"] = intValue(attrs["TotalConsumerCount"])
+		metrics[prefix+"_enqueued"] = intValue(attrs["TotalMessagesAdded"])
+		metrics[prefix+"_dequeued"] = intValue(attrs["TotalMessagesAcknowledged"])
+		metrics[prefix+"_expired"] = 0
+		metrics[prefix+"_dlq_messages"] = 0
+
+		for mbean, attrs := range queueMBeans {
+			if propertyValue(mbean, "broker") != broker {
+				continue
+			}
+			metrics[prefix+"_expired"] += intValue(attrs["MessagesExpired"])
+			if propertyValue(mbean, "address") == artemisDLA {
+				metrics[prefix+"_dlq_messages"] += intValue(attrs["MessageCount"])
+			}
+		}
+	}
+	a.removeBrokers(seen)
+
+	var queues []destination
+	for mbean, attrs := range queueMBeans {
+		queues = append(queues, destination{
+			name:   propertyValue(mbean, "queue"),
+			broker: propertyValue(mbean, "broker"),
+			stats: stats{
+				Size:          intValue(attrs["MessageCount"]),
+				ConsumerCount: intValue(attrs["ConsumerCount"]),
+				EnqueueCount:  intValue(attrs["MessagesAdded"]),
+				DequeueCount:  intValue(attrs["MessagesAcknowledged"]),
+			},
+			expired: intValue(attrs["MessagesExpired"]),
+		})
+	}
+	sortDestinations(queues)
+
+	return queues, nil
+}
+
+// processDestinations processes the destinations the same way the webadmin queues and topics are processed
+// and adds the jolokia modes only metrics of the charted destinations.
+// The destinations are identified by name, one broker per job is supported,
+// the same name destinations of the other brokers are skipped.
+func (a *Activemq) processDestinations(dests []destination, typ string, metrics map[string]int64) {
+	active := a.activeQueues
+	if typ == keyTopics {
+		active = a.activeTopics
+	}
+
+	dests = a.uniqueDestinations(dests, typ)
+
+	if typ == keyQueues {
+		var items queues
+		for _, d := range dests {
+			items.Items = append(items.Items, queue{Name: d.name, Stats: d.stats})
+		}
+		a.processQueues(&items, metrics)
+	} else {
+		var items topics
+		for _, d := range dests {
+			items.Items = append(items.Items, topic{Name: d.name, Stats: d.stats})
+		}
+		a.processTopics(&items, metrics)
+	}
+
+	for _, d := range dests {
+		if !active[d.name] {
+			continue
+		}
+		prefix := typ + "_" + nameReplacer.Replace(d.name)
+		metrics[prefix+"_expired"] = d.expired
+		if a.Mode != modeArtemis {
+			metrics[prefix+"_producers"] = d.producers
+			metrics[prefix+"_memory_usage"] = d.memoryUsage
+		}
+	}
+}
+
+func (a *Activemq) uniqueDestinations(dests []destination, typ string) []destination {
+	var (
+		unique  = dests[:0]
+		seen    = make(map[string]bool)
+		skipped int
+	)
+
+	for _, d := range dests {
+		if seen[d.name] {
+			skipped++
+			continue
+		}
+		seen[d.name] = true
+		unique = append(unique, d)
+	}
+
+	if skipped > 0 {
+		a.Warningf("%d %s are skipped, the same name %s of another broker exist (one broker per job is supported)", skipped, typ, typ)
+	}
+
+	return unique
+}
+
+func (a *Activemq) addBroker(name string) string {
+	id := nameReplacer.Replace(name)
+	if !a.activeBroker[id] {
+		a.activeBroker[id] = true
+		_ = a.charts.Add(*newBrokerCharts(a.brokerCharts, id, name)...)
+	}
+	return id
+}
+
+func (a *Activemq) removeBrokers(seen map[string]bool) {
+	for id := range a.activeBroker {
+		if seen[id] {
+			continue
+		}
+		delete(a.activeBroker, id)
+
+		for _, chart := range a.brokerCharts {
+			chart = a.charts.Get(fmt.Sprintf(chart.ID, id))
+			if chart == nil {
+				continue
+			}
+			chart.Obsolete = true
+			chart.MarkNotCreated()
+			chart.MarkRemove()
+		}
+	}
+}
+
+// sortDestinations sorts the destinations by name and broker, the MBeans order is random and the max queues/topics
+// limit must be applied to the same destinations every time.
+func sortDestinations(dests []destination) {
+	sort.Slice(dests, func(i, j int) bool {
+		if dests[i].name == dests[j].name {
+			return dests[i].broker < dests[j].broker
+		}
+		return dests[i].name < dests[j].name
+	})
+}
+
+func propertyValue(mbean, key string) string {
+	_, props, err := jolokia.ParseObjectName(mbean)
+	if err != nil {
+		return ""
+	}
+	return props[key]
+}
+
+func intValue(v interface{}) int64 {
+	return int64(floatValue(v))
+}
+
+func floatValue(v interface{}) float64 {
+	f, _ := v.(float64)
+	return f
+}
@@ -3,11 +3,11 @@ package consul
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
-	"github.com/netdata/go.d.plugin/pkg/web"
+	"github.com/netdata/go.d.plugin/pkg/consulapi"
 )
 
 type agentCheck struct {
@@ -20,81 +20,116 @@ type agentCheck struct {
 	ServiceTags []string
 }
 
-type apiClient struct {
-	aclToken string
-
-	req        web.Request
-	httpClient *http.Client
+type raftConfiguration struct {
+	Servers []struct {
+		ID      string
+		Node    string
+		Address string
+		Leader  bool
+		Voter   bool
+	}
 }
 
-func (a *apiClient) localChecks() (map[string]*agentCheck, error) {
-	req, err := a.createRequest("/v1/agent/checks")
+type autopilotHealth struct {
+	Healthy          bool
+	FailureTolerance int64
+	Servers          []struct {
+		Name    string
+		Healthy bool
+	}
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("error on creating request : %v", err)
+type agentMetrics struct {
+	Gauges []struct {
+		Name  string
+		Value float64
+	}
+	Samples []struct {
+		Name  string
+		Count int64
+		Max   float64
+		Mean  float64
 	}
+}
 
-	resp, err := a.doRequestOK(req)
+type apiClient struct {
+	*consulapi.Client
+}
 
-	defer closeBody(resp)
+func (a apiClient) localChecks() (map[string]*agentCheck, error) {
+	var checks map[string]*agentCheck
 
-	if err != nil {
+	if err := a.GetJSON("/v1/agent/checks", &checks); err != nil {
 		return nil, err
 	}
 
-	var checks map[string]*agentCheck
+	return checks, nil
+}
+
+func (a apiClient) healthChecks() ([]*agentCheck, error) {
+	var checks []*agentCheck
 
-	if err = json.NewDecoder(resp.Body).Decode(&checks); err != nil {
-		return nil, fmt.Errorf("error on decoding resp from %s : %v", req.URL, err)
+	if err := a.GetJSON("/v1/health/state/any", &checks); err != nil {
+		return nil, err
 	}
 
 	return checks, nil
 }
 
-func (a apiClient) doRequest(req *http.Request) (*http.Response, error) {
-	return a.httpClient.Do(req)
-}
+func (a apiClient) raftConfiguration() (*raftConfiguration, error) {
+	var cfg raftConfiguration
 
-func (a apiClient) doRequestOK(req *http.Request) (*http.Response, error) {
-	var (
-		resp *http.Response
-		err  error
-	)
+	if err := a.GetJSON("/v1/operator/raft/configuration", &cfg); err != nil {
+		return nil, err
+	}
 
-	if resp, err = a.doRequest(req); err != nil {
-		return resp, fmt.Errorf("error on request to %s : %v", req.URL, err)
+	return &cfg, nil
+}
 
+// autopilotHealth returns autopilot health, the endpoint responds with 429 status code if the cluster is unhealthy.
+func (a apiClient) autopilotHealth() (*autopilotHealth, error) {
+	var health autopilotHealth
+
+	if err := a.GetJSON("/v1/operator/autopilot/health", &health, http.StatusTooManyRequests); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return resp, fmt.Errorf("%s returned HTTP status %d", req.URL, resp.StatusCode)
+	return &health, nil
+}
+
+func (a apiClient) agentMetrics() (*agentMetrics, error) {
+	var metrics agentMetrics
+
+	if err := a.GetJSON("/v1/agent/metrics", &metrics); err != nil {
+		return nil, err
 	}
 
-	return resp, err
+	return &metrics, nil
 }
 
-func (a apiClient) createRequest(uri string) (*http.Request, error) {
-	var (
-		req *http.Request
-		err error
-	)
+// kvKeysCount returns the number of keys under the prefix, the endpoint responds with 404 status code if there are none.
+func (a apiClient) kvKeysCount(prefix string) (int, error) {
+	resp, err := a.Get("/v1/kv/" + (&url.URL{Path: strings.TrimPrefix(prefix, "/")}).EscapedPath() + "?keys")
 
-	a.req.URI = uri
+	defer consulapi.CloseBody(resp)
 
-	if req, err = web.NewHTTPRequest(a.req); err != nil {
-		return nil, err
+	if err != nil {
+		return 0, err
 	}
 
-	if a.aclToken != "" {
-		req.Header.Set("X-Consul-Token", a.aclToken)
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, nil
 	}
 
-	return req, nil
-}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s returned HTTP status %d", resp.Request.URL, resp.StatusCode)
+	}
 
-func closeBody(resp *http.Response) {
-	if resp != nil && resp.Body != nil {
-		_, _ = io.Copy(ioutil.Discard, resp.Body)
-		_ = resp.Body.Close()
+	var keys []string
+
+	if err = json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return 0, fmt.Errorf("error on decoding resp from %s : %v", resp.Request.URL, err)
 	}
+
+	return len(keys), nil
 }
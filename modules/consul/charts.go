@@ -9,6 +9,8 @@ type (
 	Charts = module.Charts
 	// Chart is an alias for module.Chart
 	Chart = module.Chart
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
 	// Dim is an alias for module.Dim
 	Dim = module.Dim
 )
@@ -28,4 +30,113 @@ var charts = Charts{
 		Units: "status",
 		Ctx:   "consul.checks",
 	},
+	{
+		ID:    "service_checks_state_duration",
+		Title: "Service Checks Time In Current State",
+		Fam:   "checks",
+		Units: "seconds",
+		Ctx:   "consul.checks_state_duration",
+	},
+	{
+		ID:    "unbound_checks_state_duration",
+		Title: "Unbound Checks Time In Current State",
+		Fam:   "checks",
+		Units: "seconds",
+		Ctx:   "consul.checks_state_duration",
+	},
+}
+
+var clusterHealthCharts = Charts{
+	{
+		ID:    "cluster_services_health",
+		Title: "Cluster Services Health Status",
+		Fam:   "cluster",
+		Units: "services",
+		Ctx:   "consul.cluster_services_health",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "cluster_services_passing", Name: "passing"},
+			{ID: "cluster_services_warning", Name: "warning"},
+			{ID: "cluster_services_critical", Name: "critical"},
+		},
+	},
+	{
+		ID:    "cluster_nodes_health",
+		Title: "Cluster Nodes Health Status",
+		Fam:   "cluster",
+		Units: "nodes",
+		Ctx:   "consul.cluster_nodes_health",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "cluster_nodes_passing", Name: "passing"},
+			{ID: "cluster_nodes_warning", Name: "warning"},
+			{ID: "cluster_nodes_critical", Name: "critical"},
+		},
+	},
+}
+
+var raftCharts = Charts{
+	{
+		ID:    "raft_leader",
+		Title: "Raft Leader",
+		Fam:   "raft",
+		Units: "boolean",
+		Ctx:   "consul.raft_leader",
+		Dims: Dims{
+			{ID: "raft_has_leader", Name: "has leader"},
+			{ID: "raft_is_leader", Name: "is leader"},
+		},
+	},
+	{
+		ID:    "raft_peers",
+		Title: "Raft Peers",
+		Fam:   "raft",
+		Units: "servers",
+		Ctx:   "consul.raft_peers",
+		Dims: Dims{
+			{ID: "raft_peers", Name: "peers"},
+			{ID: "raft_voters", Name: "voters"},
+		},
+	},
+	{
+		ID:    "raft_leader_last_contact",
+		Title: "Raft Leader Last Contact",
+		Fam:   "raft",
+		Units: "milliseconds",
+		Ctx:   "consul.raft_leader_last_contact",
+		Dims: Dims{
+			{ID: "raft_last_contact_mean", Name: "mean", Div: 1000},
+			{ID: "raft_last_contact_max", Name: "max", Div: 1000},
+		},
+	},
+	{
+		ID:    "autopilot_health",
+		Title: "Autopilot Health",
+		Fam:   "autopilot",
+		Units: "boolean",
+		Ctx:   "consul.autopilot_health",
+		Dims: Dims{
+			{ID: "autopilot_healthy", Name: "healthy"},
+		},
+	},
+	{
+		ID:    "autopilot_servers",
+		Title: "Autopilot Servers",
+		Fam:   "autopilot",
+		Units: "servers",
+		Ctx:   "consul.autopilot_servers",
+		Dims: Dims{
+			{ID: "autopilot_servers_healthy", Name: "healthy"},
+			{ID: "autopilot_servers_unhealthy", Name: "unhealthy"},
+			{ID: "autopilot_failure_tolerance", Name: "failure tolerance"},
+		},
+	},
+}
+
+var kvKeysChart = Chart{
+	ID:    "kv_keys",
+	Title: "KV Store Keys",
+	Fam:   "kv",
+	Units: "keys",
+	Ctx:   "consul.kv_keys",
 }
@@ -0,0 +1,167 @@
+package consul
+
+import (
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/consulapi"
+)
+
+var healthSeverity = map[string]int{
+	healthPassing:  0,
+	healthWarning:  1,
+	healthCritical: 2,
+}
+
+// collectClusterHealth collects the number of services and nodes per health state. Service state is the worst
+// state of all its instances checks, node state is the worst state of its node checks (checks that are not bound to a service).
+func (c *Consul) collectClusterHealth(metrics map[string]int64) error {
+	checks, err := c.apiClient.healthChecks()
+
+	if err != nil {
+		return err
+	}
+
+	services := make(map[string]string)
+	nodes := make(map[string]string)
+
+	for _, check := range checks {
+		if _, ok := healthSeverity[check.Status]; !ok {
+			c.Debugf("check %s on node %s has unknown status '%s'", check.CheckID, check.Node, check.Status)
+			continue
+		}
+
+		if _, ok := nodes[check.Node]; !ok {
+			nodes[check.Node] = healthPassing
+		}
+
+		if check.ServiceID == "" {
+			nodes[check.Node] = worstStatus(nodes[check.Node], check.Status)
+			continue
+		}
+
+		if status, ok := services[check.ServiceName]; ok {
+			services[check.ServiceName] = worstStatus(status, check.Status)
+		} else {
+			services[check.ServiceName] = check.Status
+		}
+	}
+
+	for status := range healthSeverity {
+		metrics["cluster_services_"+status] = 0
+		metrics["cluster_nodes_"+status] = 0
+	}
+
+	for _, status := range services {
+		metrics["cluster_services_"+status]++
+	}
+
+	for _, status := range nodes {
+		metrics["cluster_nodes_"+status]++
+	}
+
+	return nil
+}
+
+func worstStatus(a, b string) string {
+	if healthSeverity[b] > healthSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// collectRaft collects raft peers from the raft configuration, leader last contact from the agent telemetry
+// and autopilot health.
+func (c *Consul) collectRaft(metrics map[string]int64) error {
+	cfg, err := c.apiClient.raftConfiguration()
+	noLeader := err == consulapi.ErrNoLeader
+
+	if noLeader {
+		metrics["raft_has_leader"] = 0
+	} else if err != nil {
+		return err
+	} else {
+		metrics["raft_has_leader"] = 0
+		metrics["raft_peers"] = int64(len(cfg.Servers))
+		metrics["raft_voters"] = 0
+
+		for _, server := range cfg.Servers {
+			if server.Leader {
+				metrics["raft_has_leader"] = 1
+			}
+			if server.Voter {
+				metrics["raft_voters"]++
+			}
+		}
+	}
+
+	telemetry, err := c.apiClient.agentMetrics()
+
+	if err != nil {
+		return err
+	}
+
+	// metric names are prefixed with the telemetry prefix ('consul' by default)
+	for _, gauge := range telemetry.Gauges {
+		if strings.HasSuffix(gauge.Name, ".server.isLeader") {
+			metrics["raft_is_leader"] = int64(gauge.Value)
+		}
+	}
+
+	for _, sample := range telemetry.Samples {
+		if strings.HasSuffix(sample.Name, ".raft.leader.lastContact") && sample.Count > 0 {
+			metrics["raft_last_contact_mean"] = int64(sample.Mean * 1000)
+			metrics["raft_last_contact_max"] = int64(sample.Max * 1000)
+		}
+	}
+
+	// autopilot health is not available without leader
+	if noLeader {
+		return nil
+	}
+
+	health, err := c.apiClient.autopilotHealth()
+
+	if err != nil {
+		return err
+	}
+
+	metrics["autopilot_healthy"] = boolToInt(health.Healthy)
+	metrics["autopilot_failure_tolerance"] = health.FailureTolerance
+	metrics["autopilot_servers_healthy"] = 0
+	metrics["autopilot_servers_unhealthy"] = 0
+
+	for _, server := range health.Servers {
+		if server.Healthy {
+			metrics["autopilot_servers_healthy"]++
+		} else {
+			metrics["autopilot_servers_unhealthy"]++
+		}
+	}
+
+	return nil
+}
+
+func (c *Consul) collectKVKeys(metrics map[string]int64) error {
+	for _, prefix := range c.KVPrefixes {
+		count, err := c.apiClient.kvKeysCount(prefix)
+
+		if err != nil {
+			return err
+		}
+
+		metrics[kvKeysID(prefix)] = int64(count)
+	}
+
+	return nil
+}
+
+func kvKeysID(prefix string) string {
+	return "kv_keys_" + strings.Join(strings.Fields(prefix), "_")
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
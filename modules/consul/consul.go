@@ -1,9 +1,10 @@
 package consul
 
 import (
-	"fmt"
+	"strings"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/consulapi"
 	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
@@ -28,9 +29,31 @@ const (
 	healthPassing  = "passing"
 	healthWarning  = "warning"
 	healthCritical = "critical"
-	healthMaint    = "maintenance"
+	// healthMaint and healthUnknown are not Consul statuses. Maintenance mode is a critical check
+	// with the maintenance check ID, all the other statuses are reported as unknown.
+	healthMaint   = "maintenance"
+	healthUnknown = "unknown"
+
+	nodeMaintCheckID          = "_node_maintenance"
+	serviceMaintCheckIDPrefix = "_service_maintenance:"
 )
 
+// checkStatuses maps check status to the status chart value.
+var checkStatuses = map[string]int64{
+	healthPassing:  0,
+	healthWarning:  1,
+	healthCritical: 2,
+	healthMaint:    3,
+	healthUnknown:  4,
+}
+
+type checkState struct {
+	serviceBound bool
+	status       string
+	// since is the time the check has changed the status
+	since time.Time
+}
+
 // New creates Consul with default values.
 func New() *Consul {
 	return &Consul{
@@ -39,8 +62,9 @@ func New() *Consul {
 			Client:  web.Client{Timeout: web.Duration{Duration: defaultHTTPTimeout}},
 		},
 		MaxChecks:    defaultMaxChecks,
-		activeChecks: make(map[string]bool),
+		activeChecks: make(map[string]*checkState),
 		charts:       charts.Copy(),
+		now:          time.Now,
 	}
 }
 
@@ -54,10 +78,15 @@ type Consul struct {
 	MaxChecks    int    `yaml:"max_checks"`
 	ChecksFilter string `yaml:"checks_filter"`
 
+	CollectClusterHealth bool     `yaml:"collect_cluster_health"`
+	CollectRaft          bool     `yaml:"collect_raft"`
+	KVPrefixes           []string `yaml:"kv_prefixes"`
+
 	charts       *Charts
-	activeChecks map[string]bool
+	activeChecks map[string]*checkState
 	checksFilter matcher.Matcher
 	apiClient    *apiClient
+	now          func() time.Time
 }
 
 // Cleanup makes cleanup.
@@ -70,18 +99,14 @@ func (c *Consul) Init() bool {
 		return false
 	}
 
-	client, err := web.NewHTTPClient(c.Client)
+	client, err := consulapi.NewClient(c.HTTP, c.ACLToken)
 
 	if err != nil {
 		c.Error(err)
 		return false
 	}
 
-	c.apiClient = &apiClient{
-		aclToken:   c.ACLToken,
-		req:        c.Request,
-		httpClient: client,
-	}
+	c.apiClient = &apiClient{Client: client}
 
 	if c.ChecksFilter != "" {
 		sps, err := matcher.NewSimplePatternsMatcher(c.ChecksFilter)
@@ -93,6 +118,22 @@ func (c *Consul) Init() bool {
 		c.checksFilter = matcher.WithCache(sps)
 	}
 
+	if c.CollectClusterHealth {
+		_ = c.charts.Add(*clusterHealthCharts.Copy()...)
+	}
+
+	if c.CollectRaft {
+		_ = c.charts.Add(*raftCharts.Copy()...)
+	}
+
+	if len(c.KVPrefixes) > 0 {
+		chart := kvKeysChart.Copy()
+		for _, prefix := range c.KVPrefixes {
+			_ = chart.AddDim(&Dim{ID: kvKeysID(prefix), Name: prefix})
+		}
+		_ = c.charts.Add(chart)
+	}
+
 	return true
 }
 
@@ -115,6 +156,25 @@ func (c *Consul) Collect() map[string]int64 {
 		return nil
 	}
 
+	// cluster metrics are optional, an error doesn't fail the collection
+	if c.CollectClusterHealth {
+		if err := c.collectClusterHealth(metrics); err != nil {
+			c.Errorf("error on collecting cluster health : %v", err)
+		}
+	}
+
+	if c.CollectRaft {
+		if err := c.collectRaft(metrics); err != nil {
+			c.Errorf("error on collecting raft : %v", err)
+		}
+	}
+
+	if len(c.KVPrefixes) > 0 {
+		if err := c.collectKVKeys(metrics); err != nil {
+			c.Errorf("error on collecting kv keys : %v", err)
+		}
+	}
+
 	return metrics
 }
 
@@ -131,15 +191,14 @@ func (c *Consul) collectLocalChecks(metrics map[string]int64) error {
 }
 
 func (c *Consul) processLocalChecks(checks map[string]*agentCheck, metrics map[string]int64) {
-	count := len(c.activeChecks)
 	var unp int
+	now := c.now()
 
 	for id, check := range checks {
-
-		_, exist := c.activeChecks[id]
+		state, exist := c.activeChecks[id]
 
 		if !exist {
-			if c.MaxChecks != 0 && count > c.MaxChecks {
+			if c.MaxChecks != 0 && len(c.activeChecks) >= c.MaxChecks {
 				unp++
 				continue
 			}
@@ -148,23 +207,34 @@ func (c *Consul) processLocalChecks(checks map[string]*agentCheck, metrics map[s
 				continue
 			}
 
-			c.activeChecks[id] = true
-			c.addCheckToChart(check)
+			state = &checkState{serviceBound: check.ServiceID != ""}
+			c.activeChecks[id] = state
+			c.addCheckToCharts(id, state)
 		}
 
-		var status int64
-
-		switch check.Status {
-		case healthPassing, healthMaint:
-			status = 0
-		case healthWarning:
-			status = 1
-		case healthCritical:
-			status = 2
-		default:
-			panic(fmt.Sprintf("check %s unkown status %s", check.CheckID, check.Status))
+		status := checkStatus(check)
+
+		if state.status != status {
+			state.status, state.since = status, now
+
+			if _, ok := checkStatuses[status]; !ok {
+				c.Warningf("check %s has unknown status '%s'", id, status)
+			}
+		}
+
+		value, ok := checkStatuses[status]
+		if !ok {
+			value = checkStatuses[healthUnknown]
+		}
+		metrics[id] = value
+		metrics[id+"_state_duration"] = int64(now.Sub(state.since).Seconds())
+	}
+
+	for id, state := range c.activeChecks {
+		if _, ok := checks[id]; !ok {
+			delete(c.activeChecks, id)
+			c.removeCheckFromCharts(id, state)
 		}
-		metrics[id] = status
 	}
 
 	if unp > 0 {
@@ -172,6 +242,15 @@ func (c *Consul) processLocalChecks(checks map[string]*agentCheck, metrics map[s
 	}
 }
 
+// checkStatus returns the check status, the node and service maintenance checks are in the maintenance status.
+func checkStatus(check *agentCheck) string {
+	if check.Status == healthCritical &&
+		(check.CheckID == nodeMaintCheckID || strings.HasPrefix(check.CheckID, serviceMaintCheckIDPrefix)) {
+		return healthMaint
+	}
+	return check.Status
+}
+
 func (c *Consul) filterChecks(name string) bool {
 	if c.checksFilter == nil {
 		return true
@@ -179,15 +258,30 @@ func (c *Consul) filterChecks(name string) bool {
 	return c.checksFilter.MatchString(name)
 }
 
-func (c *Consul) addCheckToChart(check *agentCheck) {
-	var chart *Chart
+func (c *Consul) addCheckToCharts(id string, state *checkState) {
+	status, duration := c.checkCharts(state)
 
-	if check.ServiceID != "" {
-		chart = c.charts.Get("service_checks")
-	} else {
-		chart = c.charts.Get("unbound_checks")
-	}
+	_ = status.AddDim(&Dim{ID: id})
+	status.MarkNotCreated()
+
+	_ = duration.AddDim(&Dim{ID: id + "_state_duration", Name: id})
+	duration.MarkNotCreated()
+}
 
-	_ = chart.AddDim(&Dim{ID: check.CheckID})
-	chart.MarkNotCreated()
+func (c *Consul) removeCheckFromCharts(id string, state *checkState) {
+	status, duration := c.checkCharts(state)
+
+	_ = status.RemoveDim(id)
+	status.MarkNotCreated()
+
+	_ = duration.RemoveDim(id + "_state_duration")
+	duration.MarkNotCreated()
+}
+
+// checkCharts returns the status and the state duration charts of the check.
+func (c *Consul) checkCharts(state *checkState) (status, duration *Chart) {
+	if state.serviceBound {
+		return c.charts.Get("service_checks"), c.charts.Get("service_checks_state_duration")
+	}
+	return c.charts.Get("unbound_checks"), c.charts.Get("unbound_checks_state_duration")
 }
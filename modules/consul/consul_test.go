@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/web"
 
@@ -14,7 +15,11 @@ import (
 )
 
 var (
-	checks, _ = ioutil.ReadFile("testdata/checks.txt")
+	checks, _                = ioutil.ReadFile("testdata/checks.txt")
+	healthStateAnyData, _    = ioutil.ReadFile("testdata/health-state-any.json")
+	raftConfigurationData, _ = ioutil.ReadFile("testdata/raft-configuration.json")
+	autopilotHealthData, _   = ioutil.ReadFile("testdata/autopilot-health.json")
+	agentMetricsData, _      = ioutil.ReadFile("testdata/agent-metrics.json")
 )
 
 func TestNew(t *testing.T) {
@@ -88,10 +93,14 @@ func TestConsul_Collect(t *testing.T) {
 	assert.NotNil(t, metrics)
 
 	expected := map[string]int64{
-		"chk3":  2,
-		"mysql": 2,
-		"chk1":  0,
-		"chk2":  2,
+		"chk3":                 2,
+		"mysql":                2,
+		"chk1":                 0,
+		"chk2":                 2,
+		"chk1_state_duration":  0,
+		"chk2_state_duration":  0,
+		"chk3_state_duration":  0,
+		"mysql_state_duration": 0,
 	}
 
 	assert.Equal(t, expected, metrics)
@@ -99,6 +108,196 @@ func TestConsul_Collect(t *testing.T) {
 	assert.Len(t, mod.charts.Get("unbound_checks").Dims, 3)
 }
 
+func TestConsul_Collect_CheckStateChanges(t *testing.T) {
+	var data string
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v1/agent/checks" {
+					_, _ = w.Write([]byte(data))
+					return
+				}
+			}))
+	defer ts.Close()
+
+	now := time.Unix(1000, 0)
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.now = func() time.Time { return now }
+
+	require.True(t, mod.Init())
+
+	data = `{
+  "web": {"CheckID": "web", "Status": "passing", "ServiceID": "web"},
+  "_node_maintenance": {"Node": "node1", "CheckID": "_node_maintenance", "Name": "Node Maintenance Mode",
+    "Status": "critical", "Notes": "Maintenance mode is enabled for this node", "ServiceID": "", "ServiceName": ""},
+  "_service_maintenance:db": {"Node": "node1", "CheckID": "_service_maintenance:db", "Name": "Service Maintenance Mode",
+    "Status": "critical", "Notes": "Maintenance mode is enabled for this service", "ServiceID": "db", "ServiceName": "db"},
+  "odd": {"CheckID": "odd", "Status": "bogus"}
+}`
+	assert.Equal(t, map[string]int64{
+		"web":                                    0,
+		"web_state_duration":                     0,
+		"_node_maintenance":                      3,
+		"_node_maintenance_state_duration":       0,
+		"_service_maintenance:db":                3,
+		"_service_maintenance:db_state_duration": 0,
+		"odd":                                    4,
+		"odd_state_duration":                     0,
+	}, mod.Collect())
+	assert.Len(t, mod.charts.Get("unbound_checks").Dims, 2)
+	assert.Len(t, mod.charts.Get("unbound_checks_state_duration").Dims, 2)
+
+	now = now.Add(time.Second * 30)
+	data = `{
+  "web": {"CheckID": "web", "Status": "critical", "ServiceID": "web"},
+  "odd": {"CheckID": "odd", "Status": "bogus"}
+}`
+	assert.Equal(t, map[string]int64{
+		"web":                2,
+		"web_state_duration": 0,
+		"odd":                4,
+		"odd_state_duration": 30,
+	}, mod.Collect())
+	assert.False(t, mod.charts.Get("unbound_checks").HasDim("_node_maintenance"))
+	assert.False(t, mod.charts.Get("unbound_checks_state_duration").HasDim("_node_maintenance_state_duration"))
+	assert.NotContains(t, mod.activeChecks, "_node_maintenance")
+	assert.NotContains(t, mod.activeChecks, "_service_maintenance:db")
+
+	now = now.Add(time.Second * 10)
+	metrics := mod.Collect()
+	assert.Equal(t, int64(10), metrics["web_state_duration"])
+	assert.Equal(t, int64(40), metrics["odd_state_duration"])
+}
+
+func TestConsul_Collect_MaxChecks(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v1/agent/checks" {
+					_, _ = w.Write(checks)
+					return
+				}
+			}))
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.MaxChecks = 2
+
+	require.True(t, mod.Init())
+	require.NotNil(t, mod.Collect())
+	assert.Len(t, mod.activeChecks, 2)
+}
+
+func TestConsul_Collect_Cluster(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Consul-Token") != "secret" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				switch r.URL.Path {
+				case "/v1/agent/checks":
+					_, _ = w.Write(checks)
+				case "/v1/health/state/any":
+					_, _ = w.Write(healthStateAnyData)
+				case "/v1/operator/raft/configuration":
+					_, _ = w.Write(raftConfigurationData)
+				case "/v1/operator/autopilot/health":
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = w.Write(autopilotHealthData)
+				case "/v1/agent/metrics":
+					_, _ = w.Write(agentMetricsData)
+				case "/v1/kv/service/web":
+					if _, ok := r.URL.Query()["keys"]; ok {
+						_, _ = w.Write([]byte(`["service/web/a","service/web/b","service/web/c"]`))
+						return
+					}
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.ACLToken = "secret"
+	mod.CollectClusterHealth = true
+	mod.CollectRaft = true
+	mod.KVPrefixes = []string{"service/web", "missing"}
+
+	require.True(t, mod.Init())
+	assert.NoError(t, module.CheckCharts(*mod.Charts()...))
+	assert.Len(t, mod.Charts().Get("kv_keys").Dims, 2)
+
+	metrics := mod.Collect()
+	require.NotNil(t, metrics)
+
+	expected := map[string]int64{
+		"chk3":                        2,
+		"mysql":                       2,
+		"chk1":                        0,
+		"chk2":                        2,
+		"chk1_state_duration":         0,
+		"chk2_state_duration":         0,
+		"chk3_state_duration":         0,
+		"mysql_state_duration":        0,
+		"cluster_services_passing":    1,
+		"cluster_services_warning":    1,
+		"cluster_services_critical":   1,
+		"cluster_nodes_passing":       1,
+		"cluster_nodes_warning":       1,
+		"cluster_nodes_critical":      1,
+		"raft_has_leader":             1,
+		"raft_is_leader":              0,
+		"raft_peers":                  3,
+		"raft_voters":                 2,
+		"raft_last_contact_mean":      3125,
+		"raft_last_contact_max":       5250,
+		"autopilot_healthy":           0,
+		"autopilot_failure_tolerance": 0,
+		"autopilot_servers_healthy":   2,
+		"autopilot_servers_unhealthy": 1,
+		"kv_keys_service/web":         3,
+		"kv_keys_missing":             0,
+	}
+
+	assert.Equal(t, expected, metrics)
+}
+
+func TestConsul_Collect_NoLeader(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/agent/checks":
+					_, _ = w.Write(checks)
+				case "/v1/operator/raft/configuration", "/v1/operator/autopilot/health":
+					w.WriteHeader(http.StatusInternalServerError)
+					_, _ = w.Write([]byte("No cluster leader"))
+				case "/v1/agent/metrics":
+					_, _ = w.Write([]byte(`{"Gauges":[],"Samples":[]}`))
+				}
+			}))
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.CollectRaft = true
+
+	require.True(t, mod.Init())
+
+	metrics := mod.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["raft_has_leader"])
+	assert.NotContains(t, metrics, "raft_peers")
+	assert.NotContains(t, metrics, "autopilot_healthy")
+}
+
 func TestConsul_InvalidData(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(
@@ -1,20 +1,61 @@
 package dnsquery
 
-import "github.com/netdata/go-orchestrator/module"
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/module"
+)
 
 type (
 	// Charts is an alias for module.Charts
 	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
 	// Dim is an alias for module.Dim
 	Dim = module.Dim
 )
 
-var charts = Charts{
-	{
+var (
+	queryTimeChart = Chart{
 		ID:    "query_time",
 		Title: "DNS Query Time",
 		Units: "ms",
 		Fam:   "query time",
 		Ctx:   "dns_query_time.query_time",
-	},
+	}
+	badAnswerChart = Chart{
+		ID:    "bad_answer",
+		Title: "DNS Bad Answers",
+		Units: "boolean",
+		Fam:   "answer",
+		Ctx:   "dns_query_time.bad_answer",
+	}
+	typeQueryTimeChart = Chart{
+		ID:    "query_time_%s",
+		Title: "DNS Query Time",
+		Units: "ms",
+		Fam:   "%s record",
+		Ctx:   "dns_query_time.query_time",
+	}
+	typeBadAnswerChart = Chart{
+		ID:    "bad_answer_%s",
+		Title: "DNS Bad Answers",
+		Units: "boolean",
+		Fam:   "%s record",
+		Ctx:   "dns_query_time.bad_answer",
+	}
+	soaSerialChart = Chart{
+		ID:    "soa_serial",
+		Title: "DNS SOA Serial Number",
+		Units: "serial",
+		Fam:   "SOA record",
+		Ctx:   "dns_query_time.soa_serial",
+	}
+)
+
+func newChart(tmpl Chart, rtype string) *Chart {
+	chart := tmpl.Copy()
+	chart.ID = fmt.Sprintf(chart.ID, rtype)
+	chart.Fam = fmt.Sprintf(chart.Fam, rtype)
+	return chart
 }
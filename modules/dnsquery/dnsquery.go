@@ -1,9 +1,12 @@
 package dnsquery
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +31,9 @@ const (
 	defaultNetwork    = "udp"
 	defaultRecordType = "A"
 	defaultPort       = 53
+	defaultTLSPort    = 853
+	defaultHTTPSPort  = 443
+	defaultDoHPath    = "/dns-query"
 )
 
 // New creates DNSQuery with default values
@@ -36,12 +42,12 @@ func New() *DNSQuery {
 		Timeout:    web.Duration{Duration: defaultTimeout},
 		Network:    defaultNetwork,
 		RecordType: defaultRecordType,
-		Port:       defaultPort,
 
 		task:             make(chan task),
 		taskDone:         make(chan struct{}),
 		exchangerFactory: newExchanger,
 		servers:          make([]*server, 0),
+		queries:          make([]*query, 0),
 		workers:          make([]*worker, 0),
 	}
 }
@@ -50,17 +56,36 @@ type exchanger interface {
 	Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error)
 }
 
-func newExchanger(network string, timeout time.Duration) exchanger {
+func newExchanger(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+	if network == "https" {
+		return newDoHClient(timeout, tlsConfig)
+	}
+
 	return &dns.Client{
 		Net:         network,
 		ReadTimeout: timeout,
+		TLSConfig:   tlsConfig,
 	}
 }
 
 type server struct {
 	id   string
 	name string
-	port int
+	// address is host:port for udp, tcp and tcp-tls and URL for https
+	address string
+}
+
+type recordType struct {
+	name  string
+	rtype uint16
+	// validator validates the answers of the record type queries, nil if nothing is expected
+	validator *validator
+}
+
+// query is a server and record type pair, it keeps the result of the last query.
+type query struct {
+	server *server
+	rtype  recordType
 
 	resp *dns.Msg
 	rtt  time.Duration
@@ -71,21 +96,26 @@ type server struct {
 type DNSQuery struct {
 	module.Base
 
-	Domains    []string
-	Servers    []string
-	Network    string
-	RecordType string `yaml:"record_type"`
-	Port       int
-	Timeout    web.Duration
+	Domains             []string
+	Servers             []string
+	Network             string
+	RecordType          string   `yaml:"record_type"`
+	RecordTypes         []string `yaml:"record_types"`
+	Port                int
+	Timeout             web.Duration
+	Expected            Expected
+	web.ClientTLSConfig `yaml:",inline"`
 
 	task     chan task
 	taskDone chan struct{}
 
-	exchangerFactory func(network string, duration time.Duration) exchanger
+	exchangerFactory func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger
 
-	rtype   uint16
-	servers []*server
-	workers []*worker
+	rtypes    []recordType
+	tlsConfig *tls.Config
+	servers   []*server
+	queries   []*query
+	workers   []*worker
 }
 
 // Cleanup makes cleanup
@@ -106,16 +136,58 @@ func (d *DNSQuery) setup() error {
 		return errors.New("no servers specified")
 	}
 
-	if !(d.Network == "" || d.Network == "udp" || d.Network == "tcp" || d.Network == "tcp-tls") {
+	switch d.Network {
+	case "":
+		d.Network = defaultNetwork
+	case "udp", "tcp", "tcp-tls", "https":
+	default:
 		return fmt.Errorf("wrong network transport : %s", d.Network)
 	}
 
-	rtype, err := parseRecordType(d.RecordType)
+	if d.Port == 0 {
+		d.Port = defaultNetworkPort(d.Network)
+	}
 
-	if err != nil {
-		return fmt.Errorf("error on parsing record type : %s", err)
+	types := d.RecordTypes
+	if len(types) == 0 {
+		types = []string{d.RecordType}
+	}
+
+	d.rtypes = d.rtypes[:0]
+	seen := make(map[string]bool)
+
+	for _, name := range types {
+		name = strings.ToUpper(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		rtype, err := parseRecordType(name)
+		if err != nil {
+			return fmt.Errorf("error on parsing record type : %s", err)
+		}
+
+		v, err := newValidator(d.Expected.forRecordType(name))
+		if err != nil {
+			return fmt.Errorf("error on creating %s answer validator : %v", name, err)
+		}
+		d.rtypes = append(d.rtypes, recordType{name: name, rtype: rtype, validator: v})
+	}
+
+	for name, exp := range d.Expected.RecordTypes {
+		if !seen[strings.ToUpper(name)] {
+			return fmt.Errorf("expected : '%s' is not a queried record type", name)
+		}
+		if len(exp.RecordTypes) > 0 {
+			return fmt.Errorf("expected : '%s' has nested record types", name)
+		}
+	}
+
+	var err error
+	if d.tlsConfig, err = web.NewTLSConfig(d.ClientTLSConfig); err != nil {
+		return fmt.Errorf("error on creating TLS config : %v", err)
 	}
-	d.rtype = rtype
 
 	return nil
 }
@@ -127,12 +199,21 @@ func (d *DNSQuery) Init() bool {
 		return false
 	}
 
-	exch := d.exchangerFactory(d.Network, d.Timeout.Duration)
+	exch := d.exchangerFactory(d.Network, d.Timeout.Duration, d.tlsConfig)
+
+	for _, name := range d.Servers {
+		srv := &server{
+			id:      serverNameReplacer.Replace(name),
+			name:    name,
+			address: serverAddress(d.Network, name, d.Port),
+		}
+		d.servers = append(d.servers, srv)
 
-	for _, srv := range d.Servers {
-		d.servers = append(d.servers, &server{id: serverNameReplacer.Replace(srv), name: srv, port: d.Port})
-		// newWorker spawns worker goroutine
-		d.workers = append(d.workers, newWorker(exch, d.task, d.taskDone))
+		for _, rtype := range d.rtypes {
+			d.queries = append(d.queries, &query{server: srv, rtype: rtype})
+			// newWorker spawns worker goroutine
+			d.workers = append(d.workers, newWorker(exch, d.task, d.taskDone))
+		}
 	}
 
 	return true
@@ -145,16 +226,39 @@ func (DNSQuery) Check() bool {
 
 // Charts creates Charts
 func (d DNSQuery) Charts() *Charts {
-	charts := charts.Copy()
-
-	for _, srv := range d.servers {
-		chart := charts.Get("query_time")
-		dim := &Dim{ID: srv.id, Name: srv.name, Div: 1000000}
+	charts := &Charts{}
 
-		if err := chart.AddDim(dim); err != nil {
+	for _, rtype := range d.rtypes {
+		chart := d.newChart(queryTimeChart, typeQueryTimeChart, rtype)
+		for _, srv := range d.servers {
+			_ = chart.AddDim(&Dim{ID: d.metricKey(srv, rtype), Name: srv.name, Div: 1000000})
+		}
+		if err := charts.Add(chart); err != nil {
 			d.Errorf("error on creating charts : %s", err)
 			return nil
 		}
+
+		if rtype.validator != nil {
+			chart = d.newChart(badAnswerChart, typeBadAnswerChart, rtype)
+			for _, srv := range d.servers {
+				_ = chart.AddDim(&Dim{ID: d.metricKey(srv, rtype) + "_bad_answer", Name: srv.name})
+			}
+			if err := charts.Add(chart); err != nil {
+				d.Errorf("error on creating charts : %s", err)
+				return nil
+			}
+		}
+
+		if rtype.rtype == dns.TypeSOA {
+			chart = soaSerialChart.Copy()
+			for _, srv := range d.servers {
+				_ = chart.AddDim(&Dim{ID: srv.id + "_soa_serial", Name: srv.name})
+			}
+			if err := charts.Add(chart); err != nil {
+				d.Errorf("error on creating charts : %s", err)
+				return nil
+			}
+		}
 	}
 
 	return charts
@@ -165,28 +269,42 @@ func (d *DNSQuery) Collect() map[string]int64 {
 	domain := randomDomain(d.Domains)
 	d.Debugf("current domain : %s", domain)
 
-	for _, srv := range d.servers {
-		d.task <- task{server: srv, domain: domain, rtype: d.rtype}
+	for _, q := range d.queries {
+		v := q.rtype.validator
+		d.task <- task{query: q, domain: domain, ad: v != nil && v.ad}
 	}
 
-	for range d.servers {
+	for range d.queries {
 		<-d.taskDone
 	}
 
 	metrics := make(map[string]int64)
 
-	for _, srv := range d.servers {
-		if srv.resp != nil && srv.resp.Rcode != dns.RcodeSuccess {
-			d.Errorf("invalid answer from %s after %s query for %s", srv.name, d.RecordType, domain)
+	for _, q := range d.queries {
+		srv, rtype := q.server, q.rtype
+
+		if q.err != nil {
+			d.Errorf("error on querying %s after %s query for %s : %s", srv.name, rtype.name, domain, q.err)
 			continue
 		}
 
-		if srv.err != nil {
-			d.Errorf("error on querying %s after %s query for %s : %s", srv.name, d.RecordType, domain, srv.err)
+		if rtype.validator != nil {
+			key := d.metricKey(srv, rtype) + "_bad_answer"
+			metrics[key] = 0
+			if err := rtype.validator.validate(q.resp, rtype.rtype); err != nil {
+				d.Warningf("bad answer from %s after %s query for %s : %v", srv.name, rtype.name, domain, err)
+				metrics[key] = 1
+			}
+		} else if q.resp != nil && q.resp.Rcode != dns.RcodeSuccess {
+			d.Errorf("invalid answer from %s after %s query for %s", srv.name, rtype.name, domain)
 			continue
 		}
 
-		metrics[srv.id] = srv.rtt.Nanoseconds()
+		metrics[d.metricKey(srv, rtype)] = q.rtt.Nanoseconds()
+
+		if rtype.rtype == dns.TypeSOA && q.resp != nil {
+			collectSOASerial(metrics, srv, q.resp)
+		}
 	}
 
 	if len(metrics) == 0 {
@@ -196,6 +314,52 @@ func (d *DNSQuery) Collect() map[string]int64 {
 	return metrics
 }
 
+// newChart keeps the charts ids of a single record type job unchanged,
+// the charts are per record type only when multiple record types are queried.
+func (d DNSQuery) newChart(tmpl, typeTmpl Chart, rtype recordType) *Chart {
+	if len(d.rtypes) == 1 {
+		return tmpl.Copy()
+	}
+	return newChart(typeTmpl, rtype.name)
+}
+
+func (d DNSQuery) metricKey(srv *server, rtype recordType) string {
+	if len(d.rtypes) == 1 {
+		return srv.id
+	}
+	return srv.id + "_" + rtype.name
+}
+
+func collectSOASerial(metrics map[string]int64, srv *server, resp *dns.Msg) {
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			metrics[srv.id+"_soa_serial"] = int64(soa.Serial)
+			return
+		}
+	}
+}
+
+func defaultNetworkPort(network string) int {
+	switch network {
+	case "tcp-tls":
+		return defaultTLSPort
+	case "https":
+		return defaultHTTPSPort
+	}
+	return defaultPort
+}
+
+func serverAddress(network, name string, port int) string {
+	if network != "https" {
+		return net.JoinHostPort(name, strconv.Itoa(port))
+	}
+
+	if strings.HasPrefix(name, "https://") {
+		return name
+	}
+	return "https://" + net.JoinHostPort(name, strconv.Itoa(port)) + defaultDoHPath
+}
+
 func parseRecordType(recordType string) (uint16, error) {
 	var rtype uint16
 
@@ -234,4 +398,4 @@ func randomDomain(domains []string) string {
 	return domains[rand.Intn(len(domains))]
 }
 
-var serverNameReplacer = strings.NewReplacer(".", "_")
+var serverNameReplacer = strings.NewReplacer(".", "_", ":", "_", "/", "_")
@@ -1,7 +1,11 @@
 package dnsquery
 
 import (
+	"crypto/tls"
 	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -9,6 +13,7 @@ import (
 	"github.com/netdata/go-orchestrator/module"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 func TestNew(t *testing.T) {
@@ -76,7 +81,7 @@ func TestDNSQuery_Collect(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
 		return okMockExchanger{}
 	}
 
@@ -96,7 +101,7 @@ func TestDNSQuery_Collect_Error(t *testing.T) {
 
 	mod.Domains = []string{"google.com"}
 	mod.Servers = []string{"8.8.8.8"}
-	mod.exchangerFactory = func(network string, duration time.Duration) exchanger {
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
 		return errMockExchanger{}
 	}
 
@@ -110,6 +115,256 @@ func TestDNSQuery_Collect_Error(t *testing.T) {
 	)
 }
 
+func TestDNSQuery_Init_Expected(t *testing.T) {
+	mod := New()
+
+	mod.Domains = []string{"google.com"}
+	mod.Servers = []string{"8.8.8.8"}
+	mod.Expected.Rcode = "BADRCODE"
+	assert.False(t, mod.Init())
+
+	mod.Expected.Rcode = "nxdomain"
+	require.True(t, mod.Init())
+	assert.Equal(t, dns.RcodeNameError, mod.rtypes[0].validator.rcode)
+	assert.True(t, mod.Charts().Get("bad_answer").HasDim("8_8_8_8_bad_answer"))
+
+	mod = New()
+	mod.Domains = []string{"google.com"}
+	mod.Servers = []string{"8.8.8.8"}
+	mod.RecordTypes = []string{"A", "AAAA"}
+	mod.Expected = Expected{RecordTypes: map[string]Expected{"MX": {MinAnswers: 1}}}
+	assert.False(t, mod.Init())
+
+	mod = New()
+	mod.Domains = []string{"google.com"}
+	mod.Servers = []string{"8.8.8.8"}
+	mod.RecordTypes = []string{"A", "AAAA"}
+	mod.Expected = Expected{RecordTypes: map[string]Expected{"a": {Answers: []string{"10.0.0.1"}}}}
+	require.True(t, mod.Init())
+	assert.True(t, mod.Charts().Has("bad_answer_A"))
+	assert.False(t, mod.Charts().Has("bad_answer_AAAA"))
+
+	mod = New()
+	mod.Domains = []string{"google.com"}
+	mod.Servers = []string{"8.8.8.8"}
+	mod.RecordTypes = []string{"A", "AAAA"}
+	mod.Expected = Expected{Rcode: "NOERROR", MinTTL: 60}
+	require.True(t, mod.Init())
+	assert.True(t, mod.Charts().Get("bad_answer_AAAA").HasDim("8_8_8_8_AAAA_bad_answer"))
+}
+
+func TestDNSQuery_Collect_ExpectedRecordTypes(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	config := `
+domains: [example.com]
+servers: [8.8.8.8]
+record_types: [A, AAAA]
+expected:
+  min_ttl: 300
+  A:
+    answers: [10.0.0.1, 10.0.0.2]
+  AAAA:
+    min_answers: 1
+`
+	require.NoError(t, yaml.Unmarshal([]byte(config), mod))
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+		return answerMockExchanger{}
+	}
+
+	require.True(t, mod.Init())
+	assert.Equal(t, 300, int(mod.rtypes[1].validator.minTTL))
+
+	assert.Equal(
+		t,
+		map[string]int64{
+			"8_8_8_8_A":               1000000000,
+			"8_8_8_8_A_bad_answer":    0,
+			"8_8_8_8_AAAA":            1000000000,
+			"8_8_8_8_AAAA_bad_answer": 1,
+		},
+		mod.Collect(),
+	)
+}
+
+func TestDNSQuery_Collect_Expected(t *testing.T) {
+	tests := map[string]struct {
+		expected Expected
+		bad      int64
+	}{
+		"answer matches":       {expected: Expected{Answers: []string{"10.0.0.1", "10.0.0.2"}}, bad: 0},
+		"answer doesn't match": {expected: Expected{Answers: []string{"10.0.0.1"}}, bad: 1},
+		"rcode doesn't match":  {expected: Expected{Rcode: "NXDOMAIN"}, bad: 1},
+		"ttl is ok":            {expected: Expected{MinTTL: 300}, bad: 0},
+		"ttl is too low":       {expected: Expected{MinTTL: 301}, bad: 1},
+		"ad flag is not set":   {expected: Expected{AuthenticatedData: true}, bad: 1},
+		"enough answers":       {expected: Expected{MinAnswers: 2, MaxAnswers: 2}, bad: 0},
+		"not enough answers":   {expected: Expected{MinAnswers: 3}, bad: 1},
+		"too many answers":     {expected: Expected{MaxAnswers: 1}, bad: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			defer mod.Cleanup()
+
+			mod.Domains = []string{"example.com"}
+			mod.Servers = []string{"8.8.8.8"}
+			mod.Expected = test.expected
+			mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+				return answerMockExchanger{}
+			}
+
+			require.True(t, mod.Init())
+
+			assert.Equal(
+				t,
+				map[string]int64{"8_8_8_8": 1000000000, "8_8_8_8_bad_answer": test.bad},
+				mod.Collect(),
+			)
+		})
+	}
+}
+
+func TestDNSQuery_Init_Network(t *testing.T) {
+	tests := map[string]struct {
+		network string
+		server  string
+		address string
+	}{
+		"udp":           {network: "udp", server: "8.8.8.8", address: "8.8.8.8:53"},
+		"tcp-tls":       {network: "tcp-tls", server: "8.8.8.8", address: "8.8.8.8:853"},
+		"https":         {network: "https", server: "8.8.8.8", address: "https://8.8.8.8:443/dns-query"},
+		"https url":     {network: "https", server: "https://dns.google/resolve", address: "https://dns.google/resolve"},
+		"ipv6 with tcp": {network: "tcp", server: "2001:4860:4860::8888", address: "[2001:4860:4860::8888]:53"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mod := New()
+			mod.Domains = []string{"google.com"}
+			mod.Servers = []string{test.server}
+			mod.Network = test.network
+
+			require.True(t, mod.Init())
+			defer mod.Cleanup()
+
+			assert.Equal(t, test.address, mod.servers[0].address)
+		})
+	}
+
+	mod := New()
+	mod.Domains = []string{"google.com"}
+	mod.Servers = []string{"8.8.8.8"}
+	mod.Network = "quic"
+	assert.False(t, mod.Init())
+}
+
+func TestDNSQuery_Collect_RecordTypes(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Domains = []string{"example.com"}
+	mod.Servers = []string{"8.8.8.8", "8.8.4.4"}
+	mod.RecordTypes = []string{"A", "aaaa", "SOA", "A"}
+	mod.exchangerFactory = func(network string, timeout time.Duration, tlsConfig *tls.Config) exchanger {
+		return answerMockExchanger{}
+	}
+
+	require.True(t, mod.Init())
+	assert.Len(t, mod.queries, 6)
+	assert.Len(t, mod.workers, 6)
+
+	charts := mod.Charts()
+	require.NotNil(t, charts)
+	assert.NoError(t, module.CheckCharts(*charts...))
+	assert.True(t, charts.Has("query_time_A"))
+	assert.True(t, charts.Has("query_time_AAAA"))
+	assert.True(t, charts.Has("query_time_SOA"))
+	assert.True(t, charts.Get("soa_serial").HasDim("8_8_4_4_soa_serial"))
+
+	assert.Equal(
+		t,
+		map[string]int64{
+			"8_8_8_8_A":          1000000000,
+			"8_8_8_8_AAAA":       1000000000,
+			"8_8_8_8_SOA":        1000000000,
+			"8_8_8_8_soa_serial": 2019032601,
+			"8_8_4_4_A":          1000000000,
+			"8_8_4_4_AAAA":       1000000000,
+			"8_8_4_4_SOA":        1000000000,
+			"8_8_4_4_soa_serial": 2019032601,
+		},
+		mod.Collect(),
+	)
+}
+
+func TestDNSQuery_Collect_DoH(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != dnsMessageContentType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		msg := new(dns.Msg)
+		if err := msg.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		resp, _, _ := answerMockExchanger{}.Exchange(msg, "")
+		b, _ := resp.Pack()
+		w.Header().Set("Content-Type", dnsMessageContentType)
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Domains = []string{"example.com"}
+	mod.Servers = []string{ts.URL + "/dns-query"}
+	mod.Network = "https"
+	mod.InsecureSkipVerify = true
+	mod.Expected.Answers = []string{"10.0.0.1", "10.0.0.2"}
+
+	require.True(t, mod.Init())
+
+	metrics := mod.Collect()
+	require.NotNil(t, metrics)
+
+	id := mod.servers[0].id
+	assert.Equal(t, int64(0), metrics[id+"_bad_answer"])
+	assert.True(t, metrics[id] > 0)
+}
+
+type answerMockExchanger struct{}
+
+func (m answerMockExchanger) Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	var records []string
+	switch msg.Question[0].Qtype {
+	case dns.TypeA:
+		records = []string{"example.com. 300 IN A 10.0.0.1", "example.com. 600 IN A 10.0.0.2"}
+	case dns.TypeSOA:
+		records = []string{"example.com. 3600 IN SOA ns.example.com. admin.example.com. 2019032601 7200 3600 1209600 3600"}
+	}
+
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			return nil, 0, err
+		}
+		resp.Answer = append(resp.Answer, rr)
+	}
+
+	return resp, time.Second, nil
+}
+
 type okMockExchanger struct{}
 
 func (m okMockExchanger) Exchange(msg *dns.Msg, address string) (response *dns.Msg, rtt time.Duration, err error) {
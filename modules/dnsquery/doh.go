@@ -0,0 +1,79 @@
+package dnsquery
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const dnsMessageContentType = "application/dns-message"
+
+// dohClient is a DNS-over-HTTPS (RFC 8484) exchanger.
+type dohClient struct {
+	httpClient *http.Client
+}
+
+func newDoHClient(timeout time.Duration, tlsConfig *tls.Config) *dohClient {
+	return &dohClient{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+}
+
+// Exchange sends the query using POST method, address is the DoH endpoint URL.
+func (c dohClient) Exchange(msg *dns.Msg, address string) (*dns.Msg, time.Duration, error) {
+	// RFC 8484 4.1: DNS ID SHOULD be 0 to be more HTTP cache friendly
+	id := msg.Id
+	msg.Id = 0
+
+	b, err := msg.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(b))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	t := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("%s returned HTTP status %d", address, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	rtt := time.Since(t)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, 0, fmt.Errorf("error on unpacking response from %s : %v", address, err)
+	}
+	r.Id = id
+
+	return r, rtt, nil
+}
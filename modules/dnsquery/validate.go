@@ -0,0 +1,169 @@
+package dnsquery
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Expected describes what a valid answer looks like.
+// RecordTypes are the record type specific expectations (keyed by the record type, e.g. 'A' or 'AAAA'),
+// their options override the common ones.
+type Expected struct {
+	Rcode             string              `yaml:"rcode"`
+	Answers           []string            `yaml:"answers"`
+	MinTTL            uint32              `yaml:"min_ttl"`
+	AuthenticatedData bool                `yaml:"authenticated_data"`
+	MinAnswers        int                 `yaml:"min_answers"`
+	MaxAnswers        int                 `yaml:"max_answers"`
+	RecordTypes       map[string]Expected `yaml:",inline"`
+}
+
+func (e Expected) isEmpty() bool {
+	return e.Rcode == "" && len(e.Answers) == 0 && e.MinTTL == 0 && !e.AuthenticatedData &&
+		e.MinAnswers == 0 && e.MaxAnswers == 0
+}
+
+// forRecordType returns the common expectations overridden by the record type specific ones.
+func (e Expected) forRecordType(name string) Expected {
+	exp := e
+	exp.RecordTypes = nil
+
+	for k, v := range e.RecordTypes {
+		if !strings.EqualFold(k, name) {
+			continue
+		}
+		if v.Rcode != "" {
+			exp.Rcode = v.Rcode
+		}
+		if len(v.Answers) > 0 {
+			exp.Answers = v.Answers
+		}
+		if v.MinTTL != 0 {
+			exp.MinTTL = v.MinTTL
+		}
+		if v.AuthenticatedData {
+			exp.AuthenticatedData = true
+		}
+		if v.MinAnswers != 0 {
+			exp.MinAnswers = v.MinAnswers
+		}
+		if v.MaxAnswers != 0 {
+			exp.MaxAnswers = v.MaxAnswers
+		}
+	}
+
+	return exp
+}
+
+type validator struct {
+	rcode      int
+	answers    map[string]bool
+	minTTL     uint32
+	ad         bool
+	minAnswers int
+	maxAnswers int
+}
+
+func newValidator(e Expected) (*validator, error) {
+	if e.isEmpty() {
+		return nil, nil
+	}
+
+	v := &validator{
+		rcode:      dns.RcodeSuccess,
+		minTTL:     e.MinTTL,
+		ad:         e.AuthenticatedData,
+		minAnswers: e.MinAnswers,
+		maxAnswers: e.MaxAnswers,
+	}
+
+	if e.Rcode != "" {
+		rcode, ok := dns.StringToRcode[strings.ToUpper(e.Rcode)]
+		if !ok {
+			return nil, fmt.Errorf("unknown rcode : %s", e.Rcode)
+		}
+		v.rcode = rcode
+	}
+
+	if v.maxAnswers != 0 && v.minAnswers > v.maxAnswers {
+		return nil, fmt.Errorf("min_answers (%d) is greater than max_answers (%d)", v.minAnswers, v.maxAnswers)
+	}
+
+	if len(e.Answers) > 0 {
+		v.answers = make(map[string]bool)
+		for _, a := range e.Answers {
+			v.answers[normalizeRData(a)] = true
+		}
+	}
+
+	return v, nil
+}
+
+// validate checks the response against the expectations, it returns nil if the response is valid.
+// Only records of the queried type are taken into account (CNAME chain records are skipped).
+func (v validator) validate(resp *dns.Msg, rtype uint16) error {
+	if resp == nil {
+		return fmt.Errorf("empty response")
+	}
+
+	if resp.Rcode != v.rcode {
+		return fmt.Errorf("rcode %s, expected %s", dns.RcodeToString[resp.Rcode], dns.RcodeToString[v.rcode])
+	}
+
+	if v.ad && !resp.AuthenticatedData {
+		return fmt.Errorf("AD flag is not set")
+	}
+
+	var answers []dns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == rtype || rtype == dns.TypeANY {
+			answers = append(answers, rr)
+		}
+	}
+
+	if len(answers) < v.minAnswers {
+		return fmt.Errorf("%d answers, expected at least %d", len(answers), v.minAnswers)
+	}
+
+	if v.maxAnswers != 0 && len(answers) > v.maxAnswers {
+		return fmt.Errorf("%d answers, expected at most %d", len(answers), v.maxAnswers)
+	}
+
+	for _, rr := range answers {
+		if rr.Header().Ttl < v.minTTL {
+			return fmt.Errorf("'%s' TTL %d is less than %d", rr, rr.Header().Ttl, v.minTTL)
+		}
+
+		if v.answers != nil && !v.answers[normalizeRData(rdata(rr))] {
+			return fmt.Errorf("unexpected answer '%s'", rr)
+		}
+	}
+
+	return nil
+}
+
+// rdata returns textual representation of the record data.
+func rdata(rr dns.RR) string {
+	switch r := rr.(type) {
+	case *dns.A:
+		return r.A.String()
+	case *dns.AAAA:
+		return r.AAAA.String()
+	case *dns.TXT:
+		return strings.Join(r.Txt, "")
+	}
+	return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+func normalizeRData(s string) string {
+	s = strings.TrimSpace(s)
+
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.String()
+	}
+
+	return strings.ToLower(strings.TrimSuffix(s, "."))
+}
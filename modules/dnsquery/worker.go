@@ -1,16 +1,14 @@
 package dnsquery
 
 import (
-	"net"
-	"strconv"
-
 	"github.com/miekg/dns"
 )
 
 type task struct {
-	server *server
+	query  *query
 	domain string
-	rtype  uint16
+	// ad requests DNSSEC validation (AD flag) from the server
+	ad bool
 }
 
 func newWorker(exchanger exchanger, task chan task, taskDone chan struct{}) *worker {
@@ -40,14 +38,16 @@ func (w *worker) workLoop() {
 
 func (w *worker) doWork(t task) {
 	msg := new(dns.Msg)
-	msg.SetQuestion(dns.Fqdn(t.domain), t.rtype)
-	address := net.JoinHostPort(t.server.name, strconv.Itoa(t.server.port))
-
-	resp, rtt, err := w.exchanger.Exchange(msg, address)
+	msg.SetQuestion(dns.Fqdn(t.domain), t.query.rtype.rtype)
+	if t.ad {
+		msg.AuthenticatedData = true
+		msg.SetEdns0(4096, true)
+	}
+	resp, rtt, err := w.exchanger.Exchange(msg, t.query.server.address)
 
-	t.server.resp = resp
-	t.server.rtt = rtt
-	t.server.err = err
+	t.query.resp = resp
+	t.query.rtt = rtt
+	t.query.err = err
 
 	w.taskDone <- struct{}{}
 }
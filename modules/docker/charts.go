@@ -0,0 +1,103 @@
+package docker
+
+import "github.com/netdata/go-orchestrator/module"
+
+type (
+	// Charts is an alias for module.Charts
+	Charts = module.Charts
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
+)
+
+var containersCharts = Charts{
+	{
+		ID:    "containers_state",
+		Title: "Containers In Various States",
+		Units: "containers",
+		Fam:   "containers",
+		Ctx:   "docker.containers_state",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "containers_state_running", Name: "running"},
+			{ID: "containers_state_paused", Name: "paused"},
+			{ID: "containers_state_exited", Name: "exited"},
+			{ID: "containers_state_created", Name: "created"},
+			{ID: "containers_state_restarting", Name: "restarting"},
+			{ID: "containers_state_removing", Name: "removing"},
+			{ID: "containers_state_dead", Name: "dead"},
+		},
+	},
+}
+
+// containerCharts are the per container charts, '%s' is the container name.
+var containerCharts = Charts{
+	{
+		ID:    "container_%s_cpu_usage",
+		Title: "CPU Usage",
+		Units: "percentage",
+		Fam:   "%s",
+		Ctx:   "docker.container_cpu_usage",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "container_%s_cpu_user", Name: "user", Algo: module.Incremental, Div: 1e7},
+			{ID: "container_%s_cpu_system", Name: "system", Algo: module.Incremental, Div: 1e7},
+		},
+	},
+	{
+		ID:    "container_%s_mem_usage",
+		Title: "Memory Usage",
+		Units: "MiB",
+		Fam:   "%s",
+		Ctx:   "docker.container_mem_usage",
+		Dims: Dims{
+			{ID: "container_%s_mem_used", Name: "used", Div: 1 << 20},
+		},
+	},
+	{
+		ID:    "container_%s_mem_usage_limit",
+		Title: "Memory Usage Limit",
+		Units: "MiB",
+		Fam:   "%s",
+		Ctx:   "docker.container_mem_usage_limit",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "container_%s_mem_available", Name: "available", Div: 1 << 20},
+			{ID: "container_%s_mem_used", Name: "used", Div: 1 << 20},
+		},
+	},
+	{
+		ID:    "container_%s_net_traffic",
+		Title: "Network Traffic",
+		Units: "kilobits/s",
+		Fam:   "%s",
+		Ctx:   "docker.container_net_traffic",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "container_%s_net_rx_bytes", Name: "received", Algo: module.Incremental, Mul: 8, Div: 1000},
+			{ID: "container_%s_net_tx_bytes", Name: "sent", Algo: module.Incremental, Mul: -8, Div: 1000},
+		},
+	},
+	{
+		ID:    "container_%s_net_packets",
+		Title: "Network Packets",
+		Units: "pps",
+		Fam:   "%s",
+		Ctx:   "docker.container_net_packets",
+		Dims: Dims{
+			{ID: "container_%s_net_rx_packets", Name: "received", Algo: module.Incremental},
+			{ID: "container_%s_net_tx_packets", Name: "sent", Algo: module.Incremental, Mul: -1},
+		},
+	},
+	{
+		ID:    "container_%s_blkio",
+		Title: "Block IO",
+		Units: "KiB/s",
+		Fam:   "%s",
+		Ctx:   "docker.container_blkio",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "container_%s_blkio_read", Name: "read", Algo: module.Incremental, Div: 1024},
+			{ID: "container_%s_blkio_write", Name: "write", Algo: module.Incremental, Mul: -1, Div: 1024},
+		},
+	},
+}
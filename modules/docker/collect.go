@@ -0,0 +1,208 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/netdata/go.d.plugin/pkg/dockerapi"
+)
+
+var nameReplacer = strings.NewReplacer(".", "_", " ", "_")
+
+func (d *Docker) collect() (map[string]int64, error) {
+	containers, err := d.client.Containers(true)
+	if err != nil {
+		return nil, err
+	}
+
+	mx := make(map[string]int64)
+
+	for _, dim := range containersCharts[0].Dims {
+		mx[dim.ID] = 0
+	}
+
+	var running []dockerapi.Container
+	for _, c := range containers {
+		mx["containers_state_"+c.State]++
+		if c.State == "running" && c.Name() != "" && d.filterContainer(c.Name()) {
+			running = append(running, c)
+		}
+	}
+
+	d.collectContainers(d.limitContainers(running), mx)
+
+	return mx, nil
+}
+
+// limitContainers applies the max_containers limit before the stats are requested.
+// The containers that already have charts are preferred, so the charts don't flap.
+func (d *Docker) limitContainers(containers []dockerapi.Container) []dockerapi.Container {
+	if d.MaxContainers <= 0 || len(containers) <= d.MaxContainers {
+		return containers
+	}
+
+	limited := make([]dockerapi.Container, 0, d.MaxContainers)
+	for _, c := range containers {
+		if d.containers[c.Name()] {
+			limited = append(limited, c)
+		}
+	}
+	for _, c := range containers {
+		if len(limited) >= d.MaxContainers {
+			break
+		}
+		if !d.containers[c.Name()] {
+			limited = append(limited, c)
+		}
+	}
+
+	d.Debugf("%d containers were skipped due to max_containers limit (%d)", len(containers)-len(limited), d.MaxContainers)
+
+	return limited
+}
+
+// collectContainers requests the containers stats concurrently using a bounded number of workers,
+// the stats request takes time.
+func (d *Docker) collectContainers(containers []dockerapi.Container, mx map[string]int64) {
+	workers := d.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if workers > len(containers) {
+		workers = len(containers)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		task  = make(chan int)
+		stats = make([]*dockerapi.ContainerStats, len(containers))
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range task {
+				s, err := d.client.ContainerStats(containers[i].ID)
+				if err != nil {
+					d.Error(err)
+					continue
+				}
+				stats[i] = s
+			}
+		}()
+	}
+	for i := range containers {
+		task <- i
+	}
+	close(task)
+	wg.Wait()
+
+	seen := make(map[string]bool)
+
+	for i, c := range containers {
+		name := c.Name()
+		if stats[i] == nil {
+			continue
+		}
+
+		if !d.containers[name] {
+			d.containers[name] = true
+			d.addContainerCharts(name)
+		}
+
+		seen[name] = true
+		collectContainerStats(mx, "container_"+nameReplacer.Replace(name), stats[i])
+	}
+
+	for name := range d.containers {
+		if !seen[name] {
+			delete(d.containers, name)
+			d.removeContainerCharts(name)
+		}
+	}
+}
+
+func collectContainerStats(mx map[string]int64, prefix string, stats *dockerapi.ContainerStats) {
+	mx[prefix+"_cpu_user"] = stats.CPUStats.CPUUsage.UsageInUsermode
+	mx[prefix+"_cpu_system"] = stats.CPUStats.CPUUsage.UsageInKernelmode
+
+	used := memoryUsed(stats.MemoryStats)
+	mx[prefix+"_mem_used"] = used
+	mx[prefix+"_mem_available"] = stats.MemoryStats.Limit - used
+
+	var rxBytes, txBytes, rxPackets, txPackets int64
+	for _, n := range stats.Networks {
+		rxBytes += n.RxBytes
+		txBytes += n.TxBytes
+		rxPackets += n.RxPackets
+		txPackets += n.TxPackets
+	}
+	mx[prefix+"_net_rx_bytes"] = rxBytes
+	mx[prefix+"_net_tx_bytes"] = txBytes
+	mx[prefix+"_net_rx_packets"] = rxPackets
+	mx[prefix+"_net_tx_packets"] = txPackets
+
+	var read, write int64
+	for _, e := range stats.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			read += e.Value
+		case "write":
+			write += e.Value
+		}
+	}
+	mx[prefix+"_blkio_read"] = read
+	mx[prefix+"_blkio_write"] = write
+}
+
+// memoryUsed returns the memory usage without the page cache, the same way 'docker stats' does.
+func memoryUsed(stats dockerapi.MemoryStats) int64 {
+	// cgroup v1
+	if v, ok := stats.Stats["total_inactive_file"]; ok && v < stats.Usage {
+		return stats.Usage - v
+	}
+	// cgroup v2
+	if v, ok := stats.Stats["inactive_file"]; ok && v < stats.Usage {
+		return stats.Usage - v
+	}
+	return stats.Usage
+}
+
+func (d Docker) filterContainer(name string) bool {
+	if d.containersFilter == nil {
+		return true
+	}
+	return d.containersFilter.MatchString(name)
+}
+
+func (d *Docker) addContainerCharts(name string) {
+	id := nameReplacer.Replace(name)
+	charts := containerCharts.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Fam = fmt.Sprintf(chart.Fam, name)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	_ = d.charts.Add(*charts...)
+}
+
+func (d *Docker) removeContainerCharts(name string) {
+	id := nameReplacer.Replace(name)
+
+	for _, chart := range containerCharts {
+		chart = d.charts.Get(fmt.Sprintf(chart.ID, id))
+		if chart == nil {
+			continue
+		}
+		chart.Obsolete = true
+		chart.MarkNotCreated()
+		chart.MarkRemove()
+	}
+}
@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/dockerapi"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+func init() {
+	creator := module.Creator{
+		Create: func() module.Module { return New() },
+	}
+
+	module.Register("docker", creator)
+}
+
+const (
+	defaultTimeout       = time.Second * 2
+	defaultMaxContainers = 100
+	defaultWorkers       = 8
+)
+
+// New creates Docker with default values.
+func New() *Docker {
+	config := Config{
+		Address:       dockerapi.DefaultAddress,
+		Timeout:       web.Duration{Duration: defaultTimeout},
+		MaxContainers: defaultMaxContainers,
+		Workers:       defaultWorkers,
+	}
+	return &Docker{
+		Config:     config,
+		charts:     containersCharts.Copy(),
+		containers: make(map[string]bool),
+	}
+}
+
+// Config is the Docker module configuration.
+type Config struct {
+	Address          string       `yaml:"address"`
+	Timeout          web.Duration `yaml:"timeout"`
+	ContainersFilter string       `yaml:"containers_filter"`
+	MaxContainers    int          `yaml:"max_containers"`
+	Workers          int          `yaml:"workers"`
+}
+
+type dockerClient interface {
+	Containers(all bool) ([]dockerapi.Container, error)
+	ContainerStats(id string) (*dockerapi.ContainerStats, error)
+}
+
+// Docker Docker module.
+type Docker struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	client           dockerClient
+	containersFilter matcher.Matcher
+	charts           *Charts
+	// containers is a set of the names of the containers that have charts
+	containers map[string]bool
+}
+
+// Cleanup makes cleanup.
+func (Docker) Cleanup() {}
+
+// Init makes initialization.
+func (d *Docker) Init() bool {
+	if d.Address == "" {
+		d.Error("address parameter is mandatory, please set")
+		return false
+	}
+
+	if d.ContainersFilter != "" {
+		m, err := matcher.NewSimplePatternsMatcher(d.ContainersFilter)
+		if err != nil {
+			d.Errorf("error on creating containers filter : %v", err)
+			return false
+		}
+		d.containersFilter = matcher.WithCache(m)
+	}
+
+	client, err := dockerapi.NewClient(d.Address, d.Timeout.Duration)
+	if err != nil {
+		d.Errorf("error on creating docker client : %v", err)
+		return false
+	}
+	d.client = client
+
+	return true
+}
+
+// Check makes check.
+func (d *Docker) Check() bool {
+	return len(d.Collect()) > 0
+}
+
+// Charts creates Charts.
+func (d Docker) Charts() *Charts {
+	return d.charts
+}
+
+// Collect collects metrics.
+func (d *Docker) Collect() map[string]int64 {
+	mx, err := d.collect()
+
+	if err != nil {
+		d.Error(err)
+		return nil
+	}
+
+	return mx
+}
@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/pkg/dockerapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testContainers, _ = ioutil.ReadFile("testdata/containers.json")
+	testStatsV1, _    = ioutil.ReadFile("testdata/stats-v1.json")
+	testStatsV2, _    = ioutil.ReadFile("testdata/stats-v2.json")
+)
+
+func TestNew(t *testing.T) {
+	job := New()
+
+	assert.IsType(t, (*Docker)(nil), job)
+	assert.Equal(t, dockerapi.DefaultAddress, job.Address)
+	assert.Equal(t, defaultTimeout, job.Timeout.Duration)
+	assert.Equal(t, defaultMaxContainers, job.MaxContainers)
+	assert.Equal(t, defaultWorkers, job.Workers)
+}
+
+func TestDocker_Charts(t *testing.T) { assert.NotNil(t, New().Charts()) }
+
+func TestDocker_Cleanup(t *testing.T) { New().Cleanup() }
+
+func TestDocker_Init(t *testing.T) { assert.True(t, New().Init()) }
+
+func TestDocker_InitNG(t *testing.T) {
+	job := New()
+	job.Address = ""
+	assert.False(t, job.Init())
+
+	job = New()
+	job.Address = "ftp://127.0.0.1"
+	assert.False(t, job.Init())
+
+	job = New()
+	job.ContainersFilter = "*["
+	assert.False(t, job.Init())
+}
+
+func newTestServer(t *testing.T, containers *[]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			assert.Equal(t, "true", r.URL.Query().Get("all"))
+			_, _ = w.Write(*containers)
+		case "/containers/1111/stats":
+			_, _ = w.Write(testStatsV1)
+		case "/containers/2222/stats":
+			_, _ = w.Write(testStatsV2)
+		default:
+			t.Errorf("unexpected request to %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestDocker_Check(t *testing.T) {
+	ts := newTestServer(t, &testContainers)
+	defer ts.Close()
+
+	job := New()
+	job.Address = ts.URL
+	job.ContainersFilter = "!test-* *"
+	require.True(t, job.Init())
+	assert.True(t, job.Check())
+}
+
+func TestDocker_CheckNG(t *testing.T) {
+	job := New()
+	job.Address = "http://127.0.0.1:38001"
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
+}
+
+func TestDocker_Collect(t *testing.T) {
+	containers := testContainers
+	ts := newTestServer(t, &containers)
+	defer ts.Close()
+
+	job := New()
+	job.Address = ts.URL
+	job.ContainersFilter = "!test-* *"
+	require.True(t, job.Init())
+
+	expected := map[string]int64{
+		"containers_state_created":    0,
+		"containers_state_dead":       0,
+		"containers_state_exited":     1,
+		"containers_state_paused":     0,
+		"containers_state_removing":   0,
+		"containers_state_restarting": 0,
+		"containers_state_running":    3,
+
+		"container_redis_blkio_read":     4096,
+		"container_redis_blkio_write":    8192,
+		"container_redis_cpu_system":     100000000,
+		"container_redis_cpu_user":       200000000,
+		"container_redis_mem_available":  1031798784,
+		"container_redis_mem_used":       41943040,
+		"container_redis_net_rx_bytes":   1500,
+		"container_redis_net_rx_packets": 15,
+		"container_redis_net_tx_bytes":   2500,
+		"container_redis_net_tx_packets": 25,
+		"container_web_1_blkio_read":     1024,
+		"container_web_1_blkio_write":    2048,
+		"container_web_1_cpu_system":     200000000,
+		"container_web_1_cpu_user":       400000000,
+		"container_web_1_mem_available":  452984832,
+		"container_web_1_mem_used":       83886080,
+		"container_web_1_net_rx_bytes":   3000,
+		"container_web_1_net_rx_packets": 30,
+		"container_web_1_net_tx_bytes":   4000,
+		"container_web_1_net_tx_packets": 40,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+	assert.Len(t, *job.Charts(), len(containersCharts)+2*len(containerCharts))
+
+	chart := job.Charts().Get("container_web_1_cpu_usage")
+	require.NotNil(t, chart)
+	assert.Equal(t, "web.1", chart.Fam)
+
+	// 'web.1' is stopped
+	containers = []byte(`[{"Id": "1111", "Names": ["/redis"], "State": "running"}, {"Id": "2222", "Names": ["/web.1"], "State": "exited"}]`)
+
+	mx := job.Collect()
+	assert.Equal(t, int64(1), mx["containers_state_exited"])
+	assert.NotContains(t, mx, "container_web_1_cpu_user")
+	assert.True(t, chart.Obsolete)
+	assert.False(t, job.Charts().Get("container_redis_cpu_usage").Obsolete)
+}
+
+func TestDocker_Collect_MaxContainers(t *testing.T) {
+	ts := newTestServer(t, &testContainers)
+	defer ts.Close()
+
+	job := New()
+	job.Address = ts.URL
+	job.ContainersFilter = "!test-* *"
+	job.MaxContainers = 1
+	require.True(t, job.Init())
+	client := &statsCounter{dockerClient: job.client, requested: make(map[string]int)}
+	job.client = client
+
+	mx := job.Collect()
+	assert.Len(t, *job.Charts(), len(containersCharts)+len(containerCharts))
+	assert.Contains(t, mx, "container_redis_cpu_user")
+	assert.NotContains(t, mx, "container_web_1_cpu_user")
+	assert.Equal(t, map[string]int{"1111": 1}, client.requested)
+}
+
+type statsCounter struct {
+	dockerClient
+	mu        sync.Mutex
+	requested map[string]int
+}
+
+func (c *statsCounter) ContainerStats(id string) (*dockerapi.ContainerStats, error) {
+	c.mu.Lock()
+	c.requested[id]++
+	c.mu.Unlock()
+	return c.dockerClient.ContainerStats(id)
+}
+
+func TestDocker_Collect_StatsError(t *testing.T) {
+	containers := []byte(`[{"Id": "5555", "Names": ["/gone"], "State": "running"}]`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/containers/json" {
+			_, _ = w.Write(containers)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.Address = ts.URL
+	require.True(t, job.Init())
+
+	assert.Equal(t, int64(1), job.Collect()["containers_state_running"])
+	assert.Len(t, *job.Charts(), len(containersCharts))
+}
@@ -7,6 +7,8 @@ type (
 	Charts = module.Charts
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
 )
 
 var charts = Charts{
@@ -59,7 +61,187 @@ var charts = Charts{
 		Units: "events/s",
 		Fam:   "health checks",
 		Dims: Dims{
+			{ID: "health_checks_total", Name: "total", Algo: module.Incremental},
 			{ID: "health_checks_failed", Name: "fails", Algo: module.Incremental},
 		},
 	},
+	{
+		ID:    "builder_builds_triggered_total",
+		Title: "Builder Builds Triggered",
+		Units: "builds/s",
+		Fam:   "builder",
+		Dims: Dims{
+			{ID: "builder_triggered", Name: "triggered", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "engine_daemon_network_actions",
+		Title: "Network Actions",
+		Units: "actions/s",
+		Fam:   "network",
+		Type:  module.Stacked,
+	},
+	{
+		ID:    "engine_daemon_events_total",
+		Title: "Events",
+		Units: "events/s",
+		Fam:   "events",
+		Dims: Dims{
+			{ID: "events_total", Name: "events", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "engine_daemon_events_subscribers_total",
+		Title: "Events Subscribers",
+		Units: "subscribers",
+		Fam:   "events",
+		Dims: Dims{
+			{ID: "events_subscribers", Name: "subscribers"},
+		},
+	},
+	{
+		ID:    "logger_log_operations_failed",
+		Title: "Logger Failures",
+		Units: "failures/s",
+		Fam:   "logger",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "logger_read_failed", Name: "read", Algo: module.Incremental},
+			{ID: "logger_write_failed", Name: "write", Algo: module.Incremental},
+			{ID: "logger_entries_size_greater_than_buffer", Name: "entry_size_greater_than_buffer", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "go_goroutines",
+		Title: "Goroutines",
+		Units: "goroutines",
+		Fam:   "runtime",
+		Dims: Dims{
+			{ID: "go_goroutines", Name: "goroutines"},
+		},
+	},
+	{
+		ID:    "go_memstats",
+		Title: "Go Runtime Memory",
+		Units: "MiB",
+		Fam:   "runtime",
+		Dims: Dims{
+			{ID: "go_heap_alloc", Name: "heap_alloc", Div: 1 << 20},
+			{ID: "go_heap_inuse", Name: "heap_inuse", Div: 1 << 20},
+			{ID: "go_stack_inuse", Name: "stack_inuse", Div: 1 << 20},
+			{ID: "go_sys", Name: "sys", Div: 1 << 20},
+		},
+	},
+	{
+		ID:    "go_gc_runs",
+		Title: "Go GC Runs",
+		Units: "runs/s",
+		Fam:   "runtime",
+		Dims: Dims{
+			{ID: "go_gc_runs", Name: "runs", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "process_fds",
+		Title: "Open File Descriptors",
+		Units: "fds",
+		Fam:   "runtime",
+		Dims: Dims{
+			{ID: "process_open_fds", Name: "open"},
+			{ID: "process_max_fds", Name: "max"},
+		},
+	},
+}
+
+// swarmCharts are added only if the daemon is a swarm manager.
+var swarmCharts = Charts{
+	{
+		ID:    "swarm_manager_nodes",
+		Title: "Swarm Nodes In Various States",
+		Units: "nodes",
+		Fam:   "swarm",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "swarm_nodes_ready", Name: "ready"},
+			{ID: "swarm_nodes_down", Name: "down"},
+			{ID: "swarm_nodes_unknown", Name: "unknown"},
+			{ID: "swarm_nodes_disconnected", Name: "disconnected"},
+		},
+	},
+	{
+		ID:    "swarm_manager_tasks",
+		Title: "Swarm Tasks In Various States",
+		Units: "tasks",
+		Fam:   "swarm",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "swarm_tasks_running", Name: "running"},
+			{ID: "swarm_tasks_new", Name: "new"},
+			{ID: "swarm_tasks_pending", Name: "pending"},
+			{ID: "swarm_tasks_assigned", Name: "assigned"},
+			{ID: "swarm_tasks_accepted", Name: "accepted"},
+			{ID: "swarm_tasks_preparing", Name: "preparing"},
+			{ID: "swarm_tasks_ready", Name: "ready"},
+			{ID: "swarm_tasks_starting", Name: "starting"},
+			{ID: "swarm_tasks_complete", Name: "complete"},
+			{ID: "swarm_tasks_shutdown", Name: "shutdown"},
+			{ID: "swarm_tasks_failed", Name: "failed"},
+			{ID: "swarm_tasks_rejected", Name: "rejected"},
+			{ID: "swarm_tasks_remove", Name: "remove"},
+			{ID: "swarm_tasks_orphaned", Name: "orphaned"},
+		},
+	},
+	{
+		ID:    "swarm_manager_objects",
+		Title: "Swarm Objects",
+		Units: "objects",
+		Fam:   "swarm",
+		Dims: Dims{
+			{ID: "swarm_objects_services", Name: "services"},
+			{ID: "swarm_objects_networks", Name: "networks"},
+			{ID: "swarm_objects_secrets", Name: "secrets"},
+			{ID: "swarm_objects_configs", Name: "configs"},
+		},
+	},
+	{
+		ID:    "swarm_manager_leader",
+		Title: "Swarm Manager Leader",
+		Units: "bool",
+		Fam:   "swarm",
+		Dims: Dims{
+			{ID: "swarm_leader", Name: "leader"},
+		},
+	},
+	{
+		ID:    "swarm_raft_operations",
+		Title: "Swarm Raft Operations",
+		Units: "operations/s",
+		Fam:   "swarm raft",
+		Dims: Dims{
+			{ID: "swarm_raft_transactions", Name: "transactions", Algo: module.Incremental},
+			{ID: "swarm_raft_snapshots", Name: "snapshots", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "swarm_store_operations",
+		Title: "Swarm Store Operations",
+		Units: "operations/s",
+		Fam:   "swarm raft",
+		Dims: Dims{
+			{ID: "swarm_store_read_tx", Name: "read_tx", Algo: module.Incremental},
+			{ID: "swarm_store_write_tx", Name: "write_tx", Algo: module.Incremental},
+			{ID: "swarm_store_batch", Name: "batch", Algo: module.Incremental},
+			{ID: "swarm_store_lookup", Name: "lookup", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "swarm_etcd_operations",
+		Title: "Swarm Raft Storage Operations",
+		Units: "operations/s",
+		Fam:   "swarm raft",
+		Dims: Dims{
+			{ID: "swarm_etcd_wal_fsync", Name: "wal_fsync", Algo: module.Incremental},
+			{ID: "swarm_etcd_snap_save", Name: "snapshot_save", Algo: module.Incremental},
+		},
+	},
 }
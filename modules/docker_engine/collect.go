@@ -1,8 +1,11 @@
 package docker_engine
 
 import (
+	mtx "github.com/netdata/go.d.plugin/pkg/metrics"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
 	"github.com/netdata/go.d.plugin/pkg/stm"
+
+	"github.com/netdata/go-orchestrator/module"
 )
 
 func (de *DockerEngine) collect() (map[string]int64, error) {
@@ -12,19 +15,26 @@ func (de *DockerEngine) collect() (map[string]int64, error) {
 		return nil, err
 	}
 
-	var mx metrics
+	mx := metrics{NetworkActions: make(map[string]mtx.Gauge)}
 
 	collectHealthChecks(raw, &mx)
 	collectContainerActions(raw, &mx)
 	collectContainerStates(raw, &mx)
 	collectBuilderBuildsFails(raw, &mx)
+	collectBuilderBuildsTriggered(raw, &mx)
+	de.collectNetworkActions(raw, &mx)
+	collectEvents(raw, &mx)
+	collectLogger(raw, &mx)
+	collectGoRuntime(raw, &mx)
+	de.collectSwarm(raw, &mx)
 
 	return stm.ToMap(mx), nil
-
 }
 
 func collectHealthChecks(raw prometheus.Metrics, mx *metrics) {
-	m := raw.FindByName("engine_daemon_health_checks_failed_total")
+	m := raw.FindByName("engine_daemon_health_checks_total")
+	mx.HealthChecks.Total.Set(m.Max())
+	m = raw.FindByName("engine_daemon_health_checks_failed_total")
 	mx.HealthChecks.Failed.Set(m.Max())
 }
 
@@ -83,3 +93,119 @@ func collectBuilderBuildsFails(raw prometheus.Metrics, ms *metrics) {
 		}
 	}
 }
+
+func collectBuilderBuildsTriggered(raw prometheus.Metrics, mx *metrics) {
+	m := raw.FindByName("builder_builds_triggered_total")
+	mx.Builder.Triggered.Set(m.Max())
+}
+
+// collectNetworkActions collects the network actions, the set of actions depends on the daemon version.
+func (de *DockerEngine) collectNetworkActions(raw prometheus.Metrics, mx *metrics) {
+	chart := de.charts.Get("engine_daemon_network_actions")
+
+	for _, metric := range raw.FindByName("engine_daemon_network_actions_seconds_count") {
+		action := metric.Labels.Get("action")
+		if action == "" {
+			continue
+		}
+		dimID := "network_actions_" + action
+		if !chart.HasDim(dimID) {
+			_ = chart.AddDim(&Dim{ID: dimID, Name: action, Algo: module.Incremental})
+			chart.MarkNotCreated()
+		}
+		mx.NetworkActions[action] = mtx.Gauge(metric.Value)
+	}
+}
+
+func collectEvents(raw prometheus.Metrics, mx *metrics) {
+	m := raw.FindByName("engine_daemon_events_total")
+	mx.Events.Total.Set(m.Max())
+	m = raw.FindByName("engine_daemon_events_subscribers_total")
+	mx.Events.Subscribers.Set(m.Max())
+}
+
+func collectLogger(raw prometheus.Metrics, mx *metrics) {
+	m := raw.FindByName("logger_log_entries_size_greater_than_buffer_total")
+	mx.Logger.EntriesSizeGreaterThanBuffer.Set(m.Max())
+	m = raw.FindByName("logger_log_read_operations_failed_total")
+	mx.Logger.ReadFailed.Set(m.Max())
+	m = raw.FindByName("logger_log_write_operations_failed_total")
+	mx.Logger.WriteFailed.Set(m.Max())
+}
+
+func collectGoRuntime(raw prometheus.Metrics, mx *metrics) {
+	mx.Go.Goroutines.Set(raw.FindByName("go_goroutines").Max())
+	mx.Go.GCRuns.Set(raw.FindByName("go_gc_duration_seconds_count").Max())
+	mx.Go.HeapAlloc.Set(raw.FindByName("go_memstats_heap_alloc_bytes").Max())
+	mx.Go.HeapInuse.Set(raw.FindByName("go_memstats_heap_inuse_bytes").Max())
+	mx.Go.StackInuse.Set(raw.FindByName("go_memstats_stack_inuse_bytes").Max())
+	mx.Go.Sys.Set(raw.FindByName("go_memstats_sys_bytes").Max())
+	mx.Process.OpenFDs.Set(raw.FindByName("process_open_fds").Max())
+	mx.Process.MaxFDs.Set(raw.FindByName("process_max_fds").Max())
+}
+
+// collectSwarm collects the swarm metrics if the daemon is a swarm manager, the swarm charts are added
+// when the daemon becomes a manager and removed when it stops being one (leaves the swarm or is demoted).
+func (de *DockerEngine) collectSwarm(raw prometheus.Metrics, mx *metrics) {
+	isManager := raw.FindByName("swarm_node_manager").Max() == 1
+
+	if isManager != de.isSwarmManager {
+		de.isSwarmManager = isManager
+		if isManager {
+			_ = de.charts.Add(*swarmCharts.Copy()...)
+		} else {
+			de.removeSwarmCharts()
+		}
+	}
+
+	if !isManager {
+		return
+	}
+
+	swarm := &swarmMetrics{
+		Nodes: make(map[string]mtx.Gauge),
+		Tasks: make(map[string]mtx.Gauge),
+	}
+
+	for _, metric := range raw.FindByName("swarm_manager_nodes") {
+		if state := metric.Labels.Get("state"); state != "" {
+			swarm.Nodes[state] = mtx.Gauge(metric.Value)
+		}
+	}
+	for _, metric := range raw.FindByName("swarm_manager_tasks_total") {
+		if state := metric.Labels.Get("state"); state != "" {
+			swarm.Tasks[state] = mtx.Gauge(metric.Value)
+		}
+	}
+
+	swarm.Leader.Set(raw.FindByName("swarm_manager_leader").Max())
+	swarm.Objects.Configs.Set(raw.FindByName("swarm_manager_configs_total").Max())
+	swarm.Objects.Networks.Set(raw.FindByName("swarm_manager_networks_total").Max())
+	swarm.Objects.Secrets.Set(raw.FindByName("swarm_manager_secrets_total").Max())
+	swarm.Objects.Services.Set(raw.FindByName("swarm_manager_services_total").Max())
+
+	swarm.Raft.Transactions.Set(raw.FindByName("swarm_raft_transaction_latency_seconds_count").Max())
+	swarm.Raft.Snapshots.Set(raw.FindByName("swarm_raft_snapshot_latency_seconds_count").Max())
+
+	swarm.Store.ReadTx.Set(raw.FindByName("swarm_store_read_tx_latency_seconds_count").Max())
+	swarm.Store.WriteTx.Set(raw.FindByName("swarm_store_write_tx_latency_seconds_count").Max())
+	swarm.Store.Batch.Set(raw.FindByName("swarm_store_batch_latency_seconds_count").Max())
+	swarm.Store.Lookup.Set(raw.FindByName("swarm_store_lookup_latency_seconds_count").Max())
+
+	swarm.Etcd.WALFsync.Set(raw.FindByName("etcd_disk_wal_fsync_duration_seconds_count").Max())
+	swarm.Etcd.SnapSave.Set(raw.FindByName("etcd_debugging_snap_save_total_duration_seconds_count").Max())
+
+	mx.Swarm = swarm
+}
+
+func (de *DockerEngine) removeSwarmCharts() {
+	for _, chart := range swarmCharts {
+		chart = de.charts.Get(chart.ID)
+		if chart == nil {
+			continue
+		}
+		chart.Obsolete = true
+		chart.MarkNotCreated()
+		chart.MarkRemove()
+	}
+}
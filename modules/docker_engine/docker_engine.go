@@ -32,6 +32,7 @@ func New() *DockerEngine {
 	}
 	return &DockerEngine{
 		Config: config,
+		charts: charts.Copy(),
 	}
 }
 
@@ -44,7 +45,10 @@ type Config struct {
 type DockerEngine struct {
 	module.Base
 	Config `yaml:",inline"`
-	prom   prometheus.Prometheus
+
+	prom           prometheus.Prometheus
+	charts         *Charts
+	isSwarmManager bool
 }
 
 // Cleanup makes cleanup.
@@ -69,13 +73,13 @@ func (de *DockerEngine) Init() bool {
 }
 
 // Check makes check.
-func (de DockerEngine) Check() bool {
+func (de *DockerEngine) Check() bool {
 	return len(de.Collect()) > 0
 }
 
 // Charts creates Charts.
-func (DockerEngine) Charts() *Charts {
-	return charts.Copy()
+func (de DockerEngine) Charts() *Charts {
+	return de.charts
 }
 
 // Collect collects metrics.
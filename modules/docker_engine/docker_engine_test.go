@@ -10,7 +10,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var testMetrics, _ = ioutil.ReadFile("testdata/metrics.txt")
+var (
+	testMetrics, _             = ioutil.ReadFile("testdata/metrics.txt")
+	testMetricsSwarmManager, _ = ioutil.ReadFile("testdata/metrics-swarm-manager.txt")
+)
 
 func TestNew(t *testing.T) {
 	job := New()
@@ -84,9 +87,86 @@ func TestDockerEngine_Collect(t *testing.T) {
 		"builder_fails_missing_onbuild_arguments_error":  7,
 		"builder_fails_unknown_instruction_error":        8,
 		"health_checks_failed":                           33,
+		"health_checks_total":                            0,
+		"builder_triggered":                              0,
+		"events_total":                                   0,
+		"events_subscribers":                             0,
+		"logger_entries_size_greater_than_buffer":        0,
+		"logger_read_failed":                             0,
+		"logger_write_failed":                            0,
+		"go_goroutines":                                  50,
+		"go_gc_runs":                                     12,
+		"go_heap_alloc":                                  8133680,
+		"go_heap_inuse":                                  10477568,
+		"go_stack_inuse":                                 983040,
+		"go_sys":                                         72286456,
+		"process_open_fds":                               24,
+		"process_max_fds":                                1048576,
 	}
 
 	assert.Equal(t, expected, job.Collect())
+	assert.Len(t, *job.Charts(), len(charts))
+}
+
+func TestDockerEngine_Collect_SwarmManager(t *testing.T) {
+	data := testMetricsSwarmManager
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(data)
+			}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	require.True(t, job.Init())
+
+	mx := job.Collect()
+	require.NotNil(t, mx)
+
+	expected := map[string]int64{
+		"network_actions_allocate": 5,
+		"network_actions_connect":  7,
+		"network_actions_create":   3,
+		"swarm_leader":             1,
+		"swarm_nodes_ready":        3,
+		"swarm_nodes_down":         1,
+		"swarm_nodes_unknown":      0,
+		"swarm_nodes_disconnected": 0,
+		"swarm_tasks_running":      10,
+		"swarm_tasks_failed":       2,
+		"swarm_tasks_pending":      1,
+		"swarm_objects_configs":    1,
+		"swarm_objects_networks":   3,
+		"swarm_objects_secrets":    2,
+		"swarm_objects_services":   4,
+		"swarm_raft_transactions":  100,
+		"swarm_raft_snapshots":     2,
+		"swarm_store_read_tx":      300,
+		"swarm_store_write_tx":     50,
+		"swarm_store_batch":        5,
+		"swarm_store_lookup":       400,
+		"swarm_etcd_wal_fsync":     120,
+		"swarm_etcd_snap_save":     2,
+	}
+	for key, value := range expected {
+		assert.Equalf(t, value, mx[key], "metric '%s'", key)
+	}
+
+	assert.Len(t, *job.Charts(), len(charts)+len(swarmCharts))
+	chart := job.Charts().Get("engine_daemon_network_actions")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 3)
+
+	// the node is demoted to worker
+	data = testMetrics
+
+	mx = job.Collect()
+	require.NotNil(t, mx)
+	assert.NotContains(t, mx, "swarm_leader")
+	for _, chart := range swarmCharts {
+		assert.True(t, job.Charts().Get(chart.ID).Obsolete)
+	}
 }
 
 func TestDockerEngine_InvalidData(t *testing.T) {
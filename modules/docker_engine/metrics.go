@@ -30,8 +30,60 @@ type metrics struct {
 			MissingOnbuildArgumentsError mtx.Gauge `stm:"missing_onbuild_arguments_error"`
 			UnknownInstructionError      mtx.Gauge `stm:"unknown_instruction_error"`
 		} `stm:"fails"`
+		Triggered mtx.Gauge `stm:"triggered"`
 	} `stm:"builder"`
 	HealthChecks struct {
+		Total  mtx.Gauge `stm:"total"`
 		Failed mtx.Gauge `stm:"failed"`
 	} `stm:"health_checks"`
+	NetworkActions map[string]mtx.Gauge `stm:"network_actions"`
+	Events         struct {
+		Total       mtx.Gauge `stm:"total"`
+		Subscribers mtx.Gauge `stm:"subscribers"`
+	} `stm:"events"`
+	Logger struct {
+		EntriesSizeGreaterThanBuffer mtx.Gauge `stm:"entries_size_greater_than_buffer"`
+		ReadFailed                   mtx.Gauge `stm:"read_failed"`
+		WriteFailed                  mtx.Gauge `stm:"write_failed"`
+	} `stm:"logger"`
+	Go struct {
+		Goroutines mtx.Gauge `stm:"goroutines"`
+		GCRuns     mtx.Gauge `stm:"gc_runs"`
+		HeapAlloc  mtx.Gauge `stm:"heap_alloc"`
+		HeapInuse  mtx.Gauge `stm:"heap_inuse"`
+		StackInuse mtx.Gauge `stm:"stack_inuse"`
+		Sys        mtx.Gauge `stm:"sys"`
+	} `stm:"go"`
+	Process struct {
+		OpenFDs mtx.Gauge `stm:"open_fds"`
+		MaxFDs  mtx.Gauge `stm:"max_fds"`
+	} `stm:"process"`
+	// Swarm is collected only if the daemon is a swarm manager
+	Swarm *swarmMetrics `stm:"swarm"`
+}
+
+type swarmMetrics struct {
+	Nodes   map[string]mtx.Gauge `stm:"nodes"`
+	Tasks   map[string]mtx.Gauge `stm:"tasks"`
+	Leader  mtx.Gauge            `stm:"leader"`
+	Objects struct {
+		Configs  mtx.Gauge `stm:"configs"`
+		Networks mtx.Gauge `stm:"networks"`
+		Secrets  mtx.Gauge `stm:"secrets"`
+		Services mtx.Gauge `stm:"services"`
+	} `stm:"objects"`
+	Raft struct {
+		Transactions mtx.Gauge `stm:"transactions"`
+		Snapshots    mtx.Gauge `stm:"snapshots"`
+	} `stm:"raft"`
+	Store struct {
+		ReadTx  mtx.Gauge `stm:"read_tx"`
+		WriteTx mtx.Gauge `stm:"write_tx"`
+		Batch   mtx.Gauge `stm:"batch"`
+		Lookup  mtx.Gauge `stm:"lookup"`
+	} `stm:"store"`
+	Etcd struct {
+		WALFsync mtx.Gauge `stm:"wal_fsync"`
+		SnapSave mtx.Gauge `stm:"snap_save"`
+	} `stm:"etcd"`
 }
@@ -0,0 +1,201 @@
+package jolokia
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	jmx "github.com/netdata/go.d.plugin/pkg/jolokia"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+type (
+	// Charts is an alias for module.Charts
+	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
+)
+
+// the values are multiplied by precision to not lose the fractional part, the dimensions divisor is the precision
+const precision = 1000
+
+var (
+	placeholderRe = regexp.MustCompile(`{[^{}]+}`)
+	idReplacer    = strings.NewReplacer(" ", "_", ".", "_", ",", "_", "=", "_", ":", "_", "\"", "")
+)
+
+// chartRead is a chart and the Jolokia read request of its dimensions.
+type chartRead struct {
+	chart      *Chart
+	request    jmx.ReadRequest
+	attributes matcher.Matcher
+	dimension  string
+	multiplier float64
+	divisor    float64
+	// dim is the dimension template, the chart dimensions are added on the first read
+	dim Dim
+}
+
+func newChartRead(cfg ChartConfig) (*chartRead, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("id is not set")
+	}
+	if cfg.MBean == "" {
+		return nil, errors.New("mbean is not set")
+	}
+	if _, _, err := jmx.ParseObjectName(cfg.MBean); err != nil {
+		return nil, err
+	}
+
+	chart := &Chart{
+		ID:    cfg.ID,
+		Title: cfg.Title,
+		Units: cfg.Units,
+		Fam:   cfg.Family,
+		Ctx:   "jolokia." + cfg.ID,
+	}
+	if chart.Title == "" {
+		chart.Title = cfg.ID
+	}
+	if chart.Units == "" {
+		chart.Units = "value"
+	}
+	switch cfg.Type {
+	case "", "line":
+		chart.Type = module.Line
+	case "area":
+		chart.Type = module.Area
+	case "stacked":
+		chart.Type = module.Stacked
+	default:
+		return nil, fmt.Errorf("unknown chart type '%s'", cfg.Type)
+	}
+
+	dim := Dim{Div: precision}
+	switch cfg.Algorithm {
+	case "", "absolute":
+		dim.Algo = module.Absolute
+	case "incremental":
+		dim.Algo = module.Incremental
+	case "percentage-of-absolute-row":
+		dim.Algo = module.PercentOfAbsolute
+	case "percentage-of-incremental-row":
+		dim.Algo = module.PercentOfIncremental
+	default:
+		return nil, fmt.Errorf("unknown algorithm '%s'", cfg.Algorithm)
+	}
+
+	attributes, err := newAttributesMatcher(cfg.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	dimension := cfg.Dimension
+	if dimension == "" {
+		dimension = defaultDimension(cfg.MBean)
+	}
+
+	return &chartRead{
+		chart:      chart,
+		request:    jmx.NewReadRequest(cfg.MBean, requestAttributes(cfg.Attributes)...),
+		attributes: attributes,
+		dimension:  dimension,
+		multiplier: float64(nonZero(cfg.Multiplier)),
+		divisor:    float64(nonZero(cfg.Divisor)),
+		dim:        dim,
+	}, nil
+}
+
+// dimName returns the dimension name of the MBean attribute.
+func (r chartRead) dimName(props map[string]string, attribute string) string {
+	return placeholderRe.ReplaceAllStringFunc(r.dimension, func(s string) string {
+		key := s[1 : len(s)-1]
+		if key == "attribute" {
+			return attribute
+		}
+		return props[key]
+	})
+}
+
+// addDim adds the dimension if the chart doesn't have it yet, it returns the dimension id.
+func (r *chartRead) addDim(name string) string {
+	id := r.chart.ID + "_" + idReplacer.Replace(name)
+	if r.chart.HasDim(id) {
+		return id
+	}
+
+	dim := r.dim
+	dim.ID, dim.Name = id, name
+	if err := r.chart.AddDim(&dim); err == nil {
+		r.chart.MarkNotCreated()
+	}
+	return id
+}
+
+func newAttributesMatcher(patterns []string) (matcher.Matcher, error) {
+	if len(patterns) == 0 {
+		return matcher.TRUE(), nil
+	}
+
+	var ms []matcher.Matcher
+	for _, p := range patterns {
+		m, err := matcher.NewGlobMatcher(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attribute pattern '%s' : %v", p, err)
+		}
+		ms = append(ms, m)
+	}
+
+	if len(ms) == 1 {
+		return ms[0], nil
+	}
+	return matcher.Or(ms[0], ms[1], ms[2:]...), nil
+}
+
+// requestAttributes returns the attributes to read, there is no attributes list if any attribute is a pattern
+// (Jolokia reads all the attributes), the composite values are read as a whole.
+func requestAttributes(patterns []string) []string {
+	set := make(map[string]bool)
+	for _, p := range patterns {
+		if jmx.IsPattern(p) {
+			return nil
+		}
+		set[strings.SplitN(p, ".", 2)[0]] = true
+	}
+
+	var attrs []string
+	for attr := range set {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+// defaultDimension returns the dimension template: the values of the pattern keys and the attribute name.
+// The keys matched by the property list wildcard ("type=GarbageCollector,*") are unknown, they are not in the template.
+func defaultDimension(mbean string) string {
+	_, props, _ := jmx.ParseObjectName(mbean)
+
+	var keys []string
+	for key, value := range props {
+		if jmx.IsPattern(value) {
+			keys = append(keys, "{"+key+"}")
+		}
+	}
+	sort.Strings(keys)
+
+	return strings.Join(append(keys, "{attribute}"), "_")
+}
+
+func nonZero(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package jolokia
+
+import (
+	"sort"
+
+	jmx "github.com/netdata/go.d.plugin/pkg/jolokia"
+)
+
+func (j *Jolokia) collect() (map[string]int64, error) {
+	reqs := make([]jmx.ReadRequest, 0, len(j.reads))
+	for _, read := range j.reads {
+		reqs = append(reqs, read.request)
+	}
+
+	resps, err := j.client.Read(reqs...)
+	if err != nil {
+		return nil, err
+	}
+
+	mx := make(map[string]int64)
+
+	for i, resp := range resps {
+		read := j.reads[i]
+
+		mbeans, err := resp.MBeans()
+		if err != nil {
+			j.Debugf("chart '%s' : %v", read.chart.ID, err)
+			continue
+		}
+
+		j.collectMBeans(read, mbeans, mx)
+	}
+
+	return mx, nil
+}
+
+func (j *Jolokia) collectMBeans(read *chartRead, mbeans map[string]jmx.Attributes, mx map[string]int64) {
+	names := make([]string, 0, len(mbeans))
+	for name := range mbeans {
+		names = append(names, name)
+	}
+	// the dimensions are added in the same order every time
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, props, err := jmx.ParseObjectName(name)
+		if err != nil {
+			j.Debug(err)
+			continue
+		}
+
+		values := make(map[string]float64)
+		flatten("", map[string]interface{}(mbeans[name]), values)
+
+		attrs := make([]string, 0, len(values))
+		for attr := range values {
+			if read.attributes.MatchString(attr) {
+				attrs = append(attrs, attr)
+			}
+		}
+		sort.Strings(attrs)
+
+		for _, attr := range attrs {
+			id := read.addDim(read.dimName(props, attr))
+			mx[id] += int64(values[attr] * read.multiplier / read.divisor * precision)
+		}
+	}
+}
+
+// flatten collects the numeric and boolean values, the composite values keys are joined by a dot.
+func flatten(prefix string, attrs map[string]interface{}, values map[string]float64) {
+	for key, value := range attrs {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case float64:
+			values[key] = v
+		case bool:
+			if v {
+				values[key] = 1
+			} else {
+				values[key] = 0
+			}
+		case map[string]interface{}:
+			flatten(key, v, values)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package jolokia
+
+import (
+	"time"
+
+	jmx "github.com/netdata/go.d.plugin/pkg/jolokia"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+const (
+	defaultURL         = "http://127.0.0.1:8778/jolokia"
+	defaultHTTPTimeout = time.Second * 2
+)
+
+func init() {
+	creator := module.Creator{
+		DisabledByDefault: true,
+		Create:            func() module.Module { return New() },
+	}
+
+	module.Register("jolokia", creator)
+}
+
+// New creates Jolokia with default values.
+func New() *Jolokia {
+	config := Config{
+		HTTP: web.HTTP{
+			Request: web.Request{URL: defaultURL},
+			Client:  web.Client{Timeout: web.Duration{Duration: defaultHTTPTimeout}},
+		},
+	}
+	return &Jolokia{
+		Config: config,
+		charts: &Charts{},
+	}
+}
+
+// Config is the Jolokia module configuration.
+type Config struct {
+	web.HTTP `yaml:",inline"`
+
+	Charts []ChartConfig `yaml:"charts"`
+}
+
+// ChartConfig is a chart of the MBeans attributes.
+type ChartConfig struct {
+	ID     string `yaml:"id"`
+	Title  string `yaml:"title"`
+	Units  string `yaml:"units"`
+	Family string `yaml:"family"`
+	Type   string `yaml:"type"`
+	// MBean is the MBean name or pattern, e.g. 'java.lang:type=GarbageCollector,name=*'
+	MBean string `yaml:"mbean"`
+	// Attributes are the attributes to read, the glob patterns are allowed, the composite values keys
+	// are accessed by a dot, e.g. 'HeapMemoryUsage.used'. All the numeric attributes are read if not set.
+	Attributes []string `yaml:"attributes"`
+	Algorithm  string   `yaml:"algorithm"`
+	Multiplier int      `yaml:"multiplier"`
+	Divisor    int      `yaml:"divisor"`
+	// Dimension is the dimension name template, '{<key>}' is the MBean key property value and
+	// '{attribute}' is the attribute name, e.g. '{name} {attribute}'.
+	Dimension string `yaml:"dimension"`
+}
+
+// Jolokia Jolokia module.
+type Jolokia struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	client *jmx.Client
+	reads  []*chartRead
+	charts *Charts
+}
+
+// Cleanup makes cleanup.
+func (Jolokia) Cleanup() {}
+
+// Init makes initialization.
+func (j *Jolokia) Init() bool {
+	if j.URL == "" {
+		j.Error("URL parameter is mandatory, please set")
+		return false
+	}
+
+	if len(j.Config.Charts) == 0 {
+		j.Error("charts are not set")
+		return false
+	}
+
+	for _, cfg := range j.Config.Charts {
+		read, err := newChartRead(cfg)
+		if err != nil {
+			j.Errorf("error on creating chart '%s' : %v", cfg.ID, err)
+			return false
+		}
+		if err := j.charts.Add(read.chart); err != nil {
+			j.Error(err)
+			return false
+		}
+		j.reads = append(j.reads, read)
+	}
+
+	client, err := web.NewHTTPClient(j.Client)
+	if err != nil {
+		j.Errorf("error on creating http client : %v", err)
+		return false
+	}
+
+	j.client = jmx.New(client, j.Request)
+
+	return true
+}
+
+// Check makes check.
+func (j *Jolokia) Check() bool {
+	return len(j.Collect()) > 0
+}
+
+// Charts creates Charts.
+func (j Jolokia) Charts() *Charts {
+	return j.charts
+}
+
+// Collect collects metrics.
+func (j *Jolokia) Collect() map[string]int64 {
+	mx, err := j.collect()
+
+	if err != nil {
+		j.Error(err)
+		return nil
+	}
+
+	return mx
+}
@@ -0,0 +1,216 @@
+package jolokia
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jmx "github.com/netdata/go.d.plugin/pkg/jolokia"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCharts = []ChartConfig{
+	{
+		ID:         "gc_collections",
+		MBean:      "java.lang:type=GarbageCollector,name=*",
+		Attributes: []string{"CollectionCount"},
+		Algorithm:  "incremental",
+		Dimension:  "{name}",
+	},
+	{
+		ID:         "heap",
+		MBean:      "java.lang:type=Memory",
+		Attributes: []string{"HeapMemoryUsage.used", "HeapMemoryUsage.committed"},
+		Type:       "area",
+	},
+	{
+		ID:         "cpu",
+		MBean:      "java.lang:type=OperatingSystem",
+		Attributes: []string{"*CpuLoad"},
+		Multiplier: 100,
+	},
+	{
+		ID:    "unknown",
+		MBean: "org.apache.kafka:type=Unknown",
+	},
+}
+
+func TestNew(t *testing.T) {
+	job := New()
+
+	assert.Implements(t, (*module.Module)(nil), job)
+	assert.Equal(t, defaultURL, job.URL)
+	assert.Equal(t, defaultHTTPTimeout, job.Timeout.Duration)
+	assert.NotNil(t, job.Charts())
+}
+
+func TestJolokia_Init(t *testing.T) {
+	job := New()
+	job.Config.Charts = testCharts
+
+	require.True(t, job.Init())
+	assert.NotNil(t, job.client)
+	assert.Len(t, job.reads, len(testCharts))
+	assert.Len(t, *job.Charts(), len(testCharts))
+}
+
+func TestJolokia_Init_NG(t *testing.T) {
+	tests := map[string][]ChartConfig{
+		"no charts":          nil,
+		"no id":              {{MBean: "java.lang:type=Memory"}},
+		"no mbean":           {{ID: "heap"}},
+		"invalid mbean":      {{ID: "heap", MBean: "java.lang"}},
+		"unknown type":       {{ID: "heap", MBean: "java.lang:type=Memory", Type: "bar"}},
+		"unknown algorithm":  {{ID: "heap", MBean: "java.lang:type=Memory", Algorithm: "delta"}},
+		"invalid attributes": {{ID: "heap", MBean: "java.lang:type=Memory", Attributes: []string{"[Heap"}}},
+		"duplicate id": {
+			{ID: "heap", MBean: "java.lang:type=Memory"},
+			{ID: "heap", MBean: "java.lang:type=Threading"},
+		},
+	}
+
+	for name, charts := range tests {
+		t.Run(name, func(t *testing.T) {
+			job := New()
+			job.Config.Charts = charts
+
+			assert.False(t, job.Init())
+		})
+	}
+}
+
+func TestJolokia_Check(t *testing.T) {
+	ts := newJolokiaServer(t)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/jolokia"
+	job.Config.Charts = testCharts
+
+	require.True(t, job.Init())
+	assert.True(t, job.Check())
+}
+
+func TestJolokia_CheckNG(t *testing.T) {
+	job := New()
+	job.URL = "http://127.0.0.1:38001/jolokia"
+	job.Config.Charts = testCharts
+
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
+}
+
+func TestJolokia_Collect(t *testing.T) {
+	ts := newJolokiaServer(t)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/jolokia"
+	job.Config.Charts = testCharts
+
+	require.True(t, job.Init())
+
+	expected := map[string]int64{
+		"gc_collections_G1_Old_Generation":   1000,
+		"gc_collections_G1_Young_Generation": 15000,
+		"heap_HeapMemoryUsage_committed":     268435456000,
+		"heap_HeapMemoryUsage_used":          53687091000,
+		"cpu_ProcessCpuLoad":                 3750,
+		"cpu_SystemCpuLoad":                  12500,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+
+	gc := job.Charts().Get("gc_collections")
+	require.NotNil(t, gc)
+	require.Len(t, gc.Dims, 2)
+	assert.Equal(t, "G1 Old Generation", gc.Dims[0].Name)
+	assert.Equal(t, module.Incremental, gc.Dims[0].Algo)
+	assert.Equal(t, "G1 Young Generation", gc.Dims[1].Name)
+
+	heap := job.Charts().Get("heap")
+	require.NotNil(t, heap)
+	assert.Equal(t, module.Area, heap.Type)
+	assert.Len(t, heap.Dims, 2)
+
+	assert.Len(t, job.Charts().Get("unknown").Dims, 0)
+
+	// the dimensions are added once
+	assert.Equal(t, expected, job.Collect())
+	assert.Len(t, gc.Dims, 2)
+}
+
+func TestJolokia_Collect_ReadRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []jmx.ReadRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+
+		assert.Equal(t, []jmx.ReadRequest{
+			jmx.NewReadRequest("java.lang:type=GarbageCollector,name=*", "CollectionCount"),
+			jmx.NewReadRequest("java.lang:type=Memory", "HeapMemoryUsage"),
+			{Type: "read", MBean: "java.lang:type=OperatingSystem"},
+			{Type: "read", MBean: "org.apache.kafka:type=Unknown"},
+		}, reqs)
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	job.Config.Charts = testCharts
+
+	require.True(t, job.Init())
+	assert.Nil(t, job.Collect())
+}
+
+func TestChartRead_dimName(t *testing.T) {
+	read, err := newChartRead(ChartConfig{
+		ID:    "kafka",
+		MBean: "kafka.server:type=BrokerTopicMetrics,name=*,topic=*",
+	})
+	require.NoError(t, err)
+
+	props := map[string]string{"type": "BrokerTopicMetrics", "name": "BytesInPerSec", "topic": "orders"}
+	assert.Equal(t, "BytesInPerSec_orders_Count", read.dimName(props, "Count"))
+}
+
+func TestChartRead_PropertyListPattern(t *testing.T) {
+	read, err := newChartRead(ChartConfig{
+		ID:    "gc",
+		MBean: "java.lang:type=GarbageCollector,*",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "{attribute}", read.dimension)
+
+	read, err = newChartRead(ChartConfig{
+		ID:        "gc",
+		MBean:     "java.lang:type=GarbageCollector,*",
+		Dimension: "{name}",
+	})
+	require.NoError(t, err)
+
+	props := map[string]string{"type": "GarbageCollector", "name": "G1 Young Generation"}
+	assert.Equal(t, "G1 Young Generation", read.dimName(props, "CollectionCount"))
+}
+
+func newJolokiaServer(t *testing.T) *httptest.Server {
+	data, err := ioutil.ReadFile("testdata/response.json")
+	require.NoError(t, err)
+
+	return httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/jolokia/" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write(data)
+			}))
+}
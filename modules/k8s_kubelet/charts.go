@@ -1,6 +1,10 @@
 package k8s_kubelet
 
-import "github.com/netdata/go-orchestrator/module"
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/module"
+)
 
 type (
 	// Charts is an alias for module.Charts
@@ -224,3 +228,60 @@ func newVolumeManagerChart(name string) *Chart {
 		},
 	}
 }
+
+// podCharts are the per pod charts from the summary API, the containers dims are added dynamically.
+var podCharts = Charts{
+	{
+		ID:    "pod_%s_cpu_usage",
+		Title: "Pod CPU Usage",
+		Units: "millicores",
+		Fam:   "pod %s",
+		Ctx:   "k8s_kubelet.pod_cpu_usage",
+		Type:  module.Stacked,
+	},
+	{
+		ID:    "pod_%s_mem_working_set",
+		Title: "Pod Memory Working Set",
+		Units: "MiB",
+		Fam:   "pod %s",
+		Ctx:   "k8s_kubelet.pod_mem_working_set",
+		Type:  module.Stacked,
+	},
+	{
+		ID:    "pod_%s_net_traffic",
+		Title: "Pod Network Traffic",
+		Units: "kilobits/s",
+		Fam:   "pod %s",
+		Ctx:   "k8s_kubelet.pod_net_traffic",
+		Type:  module.Area,
+		Dims: Dims{
+			{ID: "pod_%s_net_rx", Name: "received", Algo: module.Incremental, Mul: 8, Div: 1000},
+			{ID: "pod_%s_net_tx", Name: "sent", Algo: module.Incremental, Mul: -8, Div: 1000},
+		},
+	},
+	{
+		ID:    "pod_%s_ephemeral_storage",
+		Title: "Pod Ephemeral Storage Usage",
+		Units: "MiB",
+		Fam:   "pod %s",
+		Ctx:   "k8s_kubelet.pod_ephemeral_storage",
+		Dims: Dims{
+			{ID: "pod_%s_ephemeral_storage_used", Name: "used", Div: 1 << 20},
+		},
+	},
+}
+
+func newPodCharts(id, name string) *Charts {
+	charts := podCharts.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Fam = fmt.Sprintf(chart.Fam, name)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	return charts
+}
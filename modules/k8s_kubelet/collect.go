@@ -25,7 +25,16 @@ func (k *Kubelet) collect() (map[string]int64, error) {
 	k.collectKubelet(raw, mx)
 	k.collectVolumeManager(raw, mx)
 
-	return stm.ToMap(mx), nil
+	result := stm.ToMap(mx)
+
+	// pods stats are optional, the kubelet metrics are reported even if the summary API is unavailable
+	if k.CollectPodsStats {
+		if err := k.collectSummary(result); err != nil {
+			k.Error(err)
+		}
+	}
+
+	return result, nil
 }
 
 func (k *Kubelet) collectVolumeManager(raw prometheus.Metrics, mx *metrics) {
@@ -1,8 +1,12 @@
 package k8s_kubelet
 
 import (
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/matcher"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
@@ -10,8 +14,11 @@ import (
 )
 
 const (
-	defaultURL         = "http://127.0.0.1:10255/metrics"
+	defaultURL         = "https://127.0.0.1:10250/metrics"
 	defaultHTTPTimeout = time.Second * 2
+	// the pod service account token and the cluster CA, they are used for the secure port (https) if not set
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
 )
 
 func init() {
@@ -35,12 +42,16 @@ func New() *Kubelet {
 		Config:                     config,
 		charts:                     charts.Copy(),
 		activeVolumeManagerPlugins: make(map[string]bool),
+		activePods:                 make(map[string]bool),
 	}
 }
 
-// Config is the DockerEngine module configuration.
+// Config is the Kubelet module configuration.
 type Config struct {
 	web.HTTP `yaml:",inline"`
+
+	CollectPodsStats bool   `yaml:"collect_pods_stats"`
+	NamespacesFilter string `yaml:"namespaces_filter"`
 }
 
 // Kubelet Kubelet module.
@@ -51,6 +62,12 @@ type Kubelet struct {
 	prom                       prometheus.Prometheus
 	charts                     *Charts
 	activeVolumeManagerPlugins map[string]bool
+
+	httpClient       *http.Client
+	summaryRequest   web.Request
+	namespacesFilter matcher.Matcher
+	// activePods is a set of the pods (by chart id) that have charts
+	activePods map[string]bool
 }
 
 // Cleanup makes cleanup.
@@ -63,6 +80,21 @@ func (k *Kubelet) Init() bool {
 		return false
 	}
 
+	// the secure port requires authentication, the service account token and the cluster CA
+	// are only available when the plugin runs in a pod
+	if strings.HasPrefix(k.URL, "https://") {
+		if k.BearerTokenFile == "" {
+			if fileExists(serviceAccountTokenFile) {
+				k.BearerTokenFile = serviceAccountTokenFile
+			} else {
+				k.Warningf("'%s' requires authentication, but 'bearer_token_file' is not set and there is no service account token", k.URL)
+			}
+		}
+		if k.TLSCA == "" && fileExists(serviceAccountCAFile) {
+			k.TLSCA = serviceAccountCAFile
+		}
+	}
+
 	client, err := web.NewHTTPClient(k.Client)
 	if err != nil {
 		k.Errorf("error on creating http client : %v", err)
@@ -71,6 +103,23 @@ func (k *Kubelet) Init() bool {
 
 	k.prom = prometheus.New(client, k.Request)
 
+	if k.CollectPodsStats {
+		if k.summaryRequest, err = newSummaryRequest(k.Request); err != nil {
+			k.Errorf("error on creating summary request : %v", err)
+			return false
+		}
+		k.httpClient = client
+	}
+
+	if k.NamespacesFilter != "" {
+		m, err := matcher.NewSimplePatternsMatcher(k.NamespacesFilter)
+		if err != nil {
+			k.Errorf("error on creating namespaces filter : %v", err)
+			return false
+		}
+		k.namespacesFilter = matcher.WithCache(m)
+	}
+
 	return true
 }
 
@@ -95,3 +144,8 @@ func (k *Kubelet) Collect() map[string]int64 {
 
 	return mx
 }
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,16 +1,23 @@
 package k8s_kubelet
 
 import (
+	"encoding/pem"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-var testMetrics, _ = ioutil.ReadFile("testdata/metrics.txt")
+var (
+	testMetrics, _ = ioutil.ReadFile("testdata/metrics.txt")
+	testSummary, _ = ioutil.ReadFile("testdata/summary.json")
+)
 
 func TestNew(t *testing.T) {
 	job := New()
@@ -53,6 +60,58 @@ func TestKubeProxy_CheckNG(t *testing.T) {
 	assert.False(t, job.Check())
 }
 
+func TestKubeProxy_Check_SecurePort(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write(testMetrics)
+			}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "k8s_kubelet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	tokenFile := filepath.Join(dir, "token")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(caFile, ca, 0600))
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600))
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	job.TLSCA = caFile
+	job.BearerTokenFile = tokenFile
+	require.True(t, job.Init())
+	assert.True(t, job.Check())
+
+	job = New()
+	job.URL = ts.URL + "/metrics"
+	job.TLSCA = caFile
+	job.BearerTokenFile = filepath.Join(dir, "not_exists")
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
+}
+
+func TestKubeProxy_Init_SecurePortDefaults(t *testing.T) {
+	job := New()
+	require.True(t, job.Init())
+	// there is no service account outside a pod
+	assert.Empty(t, job.BearerTokenFile)
+	assert.Empty(t, job.TLSCA)
+
+	job = New()
+	job.URL = "http://127.0.0.1:10255/metrics"
+	require.True(t, job.Init())
+	// the read-only port doesn't need the token
+	assert.Empty(t, job.BearerTokenFile)
+	assert.Empty(t, job.TLSCA)
+}
+
 func TestKubeProxy_Collect(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(
@@ -147,6 +206,118 @@ func TestKubeProxy_Collect(t *testing.T) {
 	assert.Equal(t, expected, job.Collect())
 }
 
+func newSummaryTestServer(summary *[]byte) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/metrics":
+					_, _ = w.Write(testMetrics)
+				case summaryPath:
+					_, _ = w.Write(*summary)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+}
+
+func TestKubeProxy_Collect_PodsStats(t *testing.T) {
+	summary := testSummary
+	ts := newSummaryTestServer(&summary)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	job.CollectPodsStats = true
+	require.True(t, job.Init())
+	require.True(t, job.Check())
+
+	expected := map[string]int64{
+		"pod_default_web-1_net_rx":                                                   1000,
+		"pod_default_web-1_net_tx":                                                   2000,
+		"pod_default_web-1_ephemeral_storage_used":                                   4194304,
+		"pod_default_web-1_container_nginx_cpu_usage":                                25000000,
+		"pod_default_web-1_container_nginx_mem_working_set":                          20971520,
+		"pod_default_web-1_container_log-shipper_cpu_usage":                          5000000,
+		"pod_default_web-1_container_log-shipper_mem_working_set":                    10485760,
+		"pod_kube-system_coredns-5c98db65d4-abcde_net_rx":                            3000,
+		"pod_kube-system_coredns-5c98db65d4-abcde_net_tx":                            4000,
+		"pod_kube-system_coredns-5c98db65d4-abcde_ephemeral_storage_used":            1048576,
+		"pod_kube-system_coredns-5c98db65d4-abcde_container_coredns_cpu_usage":       3000000,
+		"pod_kube-system_coredns-5c98db65d4-abcde_container_coredns_mem_working_set": 15728640,
+	}
+
+	mx := job.Collect()
+	for key, value := range expected {
+		assert.Equalf(t, value, mx[key], "key '%s'", key)
+	}
+	assert.Contains(t, mx, "kubelet_running_pod")
+	assert.Equal(t, 2*len(podCharts), countPodCharts(job))
+
+	chart := job.Charts().Get("pod_default_web-1_cpu_usage")
+	require.NotNil(t, chart)
+	assert.Equal(t, "pod default/web-1", chart.Fam)
+	assert.Len(t, chart.Dims, 2)
+
+	// 'web-1' is terminated, 'coredns' container is replaced
+	summary = []byte(`{"pods": [{"podRef": {"name": "coredns-5c98db65d4-abcde", "namespace": "kube-system"},
+  "containers": [{"name": "coredns-v2", "cpu": {"usageNanoCores": 1000000}}]}]}`)
+
+	mx = job.Collect()
+	assert.NotContains(t, mx, "pod_default_web-1_net_rx")
+	assert.True(t, chart.Obsolete)
+
+	coredns := job.Charts().Get("pod_kube-system_coredns-5c98db65d4-abcde_cpu_usage")
+	require.NotNil(t, coredns)
+	assert.False(t, coredns.Obsolete)
+	require.Len(t, coredns.Dims, 1)
+	assert.Equal(t, "coredns-v2", coredns.Dims[0].Name)
+	assert.Len(t, job.Charts().Get("pod_kube-system_coredns-5c98db65d4-abcde_mem_working_set").Dims, 1)
+}
+
+func TestKubeProxy_Collect_PodsStatsNamespacesFilter(t *testing.T) {
+	ts := newSummaryTestServer(&testSummary)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	job.CollectPodsStats = true
+	job.NamespacesFilter = "!kube-system *"
+	require.True(t, job.Init())
+
+	mx := job.Collect()
+	assert.Contains(t, mx, "pod_default_web-1_net_rx")
+	assert.NotContains(t, mx, "pod_kube-system_coredns-5c98db65d4-abcde_net_rx")
+	assert.Equal(t, len(podCharts), countPodCharts(job))
+}
+
+func TestKubeProxy_Collect_PodsStatsError(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(testMetrics)
+			}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	job.CollectPodsStats = true
+	require.True(t, job.Init())
+
+	// the kubelet metrics are collected even if the summary API fails
+	assert.Contains(t, job.Collect(), "kubelet_running_pod")
+	assert.Zero(t, countPodCharts(job))
+}
+
+func countPodCharts(k *Kubelet) (n int) {
+	for _, chart := range *k.Charts() {
+		if strings.HasPrefix(chart.ID, "pod_") {
+			n++
+		}
+	}
+	return n
+}
+
 func TestKubeProxy_InvalidData(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(
@@ -0,0 +1,220 @@
+package k8s_kubelet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+const summaryPath = "/stats/summary"
+
+// summary is the kubelet summary API response, only the used fields are decoded.
+type summary struct {
+	Pods []podStats `json:"pods"`
+}
+
+type podStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		UID       string `json:"uid"`
+	} `json:"podRef"`
+	Containers       []containerStats `json:"containers"`
+	CPU              *cpuStats        `json:"cpu"`
+	Memory           *memoryStats     `json:"memory"`
+	Network          *networkStats    `json:"network"`
+	EphemeralStorage *fsStats         `json:"ephemeral-storage"`
+}
+
+type containerStats struct {
+	Name   string       `json:"name"`
+	CPU    *cpuStats    `json:"cpu"`
+	Memory *memoryStats `json:"memory"`
+}
+
+type cpuStats struct {
+	UsageNanoCores *int64 `json:"usageNanoCores"`
+}
+
+type memoryStats struct {
+	WorkingSetBytes *int64 `json:"workingSetBytes"`
+}
+
+type networkStats struct {
+	RxBytes *int64 `json:"rxBytes"`
+	TxBytes *int64 `json:"txBytes"`
+}
+
+type fsStats struct {
+	UsedBytes *int64 `json:"usedBytes"`
+}
+
+// newSummaryRequest returns the request to the summary API on the same host as the metrics endpoint.
+func newSummaryRequest(r web.Request) (web.Request, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return r, err
+	}
+
+	u.Path = summaryPath
+	u.RawQuery = ""
+	r.URL = u.String()
+	r.URI = ""
+
+	return r, nil
+}
+
+func (k *Kubelet) fetchSummary() (*summary, error) {
+	req, err := web.NewHTTPRequest(k.summaryRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating summary request : %v", err)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to %s : %v", req.URL, err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %d", req.URL, resp.StatusCode)
+	}
+
+	var s summary
+	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("error on decoding resp from %s : %v", req.URL, err)
+	}
+
+	return &s, nil
+}
+
+func (k *Kubelet) collectSummary(mx map[string]int64) error {
+	s, err := k.fetchSummary()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+
+	for _, pod := range s.Pods {
+		if pod.PodRef.Name == "" || !k.filterNamespace(pod.PodRef.Namespace) {
+			continue
+		}
+
+		id := podID(pod.PodRef.Namespace, pod.PodRef.Name)
+		seen[id] = true
+
+		if !k.activePods[id] {
+			k.activePods[id] = true
+			_ = k.charts.Add(*newPodCharts(id, pod.PodRef.Namespace+"/"+pod.PodRef.Name)...)
+		}
+
+		k.collectPodStats(mx, id, pod)
+	}
+
+	for id := range k.activePods {
+		if !seen[id] {
+			delete(k.activePods, id)
+			k.removePodCharts(id)
+		}
+	}
+
+	return nil
+}
+
+func (k *Kubelet) collectPodStats(mx map[string]int64, id string, pod podStats) {
+	prefix := "pod_" + id
+
+	if pod.Network != nil {
+		setValue(mx, prefix+"_net_rx", pod.Network.RxBytes)
+		setValue(mx, prefix+"_net_tx", pod.Network.TxBytes)
+	}
+	if pod.EphemeralStorage != nil {
+		setValue(mx, prefix+"_ephemeral_storage_used", pod.EphemeralStorage.UsedBytes)
+	}
+
+	cpuChart := k.charts.Get(prefix + "_cpu_usage")
+	memChart := k.charts.Get(prefix + "_mem_working_set")
+	seen := make(map[string]bool)
+
+	for _, c := range pod.Containers {
+		cid := prefix + "_container_" + nameReplacer.Replace(c.Name)
+		seen[cid] = true
+
+		if !cpuChart.HasDim(cid + "_cpu_usage") {
+			_ = cpuChart.AddDim(&Dim{ID: cid + "_cpu_usage", Name: c.Name, Div: 1000000})
+			cpuChart.MarkNotCreated()
+			_ = memChart.AddDim(&Dim{ID: cid + "_mem_working_set", Name: c.Name, Div: 1 << 20})
+			memChart.MarkNotCreated()
+		}
+
+		if c.CPU != nil {
+			setValue(mx, cid+"_cpu_usage", c.CPU.UsageNanoCores)
+		}
+		if c.Memory != nil {
+			setValue(mx, cid+"_mem_working_set", c.Memory.WorkingSetBytes)
+		}
+	}
+
+	removeContainerDims(cpuChart, "_cpu_usage", seen)
+	removeContainerDims(memChart, "_mem_working_set", seen)
+}
+
+// removeContainerDims removes the dimensions of the containers that are not in the pod anymore.
+func removeContainerDims(chart *Chart, suffix string, seen map[string]bool) {
+	var stale []string
+	for _, dim := range chart.Dims {
+		if !seen[strings.TrimSuffix(dim.ID, suffix)] {
+			stale = append(stale, dim.ID)
+		}
+	}
+
+	for _, id := range stale {
+		_ = chart.RemoveDim(id)
+	}
+	if len(stale) > 0 {
+		chart.MarkNotCreated()
+	}
+}
+
+func (k *Kubelet) removePodCharts(id string) {
+	for _, chart := range podCharts {
+		chart = k.charts.Get(fmt.Sprintf(chart.ID, id))
+		if chart == nil {
+			continue
+		}
+		chart.Obsolete = true
+		chart.MarkNotCreated()
+		chart.MarkRemove()
+	}
+}
+
+func (k Kubelet) filterNamespace(namespace string) bool {
+	if k.namespacesFilter == nil {
+		return true
+	}
+	return k.namespacesFilter.MatchString(namespace)
+}
+
+// setValue sets the value if the kubelet has reported it, missing values are not reported as zeros.
+func setValue(mx map[string]int64, key string, value *int64) {
+	if value != nil {
+		mx[key] = *value
+	}
+}
+
+var nameReplacer = strings.NewReplacer(".", "_", " ", "_")
+
+func podID(namespace, name string) string {
+	return nameReplacer.Replace(namespace + "_" + name)
+}
@@ -7,6 +7,8 @@ type (
 	Charts = module.Charts
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
 	// Dim is an alias for module.Dim
 	Dim = module.Dim
 )
@@ -104,3 +106,57 @@ var charts = Charts{
 		},
 	},
 }
+
+// The charts below are added when the metrics are found, the metrics are missing in the old kube-proxy releases.
+var (
+	syncProxyRulesChangesChart = Chart{
+		ID:    "kubeproxy_sync_proxy_rules_changes",
+		Title: "Sync Proxy Rules Changes",
+		Units: "changes/s",
+		Fam:   "sync proxy rules",
+		Ctx:   "k8s_kubeproxy.kubeproxy_sync_proxy_rules_changes",
+		Dims: Dims{
+			{ID: "sync_proxy_rules_endpoint_changes_total", Name: "endpoints", Algo: module.Incremental},
+			{ID: "sync_proxy_rules_service_changes_total", Name: "services", Algo: module.Incremental},
+		},
+	}
+	syncProxyRulesPendingChangesChart = Chart{
+		ID:    "kubeproxy_sync_proxy_rules_pending_changes",
+		Title: "Sync Proxy Rules Pending Changes",
+		Units: "changes",
+		Fam:   "sync proxy rules",
+		Ctx:   "k8s_kubeproxy.kubeproxy_sync_proxy_rules_pending_changes",
+		Dims: Dims{
+			{ID: "sync_proxy_rules_endpoint_changes_pending", Name: "endpoints"},
+			{ID: "sync_proxy_rules_service_changes_pending", Name: "services"},
+		},
+	}
+	syncProxyRulesLastSyncChart = Chart{
+		ID:    "kubeproxy_sync_proxy_rules_last_sync",
+		Title: "Time Since Last Sync Proxy Rules",
+		Units: "seconds",
+		Fam:   "sync proxy rules",
+		Ctx:   "k8s_kubeproxy.kubeproxy_sync_proxy_rules_last_sync",
+		Dims: Dims{
+			{ID: "sync_proxy_rules_last_sync_ago", Name: "ago"},
+		},
+	}
+	iptablesRestoreFailuresChart = Chart{
+		ID:    "kubeproxy_sync_proxy_rules_iptables_restore_failures",
+		Title: "IPTables Restore Failures",
+		Units: "failures/s",
+		Fam:   "sync proxy rules",
+		Ctx:   "k8s_kubeproxy.kubeproxy_sync_proxy_rules_iptables_restore_failures",
+		Dims: Dims{
+			{ID: "sync_proxy_rules_iptables_restore_failures_total", Name: "failures", Algo: module.Incremental},
+		},
+	}
+	networkProgrammingLatencyChart = Chart{
+		ID:    "kubeproxy_network_programming_latency",
+		Title: "Network Programming Latency",
+		Units: "observes/s",
+		Fam:   "network programming",
+		Ctx:   "k8s_kubeproxy.kubeproxy_network_programming_latency",
+		Type:  module.Stacked,
+	}
+)
@@ -2,6 +2,10 @@ package k8s_kubeproxy
 
 import (
 	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	mtx "github.com/netdata/go.d.plugin/pkg/metrics"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
@@ -22,56 +26,81 @@ func (kp *KubeProxy) collect() (map[string]int64, error) {
 	kp.collectSyncProxyRules(raw, mx)
 	kp.collectRESTClientHTTPRequests(raw, mx)
 	kp.collectHTTPRequestDuration(raw, mx)
+	kp.collectNetworkProgrammingDuration(raw, mx)
 
 	return stm.ToMap(mx), nil
 }
 
+// syncProxyRulesLatencyNames are the sync proxy rules latency histogram names from the oldest to the newest release,
+// the buckets are in microseconds in the old releases and in seconds in the new ones.
+var syncProxyRulesLatencyNames = []string{
+	"kubeproxy_sync_proxy_rules_latency_microseconds",
+	"kubeproxy_sync_proxy_rules_latency_seconds",
+	"kubeproxy_sync_proxy_rules_duration_seconds",
+}
+
 func (kp *KubeProxy) collectSyncProxyRules(raw prometheus.Metrics, mx *metrics) {
-	m := raw.FindByName("kubeproxy_sync_proxy_rules_latency_microseconds_count")
-	mx.SyncProxyRules.Count.Set(m.Max())
-	kp.collectSyncProxyRulesLatency(raw, mx)
+	if name := findHistogram(raw, syncProxyRulesLatencyNames...); name != "" {
+		m := raw.FindByName(name + "_count")
+		mx.SyncProxyRules.Count.Set(m.Max())
+		kp.collectSyncProxyRulesLatency(raw, mx, name)
+	}
+
+	kp.collectSyncProxyRulesChanges(raw, mx)
+	kp.collectSyncProxyRulesLastSync(raw, mx)
+	kp.collectIPTablesRestoreFailures(raw, mx)
 }
 
-func (kp *KubeProxy) collectSyncProxyRulesLatency(raw prometheus.Metrics, mx *metrics) {
-	metricName := "kubeproxy_sync_proxy_rules_latency_microseconds_bucket"
+func (kp *KubeProxy) collectSyncProxyRulesLatency(raw prometheus.Metrics, mx *metrics, name string) {
 	latency := &mx.SyncProxyRules.Latency
 
-	for _, metric := range raw.FindByName(metricName) {
+	mul := 1.0
+	if strings.HasSuffix(name, "_seconds") {
+		mul = 1e6
+	}
+
+	for _, metric := range raw.FindByName(name + "_bucket") {
 		bucket := metric.Labels.Get("le")
 		value := metric.Value
-		switch bucket {
-		case "1000":
+		if bucket == "+Inf" {
+			latency.Inf.Set(value)
+			continue
+		}
+		le, err := strconv.ParseFloat(bucket, 64)
+		if err != nil {
+			continue
+		}
+		switch int64(math.Round(le * mul)) {
+		case 1000:
 			latency.LE1000.Set(value)
-		case "2000":
+		case 2000:
 			latency.LE2000.Set(value)
-		case "4000":
+		case 4000:
 			latency.LE4000.Set(value)
-		case "8000":
+		case 8000:
 			latency.LE8000.Set(value)
-		case "16000":
+		case 16000:
 			latency.LE16000.Set(value)
-		case "32000":
+		case 32000:
 			latency.LE32000.Set(value)
-		case "64000":
+		case 64000:
 			latency.LE64000.Set(value)
-		case "128000":
+		case 128000:
 			latency.LE128000.Set(value)
-		case "256000":
+		case 256000:
 			latency.LE256000.Set(value)
-		case "512000":
+		case 512000:
 			latency.LE512000.Set(value)
-		case "1.024e+06":
+		case 1024000:
 			latency.LE1024000.Set(value)
-		case "2.048e+06":
+		case 2048000:
 			latency.LE2048000.Set(value)
-		case "4.096e+06":
+		case 4096000:
 			latency.LE4096000.Set(value)
-		case "8.192e+06":
+		case 8192000:
 			latency.LE8192000.Set(value)
-		case "1.6384e+07":
+		case 16384000:
 			latency.LE16384000.Set(value)
-		case "+Inf":
-			latency.Inf.Set(value)
 		}
 	}
 
@@ -92,6 +121,117 @@ func (kp *KubeProxy) collectSyncProxyRulesLatency(raw prometheus.Metrics, mx *me
 	latency.LE2000.Sub(latency.LE1000.Value())
 }
 
+func (kp *KubeProxy) collectSyncProxyRulesChanges(raw prometheus.Metrics, mx *metrics) {
+	mx.SyncProxyRules.EndpointChanges = collectChanges(raw, "kubeproxy_sync_proxy_rules_endpoint_changes")
+	mx.SyncProxyRules.ServiceChanges = collectChanges(raw, "kubeproxy_sync_proxy_rules_service_changes")
+
+	if mx.SyncProxyRules.EndpointChanges != nil || mx.SyncProxyRules.ServiceChanges != nil {
+		kp.addChart(syncProxyRulesChangesChart)
+		kp.addChart(syncProxyRulesPendingChangesChart)
+	}
+}
+
+func collectChanges(raw prometheus.Metrics, name string) *changes {
+	total := raw.FindByName(name + "_total")
+	if len(total) == 0 {
+		return nil
+	}
+
+	var c changes
+	c.Total.Set(total.Max())
+	c.Pending.Set(raw.FindByName(name + "_pending").Max())
+	return &c
+}
+
+// collectSyncProxyRulesLastSync collects the time since the last successful sync, it grows if the rules are stale.
+func (kp *KubeProxy) collectSyncProxyRulesLastSync(raw prometheus.Metrics, mx *metrics) {
+	// the timestamp is zero until the first sync
+	ts := raw.FindByName("kubeproxy_sync_proxy_rules_last_timestamp_seconds").Max()
+	if ts <= 0 {
+		return
+	}
+
+	ago := kp.now().Sub(time.Unix(0, int64(ts*1e9))).Seconds()
+	if ago < 0 {
+		ago = 0
+	}
+
+	mx.SyncProxyRules.LastSyncAgo = new(mtx.Gauge)
+	mx.SyncProxyRules.LastSyncAgo.Set(ago)
+	kp.addChart(syncProxyRulesLastSyncChart)
+}
+
+func (kp *KubeProxy) collectIPTablesRestoreFailures(raw prometheus.Metrics, mx *metrics) {
+	total := raw.FindByName("kubeproxy_sync_proxy_rules_iptables_restore_failures_total")
+	if len(total) == 0 {
+		return
+	}
+
+	failures := &restoreFailures{}
+	failures.Total.Set(total.Max())
+	mx.SyncProxyRules.IPTablesRestoreFailures = failures
+
+	chart := kp.addChart(iptablesRestoreFailuresChart)
+
+	partial := raw.FindByName("kubeproxy_sync_proxy_rules_iptables_partial_restore_failures_total")
+	if len(partial) == 0 {
+		return
+	}
+
+	failures.Partial = new(mtx.Gauge)
+	failures.Partial.Set(partial.Max())
+
+	if dimID := "sync_proxy_rules_iptables_restore_failures_partial"; !chart.HasDim(dimID) {
+		_ = chart.AddDim(&Dim{ID: dimID, Name: "partial", Algo: module.Incremental})
+		chart.MarkNotCreated()
+	}
+}
+
+// collectNetworkProgrammingDuration collects the latency between a service or pod change
+// and the proxy rules reflecting it. The buckets are added dynamically, they differ between the releases.
+func (kp *KubeProxy) collectNetworkProgrammingDuration(raw prometheus.Metrics, mx *metrics) {
+	metrics := raw.FindByName("kubeproxy_network_programming_duration_seconds_bucket")
+	if len(metrics) == 0 {
+		return
+	}
+
+	type bucket struct {
+		le    string
+		bound float64
+		value float64
+	}
+
+	buckets := make([]bucket, 0, len(metrics))
+	for _, metric := range metrics {
+		le := metric.Labels.Get("le")
+		// "+Inf" is parsed as well
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, bucket{le: le, bound: bound, value: metric.Value})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+
+	chart := kp.addChart(networkProgrammingLatencyChart)
+
+	var prev float64
+	for _, b := range buckets {
+		dimID := "network_programming_duration_bucket_" + b.le
+		if !chart.HasDim(dimID) {
+			name := b.le + " sec"
+			if math.IsInf(b.bound, 1) {
+				name = b.le
+			}
+			_ = chart.AddDim(&Dim{ID: dimID, Name: name, Algo: module.Incremental})
+			chart.MarkNotCreated()
+		}
+		// the histogram buckets are cumulative
+		mx.NetworkProgramming.Duration.Buckets[b.le] = mtx.Gauge(b.value - prev)
+		prev = b.value
+	}
+}
+
 func (kp *KubeProxy) collectRESTClientHTTPRequests(raw prometheus.Metrics, mx *metrics) {
 	metricName := "rest_client_requests_total"
 	chart := kp.charts.Get("rest_client_requests_by_code")
@@ -142,3 +282,21 @@ func (kp *KubeProxy) collectHTTPRequestDuration(raw prometheus.Metrics, mx *metr
 		}
 	}
 }
+
+// findHistogram returns the first histogram name that is found, the names are checked in the given order.
+func findHistogram(raw prometheus.Metrics, names ...string) string {
+	for _, name := range names {
+		if len(raw.FindByName(name+"_count")) > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// addChart adds the chart if it isn't added yet and returns the job chart.
+func (kp *KubeProxy) addChart(chart Chart) *Chart {
+	if !kp.charts.Has(chart.ID) {
+		_ = kp.charts.Add(chart.Copy())
+	}
+	return kp.charts.Get(chart.ID)
+}
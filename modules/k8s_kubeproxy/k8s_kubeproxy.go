@@ -33,6 +33,7 @@ func New() *KubeProxy {
 	return &KubeProxy{
 		Config: config,
 		charts: charts.Copy(),
+		now:    time.Now,
 	}
 }
 
@@ -48,6 +49,7 @@ type KubeProxy struct {
 
 	prom   prometheus.Prometheus
 	charts *Charts
+	now    func() time.Time
 }
 
 // Cleanup makes cleanup.
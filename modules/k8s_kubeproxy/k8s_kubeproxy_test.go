@@ -5,12 +5,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-var testMetrics, _ = ioutil.ReadFile("testdata/metrics.txt")
+var (
+	testMetrics, _    = ioutil.ReadFile("testdata/metrics.txt")
+	testMetrics126, _ = ioutil.ReadFile("testdata/metrics-1.26.txt")
+)
 
 func TestNew(t *testing.T) {
 	job := New()
@@ -96,6 +100,84 @@ func TestKubeProxy_Collect(t *testing.T) {
 	assert.Equal(t, expected, job.Collect())
 }
 
+func TestKubeProxy_Collect_NewMetricsNames(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(testMetrics126)
+			}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	job.now = func() time.Time { return time.Unix(1600000030, 0) }
+	require.True(t, job.Init())
+
+	expected := map[string]int64{
+		"sync_proxy_rules_count":                             100,
+		"sync_proxy_rules_bucket_1000":                       0,
+		"sync_proxy_rules_bucket_2000":                       0,
+		"sync_proxy_rules_bucket_4000":                       0,
+		"sync_proxy_rules_bucket_8000":                       2,
+		"sync_proxy_rules_bucket_16000":                      28,
+		"sync_proxy_rules_bucket_32000":                      60,
+		"sync_proxy_rules_bucket_64000":                      9,
+		"sync_proxy_rules_bucket_128000":                     1,
+		"sync_proxy_rules_bucket_256000":                     0,
+		"sync_proxy_rules_bucket_512000":                     0,
+		"sync_proxy_rules_bucket_1024000":                    0,
+		"sync_proxy_rules_bucket_2048000":                    0,
+		"sync_proxy_rules_bucket_4096000":                    0,
+		"sync_proxy_rules_bucket_8192000":                    0,
+		"sync_proxy_rules_bucket_16384000":                   0,
+		"sync_proxy_rules_bucket_+Inf":                       0,
+		"sync_proxy_rules_endpoint_changes_total":            250,
+		"sync_proxy_rules_endpoint_changes_pending":          3,
+		"sync_proxy_rules_service_changes_total":             40,
+		"sync_proxy_rules_service_changes_pending":           0,
+		"sync_proxy_rules_last_sync_ago":                     30,
+		"sync_proxy_rules_iptables_restore_failures_total":   2,
+		"sync_proxy_rules_iptables_restore_failures_partial": 1,
+		"network_programming_duration_bucket_0.25":           10,
+		"network_programming_duration_bucket_0.5":            5,
+		"network_programming_duration_bucket_1":              3,
+		"network_programming_duration_bucket_2":              1,
+		"network_programming_duration_bucket_+Inf":           1,
+		"rest_client_requests_200":                           120,
+		"rest_client_requests_GET":                           120,
+		"http_request_duration_05":                           0,
+		"http_request_duration_09":                           0,
+		"http_request_duration_099":                          0,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+	assert.Len(t, *job.Charts(), len(charts)+5)
+
+	chart := job.Charts().Get("kubeproxy_network_programming_latency")
+	require.NotNil(t, chart)
+	require.Len(t, chart.Dims, 5)
+	assert.Equal(t, "0.25 sec", chart.Dims[0].Name)
+	assert.Equal(t, "+Inf", chart.Dims[4].Name)
+	assert.True(t, job.Charts().Get("kubeproxy_sync_proxy_rules_iptables_restore_failures").
+		HasDim("sync_proxy_rules_iptables_restore_failures_partial"))
+}
+
+func TestKubeProxy_Collect_OldVersionNoOptionalCharts(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(testMetrics)
+			}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL + "/metrics"
+	require.True(t, job.Init())
+	require.True(t, job.Check())
+
+	assert.Len(t, *job.Charts(), len(charts))
+}
+
 func TestKubeProxy_InvalidData(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(
@@ -8,6 +8,7 @@ func newMetrics() *metrics {
 	var mx metrics
 	mx.RESTClient.Requests.ByStatusCode = make(map[string]mtx.Gauge)
 	mx.RESTClient.Requests.ByMethod = make(map[string]mtx.Gauge)
+	mx.NetworkProgramming.Duration.Buckets = make(map[string]mtx.Gauge)
 
 	return &mx
 }
@@ -33,7 +34,17 @@ type metrics struct {
 			LE16384000 mtx.Gauge `stm:"16384000"`
 			Inf        mtx.Gauge `stm:"+Inf"`
 		} `stm:"bucket"`
+		// the metrics below are missing in the old kube-proxy versions, nil means not reported
+		EndpointChanges         *changes         `stm:"endpoint_changes"`
+		ServiceChanges          *changes         `stm:"service_changes"`
+		LastSyncAgo             *mtx.Gauge       `stm:"last_sync_ago"`
+		IPTablesRestoreFailures *restoreFailures `stm:"iptables_restore_failures"`
 	} `stm:"sync_proxy_rules"`
+	NetworkProgramming struct {
+		Duration struct {
+			Buckets map[string]mtx.Gauge `stm:"bucket"`
+		} `stm:"duration"`
+	} `stm:"network_programming"`
 	RESTClient struct {
 		Requests struct {
 			ByStatusCode map[string]mtx.Gauge `stm:""`
@@ -50,3 +61,13 @@ type metrics struct {
 		} `stm:"request"`
 	} `stm:"http"`
 }
+
+type changes struct {
+	Total   mtx.Gauge `stm:"total"`
+	Pending mtx.Gauge `stm:"pending"`
+}
+
+type restoreFailures struct {
+	Total   mtx.Gauge  `stm:"total"`
+	Partial *mtx.Gauge `stm:"partial"`
+}
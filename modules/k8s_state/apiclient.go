@@ -0,0 +1,241 @@
+package k8s_state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+const (
+	uriNodes       = "/api/v1/nodes"
+	uriPods        = "/api/v1/pods"
+	uriPVCs        = "/api/v1/persistentvolumeclaims"
+	uriDeployments = "/apis/apps/v1/deployments"
+	uriDaemonSets  = "/apis/apps/v1/daemonsets"
+)
+
+// listLimit is the page size of the list requests, big clusters responses are split into several pages.
+const listLimit = 500
+
+type (
+	objectMeta struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+	listMeta struct {
+		Continue string `json:"continue"`
+	}
+	resourceList map[string]string
+)
+
+type (
+	nodeList struct {
+		Metadata listMeta `json:"metadata"`
+		Items    []node   `json:"items"`
+	}
+	node struct {
+		Metadata objectMeta `json:"metadata"`
+		Spec     struct {
+			Unschedulable bool `json:"unschedulable"`
+		} `json:"spec"`
+		Status struct {
+			Allocatable resourceList `json:"allocatable"`
+			Conditions  []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+)
+
+type (
+	podList struct {
+		Metadata listMeta `json:"metadata"`
+		Items    []pod    `json:"items"`
+	}
+	pod struct {
+		Metadata objectMeta `json:"metadata"`
+		Spec     struct {
+			NodeName       string      `json:"nodeName"`
+			Containers     []container `json:"containers"`
+			InitContainers []container `json:"initContainers"`
+		} `json:"spec"`
+		Status struct {
+			Phase             string `json:"phase"`
+			ContainerStatuses []struct {
+				RestartCount int64 `json:"restartCount"`
+			} `json:"containerStatuses"`
+		} `json:"status"`
+	}
+	container struct {
+		Resources struct {
+			Requests resourceList `json:"requests"`
+			Limits   resourceList `json:"limits"`
+		} `json:"resources"`
+	}
+)
+
+type (
+	pvcList struct {
+		Metadata listMeta `json:"metadata"`
+		Items    []pvc    `json:"items"`
+	}
+	pvc struct {
+		Metadata objectMeta `json:"metadata"`
+		Status   struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	}
+)
+
+type (
+	deploymentList struct {
+		Metadata listMeta     `json:"metadata"`
+		Items    []deployment `json:"items"`
+	}
+	deployment struct {
+		Metadata objectMeta `json:"metadata"`
+		Spec     struct {
+			// nil means the default (1 replica)
+			Replicas *int64 `json:"replicas"`
+		} `json:"spec"`
+		Status struct {
+			ReadyReplicas       int64 `json:"readyReplicas"`
+			AvailableReplicas   int64 `json:"availableReplicas"`
+			UnavailableReplicas int64 `json:"unavailableReplicas"`
+			UpdatedReplicas     int64 `json:"updatedReplicas"`
+		} `json:"status"`
+	}
+)
+
+type (
+	daemonSetList struct {
+		Metadata listMeta    `json:"metadata"`
+		Items    []daemonSet `json:"items"`
+	}
+	daemonSet struct {
+		Metadata objectMeta `json:"metadata"`
+		Status   struct {
+			DesiredNumberScheduled int64 `json:"desiredNumberScheduled"`
+			CurrentNumberScheduled int64 `json:"currentNumberScheduled"`
+			NumberReady            int64 `json:"numberReady"`
+			NumberAvailable        int64 `json:"numberAvailable"`
+			NumberMisscheduled     int64 `json:"numberMisscheduled"`
+		} `json:"status"`
+	}
+)
+
+type apiClient struct {
+	req        web.Request
+	httpClient *http.Client
+}
+
+func (a apiClient) nodes() ([]node, error) {
+	var items []node
+	err := a.list(uriNodes, func() interface{} { return &nodeList{} }, func(v interface{}) string {
+		l := v.(*nodeList)
+		items = append(items, l.Items...)
+		return l.Metadata.Continue
+	})
+	return items, err
+}
+
+func (a apiClient) pods() ([]pod, error) {
+	var items []pod
+	err := a.list(uriPods, func() interface{} { return &podList{} }, func(v interface{}) string {
+		l := v.(*podList)
+		items = append(items, l.Items...)
+		return l.Metadata.Continue
+	})
+	return items, err
+}
+
+func (a apiClient) pvcs() ([]pvc, error) {
+	var items []pvc
+	err := a.list(uriPVCs, func() interface{} { return &pvcList{} }, func(v interface{}) string {
+		l := v.(*pvcList)
+		items = append(items, l.Items...)
+		return l.Metadata.Continue
+	})
+	return items, err
+}
+
+func (a apiClient) deployments() ([]deployment, error) {
+	var items []deployment
+	err := a.list(uriDeployments, func() interface{} { return &deploymentList{} }, func(v interface{}) string {
+		l := v.(*deploymentList)
+		items = append(items, l.Items...)
+		return l.Metadata.Continue
+	})
+	return items, err
+}
+
+func (a apiClient) daemonSets() ([]daemonSet, error) {
+	var items []daemonSet
+	err := a.list(uriDaemonSets, func() interface{} { return &daemonSetList{} }, func(v interface{}) string {
+		l := v.(*daemonSetList)
+		items = append(items, l.Items...)
+		return l.Metadata.Continue
+	})
+	return items, err
+}
+
+// list requests all the pages of the list, newList creates the page destination,
+// addPage stores the page items and returns the continue token of the next page.
+func (a apiClient) list(uri string, newList func() interface{}, addPage func(interface{}) string) error {
+	var token string
+
+	for {
+		query := url.Values{"limit": {fmt.Sprint(listLimit)}}
+		if token != "" {
+			query.Set("continue", token)
+		}
+
+		page := newList()
+		if err := a.getJSON(uri+"?"+query.Encode(), page); err != nil {
+			return err
+		}
+
+		if token = addPage(page); token == "" {
+			return nil
+		}
+	}
+}
+
+func (a apiClient) getJSON(uri string, dst interface{}) error {
+	a.req.URI = uri
+
+	req, err := web.NewHTTPRequest(a.req)
+	if err != nil {
+		return fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error on request to %s : %v", req.URL, err)
+	}
+
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %d", req.URL, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("error on decoding resp from %s : %v", req.URL, err)
+	}
+
+	return nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,190 @@
+package k8s_state
+
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+type (
+	// Charts is an alias for module.Charts
+	Charts = module.Charts
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
+)
+
+var clusterCharts = Charts{
+	{
+		ID:    "nodes",
+		Title: "Nodes",
+		Units: "nodes",
+		Fam:   "cluster",
+		Ctx:   "k8s_state.nodes",
+		Dims: Dims{
+			{ID: "nodes_ready", Name: "ready"},
+			{ID: "nodes_not_ready", Name: "not ready"},
+			{ID: "nodes_unschedulable", Name: "unschedulable"},
+		},
+	},
+	{
+		ID:    "pods_phase",
+		Title: "Pods In Various Phases",
+		Units: "pods",
+		Fam:   "cluster",
+		Ctx:   "k8s_state.pods_phase",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "pods_phase_running", Name: "running"},
+			{ID: "pods_phase_pending", Name: "pending"},
+			{ID: "pods_phase_succeeded", Name: "succeeded"},
+			{ID: "pods_phase_failed", Name: "failed"},
+			{ID: "pods_phase_unknown", Name: "unknown"},
+		},
+	},
+}
+
+// nodeCharts are the per node charts, '%s' is the node name.
+var nodeCharts = Charts{
+	{
+		ID:    "node_%s_conditions",
+		Title: "Node Conditions",
+		Units: "status",
+		Fam:   "node %s",
+		Ctx:   "k8s_state.node_conditions",
+		Dims: Dims{
+			{ID: "node_%s_cond_ready", Name: "ready"},
+			{ID: "node_%s_cond_memory_pressure", Name: "memory pressure"},
+			{ID: "node_%s_cond_disk_pressure", Name: "disk pressure"},
+			{ID: "node_%s_cond_pid_pressure", Name: "pid pressure"},
+			{ID: "node_%s_cond_network_unavailable", Name: "network unavailable"},
+		},
+	},
+	{
+		ID:    "node_%s_cpu",
+		Title: "Node CPU Allocatable And Requested",
+		Units: "millicores",
+		Fam:   "node %s",
+		Ctx:   "k8s_state.node_cpu",
+		Dims: Dims{
+			{ID: "node_%s_alloc_cpu", Name: "allocatable"},
+			{ID: "node_%s_req_cpu", Name: "requests"},
+			{ID: "node_%s_lim_cpu", Name: "limits"},
+		},
+	},
+	{
+		ID:    "node_%s_mem",
+		Title: "Node Memory Allocatable And Requested",
+		Units: "MiB",
+		Fam:   "node %s",
+		Ctx:   "k8s_state.node_mem",
+		Dims: Dims{
+			{ID: "node_%s_alloc_mem", Name: "allocatable", Div: 1 << 20},
+			{ID: "node_%s_req_mem", Name: "requests", Div: 1 << 20},
+			{ID: "node_%s_lim_mem", Name: "limits", Div: 1 << 20},
+		},
+	},
+	{
+		ID:    "node_%s_pods",
+		Title: "Node Pods Allocatable And Running",
+		Units: "pods",
+		Fam:   "node %s",
+		Ctx:   "k8s_state.node_pods",
+		Dims: Dims{
+			{ID: "node_%s_alloc_pods", Name: "allocatable"},
+			{ID: "node_%s_pods", Name: "running"},
+		},
+	},
+}
+
+// namespaceCharts are the per namespace charts, '%s' is the namespace.
+var namespaceCharts = Charts{
+	{
+		ID:    "ns_%s_pods_phase",
+		Title: "Namespace Pods In Various Phases",
+		Units: "pods",
+		Fam:   "ns %s",
+		Ctx:   "k8s_state.namespace_pods_phase",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "ns_%s_pods_phase_running", Name: "running"},
+			{ID: "ns_%s_pods_phase_pending", Name: "pending"},
+			{ID: "ns_%s_pods_phase_succeeded", Name: "succeeded"},
+			{ID: "ns_%s_pods_phase_failed", Name: "failed"},
+			{ID: "ns_%s_pods_phase_unknown", Name: "unknown"},
+		},
+	},
+	{
+		ID:    "ns_%s_containers_restarts",
+		Title: "Namespace Containers Restarts",
+		Units: "restarts/s",
+		Fam:   "ns %s",
+		Ctx:   "k8s_state.namespace_containers_restarts",
+		Dims: Dims{
+			{ID: "ns_%s_containers_restarts", Name: "restarts", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "ns_%s_pvcs_phase",
+		Title: "Namespace Persistent Volume Claims In Various Phases",
+		Units: "claims",
+		Fam:   "ns %s",
+		Ctx:   "k8s_state.namespace_pvcs_phase",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "ns_%s_pvcs_phase_bound", Name: "bound"},
+			{ID: "ns_%s_pvcs_phase_pending", Name: "pending"},
+			{ID: "ns_%s_pvcs_phase_lost", Name: "lost"},
+		},
+	},
+}
+
+// deploymentCharts are the per deployment charts, '%s' is the namespace and the deployment name.
+var deploymentCharts = Charts{
+	{
+		ID:    "deploy_%s_replicas",
+		Title: "Deployment Replicas",
+		Units: "replicas",
+		Fam:   "ns %s",
+		Ctx:   "k8s_state.deployment_replicas",
+		Dims: Dims{
+			{ID: "deploy_%s_replicas_desired", Name: "desired"},
+			{ID: "deploy_%s_replicas_ready", Name: "ready"},
+			{ID: "deploy_%s_replicas_available", Name: "available"},
+			{ID: "deploy_%s_replicas_unavailable", Name: "unavailable"},
+			{ID: "deploy_%s_replicas_updated", Name: "updated"},
+		},
+	},
+}
+
+// daemonSetCharts are the per daemonset charts, '%s' is the namespace and the daemonset name.
+var daemonSetCharts = Charts{
+	{
+		ID:    "ds_%s_pods",
+		Title: "DaemonSet Pods",
+		Units: "pods",
+		Fam:   "ns %s",
+		Ctx:   "k8s_state.daemonset_pods",
+		Dims: Dims{
+			{ID: "ds_%s_pods_desired", Name: "desired"},
+			{ID: "ds_%s_pods_current", Name: "current"},
+			{ID: "ds_%s_pods_ready", Name: "ready"},
+			{ID: "ds_%s_pods_available", Name: "available"},
+			{ID: "ds_%s_pods_misscheduled", Name: "misscheduled"},
+		},
+	},
+}
+
+func newCharts(tmpl Charts, id, fam string) *Charts {
+	charts := tmpl.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Fam = fmt.Sprintf(chart.Fam, fam)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	return charts
+}
@@ -0,0 +1,327 @@
+package k8s_state
+
+import (
+	"fmt"
+	"strings"
+)
+
+var nameReplacer = strings.NewReplacer(".", "_", " ", "_")
+
+// object is a Kubernetes object (node, namespace, deployment, daemonset) that has charts.
+type object struct {
+	charts Charts
+	id     string
+	fam    string
+}
+
+type objects map[string]object
+
+func (o objects) add(charts Charts, id, fam string) {
+	o[charts[0].ID+"/"+id] = object{charts: charts, id: id, fam: fam}
+}
+
+func (ks *K8sState) collect() (map[string]int64, error) {
+	nodes, err := ks.apiClient.nodes()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := ks.apiClient.pods()
+	if err != nil {
+		return nil, err
+	}
+	pvcs, err := ks.apiClient.pvcs()
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := ks.apiClient.deployments()
+	if err != nil {
+		return nil, err
+	}
+	daemonSets, err := ks.apiClient.daemonSets()
+	if err != nil {
+		return nil, err
+	}
+
+	mx := make(map[string]int64)
+	seen := make(objects)
+
+	collectNodes(mx, seen, nodes, pods)
+	ks.collectPods(mx, seen, pods)
+	ks.collectPVCs(mx, seen, pvcs)
+	ks.collectDeployments(mx, seen, deployments)
+	ks.collectDaemonSets(mx, seen, daemonSets)
+
+	ks.updateCharts(seen)
+
+	return mx, nil
+}
+
+// collectNodes collects the nodes conditions and resources, the resources are requested by all the pods
+// scheduled on the node, the namespaces filter is not applied.
+func collectNodes(mx map[string]int64, seen objects, nodes []node, pods []pod) {
+	for _, dim := range clusterCharts[0].Dims {
+		mx[dim.ID] = 0
+	}
+
+	nodePods := make(map[string][]pod)
+	for _, p := range pods {
+		if p.Spec.NodeName != "" && !isTerminated(p) {
+			nodePods[p.Spec.NodeName] = append(nodePods[p.Spec.NodeName], p)
+		}
+	}
+
+	for _, n := range nodes {
+		id := nameReplacer.Replace(n.Metadata.Name)
+		seen.add(nodeCharts, id, n.Metadata.Name)
+		prefix := "node_" + id
+
+		for _, dim := range nodeCharts[0].Dims {
+			mx[fmt.Sprintf(dim.ID, id)] = 0
+		}
+		ready := false
+		for _, c := range n.Status.Conditions {
+			if c.Status != "True" {
+				continue
+			}
+			switch c.Type {
+			case "Ready":
+				ready = true
+				mx[prefix+"_cond_ready"] = 1
+			case "MemoryPressure":
+				mx[prefix+"_cond_memory_pressure"] = 1
+			case "DiskPressure":
+				mx[prefix+"_cond_disk_pressure"] = 1
+			case "PIDPressure":
+				mx[prefix+"_cond_pid_pressure"] = 1
+			case "NetworkUnavailable":
+				mx[prefix+"_cond_network_unavailable"] = 1
+			}
+		}
+
+		if ready {
+			mx["nodes_ready"]++
+		} else {
+			mx["nodes_not_ready"]++
+		}
+		if n.Spec.Unschedulable {
+			mx["nodes_unschedulable"]++
+		}
+
+		mx[prefix+"_alloc_cpu"] = n.Status.Allocatable.milli("cpu")
+		mx[prefix+"_alloc_mem"] = n.Status.Allocatable.value("memory")
+		mx[prefix+"_alloc_pods"] = n.Status.Allocatable.value("pods")
+
+		var r podResources
+		for _, p := range nodePods[n.Metadata.Name] {
+			r.add(newPodResources(p))
+		}
+		mx[prefix+"_req_cpu"] = r.reqCPU
+		mx[prefix+"_lim_cpu"] = r.limCPU
+		mx[prefix+"_req_mem"] = r.reqMem
+		mx[prefix+"_lim_mem"] = r.limMem
+		mx[prefix+"_pods"] = int64(len(nodePods[n.Metadata.Name]))
+	}
+}
+
+func (ks *K8sState) collectPods(mx map[string]int64, seen objects, pods []pod) {
+	for _, dim := range clusterCharts[1].Dims {
+		mx[dim.ID] = 0
+	}
+
+	for _, p := range pods {
+		phase := podPhase(p)
+		mx["pods_phase_"+phase]++
+
+		ns := p.Metadata.Namespace
+		if !ks.filterNamespace(ns) {
+			continue
+		}
+
+		prefix := "ns_" + ks.addNamespace(mx, seen, ns)
+		mx[prefix+"_pods_phase_"+phase]++
+		for _, s := range p.Status.ContainerStatuses {
+			mx[prefix+"_containers_restarts"] += s.RestartCount
+		}
+	}
+}
+
+func (ks *K8sState) collectPVCs(mx map[string]int64, seen objects, pvcs []pvc) {
+	for _, c := range pvcs {
+		ns := c.Metadata.Namespace
+		if !ks.filterNamespace(ns) {
+			continue
+		}
+
+		prefix := "ns_" + ks.addNamespace(mx, seen, ns)
+		switch c.Status.Phase {
+		case "Bound":
+			mx[prefix+"_pvcs_phase_bound"]++
+		case "Lost":
+			mx[prefix+"_pvcs_phase_lost"]++
+		default:
+			mx[prefix+"_pvcs_phase_pending"]++
+		}
+	}
+}
+
+func (ks *K8sState) collectDeployments(mx map[string]int64, seen objects, deployments []deployment) {
+	for _, d := range deployments {
+		if !ks.filterNamespace(d.Metadata.Namespace) {
+			continue
+		}
+
+		id := objectID(d.Metadata)
+		seen.add(deploymentCharts, id, d.Metadata.Namespace)
+		prefix := "deploy_" + id
+
+		desired := int64(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		mx[prefix+"_replicas_desired"] = desired
+		mx[prefix+"_replicas_ready"] = d.Status.ReadyReplicas
+		mx[prefix+"_replicas_available"] = d.Status.AvailableReplicas
+		mx[prefix+"_replicas_unavailable"] = d.Status.UnavailableReplicas
+		mx[prefix+"_replicas_updated"] = d.Status.UpdatedReplicas
+	}
+}
+
+func (ks *K8sState) collectDaemonSets(mx map[string]int64, seen objects, daemonSets []daemonSet) {
+	for _, d := range daemonSets {
+		if !ks.filterNamespace(d.Metadata.Namespace) {
+			continue
+		}
+
+		id := objectID(d.Metadata)
+		seen.add(daemonSetCharts, id, d.Metadata.Namespace)
+		prefix := "ds_" + id
+
+		mx[prefix+"_pods_desired"] = d.Status.DesiredNumberScheduled
+		mx[prefix+"_pods_current"] = d.Status.CurrentNumberScheduled
+		mx[prefix+"_pods_ready"] = d.Status.NumberReady
+		mx[prefix+"_pods_available"] = d.Status.NumberAvailable
+		mx[prefix+"_pods_misscheduled"] = d.Status.NumberMisscheduled
+	}
+}
+
+// addNamespace adds the namespace charts and zeroes the namespace metrics, it returns the namespace id.
+func (ks *K8sState) addNamespace(mx map[string]int64, seen objects, ns string) string {
+	id := nameReplacer.Replace(ns)
+	key := namespaceCharts[0].ID + "/" + id
+
+	if _, ok := seen[key]; ok {
+		return id
+	}
+	seen.add(namespaceCharts, id, ns)
+
+	for _, chart := range namespaceCharts {
+		for _, dim := range chart.Dims {
+			mx[fmt.Sprintf(dim.ID, id)] = 0
+		}
+	}
+
+	return id
+}
+
+// updateCharts adds the charts of the new objects and removes the charts of the deleted ones.
+func (ks *K8sState) updateCharts(seen objects) {
+	for key, o := range seen {
+		if _, ok := ks.active[key]; ok {
+			continue
+		}
+		ks.active[key] = o
+		_ = ks.charts.Add(*newCharts(o.charts, o.id, o.fam)...)
+	}
+
+	for key, o := range ks.active {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		delete(ks.active, key)
+
+		for _, chart := range o.charts {
+			chart = ks.charts.Get(fmt.Sprintf(chart.ID, o.id))
+			if chart == nil {
+				continue
+			}
+			chart.Obsolete = true
+			chart.MarkNotCreated()
+			chart.MarkRemove()
+		}
+	}
+}
+
+func (ks K8sState) filterNamespace(ns string) bool {
+	if ks.namespacesFilter == nil {
+		return true
+	}
+	return ks.namespacesFilter.MatchString(ns)
+}
+
+type podResources struct {
+	reqCPU, limCPU int64
+	reqMem, limMem int64
+}
+
+// newPodResources returns the pod effective requests and limits the same way the scheduler does:
+// the max of the sum of the containers resources and the max of the init containers resources.
+func newPodResources(p pod) podResources {
+	var r, initRes podResources
+
+	for _, c := range p.Spec.Containers {
+		r.add(newContainerResources(c))
+	}
+	for _, c := range p.Spec.InitContainers {
+		initRes.max(newContainerResources(c))
+	}
+	r.max(initRes)
+
+	return r
+}
+
+func newContainerResources(c container) podResources {
+	return podResources{
+		reqCPU: c.Resources.Requests.milli("cpu"),
+		limCPU: c.Resources.Limits.milli("cpu"),
+		reqMem: c.Resources.Requests.value("memory"),
+		limMem: c.Resources.Limits.value("memory"),
+	}
+}
+
+func (r *podResources) add(v podResources) {
+	r.reqCPU += v.reqCPU
+	r.limCPU += v.limCPU
+	r.reqMem += v.reqMem
+	r.limMem += v.limMem
+}
+
+func (r *podResources) max(v podResources) {
+	r.reqCPU = max(r.reqCPU, v.reqCPU)
+	r.limCPU = max(r.limCPU, v.limCPU)
+	r.reqMem = max(r.reqMem, v.reqMem)
+	r.limMem = max(r.limMem, v.limMem)
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func isTerminated(p pod) bool {
+	return p.Status.Phase == "Succeeded" || p.Status.Phase == "Failed"
+}
+
+func podPhase(p pod) string {
+	switch p.Status.Phase {
+	case "Running", "Pending", "Succeeded", "Failed":
+		return strings.ToLower(p.Status.Phase)
+	}
+	return "unknown"
+}
+
+// objectID returns the namespaced object id, namespaces can't contain '_'.
+func objectID(meta objectMeta) string {
+	return nameReplacer.Replace(meta.Namespace + "_" + meta.Name)
+}
@@ -0,0 +1,139 @@
+package k8s_state
+
+import (
+	"net"
+	"os"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+const (
+	defaultURL         = "https://kubernetes.default.svc"
+	defaultHTTPTimeout = time.Second * 5
+	// the pod service account token and the cluster CA
+	defaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	defaultCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+func init() {
+	creator := module.Creator{
+		DisabledByDefault: true,
+		// every update is a full list of the cluster objects
+		UpdateEvery: 30,
+		Create:      func() module.Module { return New() },
+	}
+
+	module.Register("k8s_state", creator)
+}
+
+// New creates K8sState with default values.
+func New() *K8sState {
+	address := defaultURL
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" && port != "" {
+		address = "https://" + net.JoinHostPort(host, port)
+	}
+
+	config := Config{
+		HTTP: web.HTTP{
+			Request: web.Request{URL: address, BearerTokenFile: defaultTokenFile},
+			Client: web.Client{
+				Timeout:         web.Duration{Duration: defaultHTTPTimeout},
+				ClientTLSConfig: web.ClientTLSConfig{TLSCA: defaultCAFile},
+			},
+		},
+	}
+
+	return &K8sState{
+		Config: config,
+		charts: clusterCharts.Copy(),
+		active: make(objects),
+	}
+}
+
+// Config is the K8sState module configuration.
+type Config struct {
+	web.HTTP `yaml:",inline"`
+
+	NamespacesFilter string `yaml:"namespaces_filter"`
+}
+
+// K8sState K8sState module.
+type K8sState struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	apiClient        *apiClient
+	namespacesFilter matcher.Matcher
+	charts           *Charts
+	// active are the objects that have charts
+	active objects
+}
+
+// Cleanup makes cleanup.
+func (K8sState) Cleanup() {}
+
+// Init makes initialization.
+func (ks *K8sState) Init() bool {
+	if ks.URL == "" {
+		ks.Error("URL parameter is mandatory, please set")
+		return false
+	}
+
+	// the service account defaults are only usable when the plugin runs in a pod
+	if ks.BearerTokenFile == defaultTokenFile && !fileExists(defaultTokenFile) {
+		ks.BearerTokenFile = ""
+	}
+	if ks.TLSCA == defaultCAFile && !fileExists(defaultCAFile) {
+		ks.TLSCA = ""
+	}
+
+	if ks.NamespacesFilter != "" {
+		m, err := matcher.NewSimplePatternsMatcher(ks.NamespacesFilter)
+		if err != nil {
+			ks.Errorf("error on creating namespaces filter : %v", err)
+			return false
+		}
+		ks.namespacesFilter = matcher.WithCache(m)
+	}
+
+	client, err := web.NewHTTPClient(ks.Client)
+	if err != nil {
+		ks.Errorf("error on creating http client : %v", err)
+		return false
+	}
+
+	ks.apiClient = &apiClient{req: ks.Request, httpClient: client}
+
+	return true
+}
+
+// Check makes check.
+func (ks *K8sState) Check() bool {
+	return len(ks.Collect()) > 0
+}
+
+// Charts creates Charts.
+func (ks K8sState) Charts() *Charts {
+	return ks.charts
+}
+
+// Collect collects metrics.
+func (ks *K8sState) Collect() map[string]int64 {
+	mx, err := ks.collect()
+
+	if err != nil {
+		ks.Error(err)
+		return nil
+	}
+
+	return mx
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
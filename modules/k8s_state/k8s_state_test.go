@@ -0,0 +1,272 @@
+package k8s_state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNodes, _       = ioutil.ReadFile("testdata/nodes.json")
+	testPodsPage1, _   = ioutil.ReadFile("testdata/pods-1.json")
+	testPodsPage2, _   = ioutil.ReadFile("testdata/pods-2.json")
+	testPVCs, _        = ioutil.ReadFile("testdata/pvcs.json")
+	testDeployments, _ = ioutil.ReadFile("testdata/deployments.json")
+	testDaemonSets, _  = ioutil.ReadFile("testdata/daemonsets.json")
+)
+
+func TestNew(t *testing.T) {
+	job := New()
+
+	assert.IsType(t, (*K8sState)(nil), job)
+	assert.Equal(t, defaultURL, job.URL)
+	assert.Equal(t, defaultHTTPTimeout, job.Timeout.Duration)
+	assert.Equal(t, defaultTokenFile, job.BearerTokenFile)
+}
+
+func TestK8sState_Charts(t *testing.T) { assert.NotNil(t, New().Charts()) }
+
+func TestK8sState_Cleanup(t *testing.T) { New().Cleanup() }
+
+func TestK8sState_Init(t *testing.T) {
+	job := New()
+	require.True(t, job.Init())
+
+	// there is no service account outside a pod
+	assert.Empty(t, job.BearerTokenFile)
+	assert.Empty(t, job.TLSCA)
+}
+
+func TestK8sState_InitNG(t *testing.T) {
+	job := New()
+	job.URL = ""
+	assert.False(t, job.Init())
+
+	job = New()
+	job.NamespacesFilter = "*["
+	assert.False(t, job.Init())
+}
+
+// newTestAPIServer returns a fake API server, the deployments are served by reference to test the objects removal.
+func newTestAPIServer(t *testing.T, deployments *[]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") == "" {
+			t.Errorf("list request to %s is not limited", r.URL)
+		}
+
+		switch r.URL.Path {
+		case uriNodes:
+			_, _ = w.Write(testNodes)
+		case uriPods:
+			if r.URL.Query().Get("continue") == "page2" {
+				_, _ = w.Write(testPodsPage2)
+			} else {
+				_, _ = w.Write(testPodsPage1)
+			}
+		case uriPVCs:
+			_, _ = w.Write(testPVCs)
+		case uriDeployments:
+			_, _ = w.Write(*deployments)
+		case uriDaemonSets:
+			_, _ = w.Write(testDaemonSets)
+		default:
+			t.Errorf("unexpected request to %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestK8sState_Check(t *testing.T) {
+	ts := newTestAPIServer(t, &testDeployments)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	require.True(t, job.Init())
+	assert.True(t, job.Check())
+}
+
+func TestK8sState_CheckNG(t *testing.T) {
+	job := New()
+	job.URL = "http://127.0.0.1:38001"
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
+}
+
+func TestK8sState_Collect(t *testing.T) {
+	deployments := testDeployments
+	ts := newTestAPIServer(t, &deployments)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	require.True(t, job.Init())
+
+	expected := map[string]int64{
+		"nodes_ready":         1,
+		"nodes_not_ready":     1,
+		"nodes_unschedulable": 1,
+
+		"pods_phase_running":   2,
+		"pods_phase_pending":   1,
+		"pods_phase_succeeded": 1,
+		"pods_phase_failed":    0,
+		"pods_phase_unknown":   0,
+
+		"node_node1_example_com_cond_ready":               1,
+		"node_node1_example_com_cond_memory_pressure":     0,
+		"node_node1_example_com_cond_disk_pressure":       0,
+		"node_node1_example_com_cond_pid_pressure":        0,
+		"node_node1_example_com_cond_network_unavailable": 0,
+		"node_node1_example_com_alloc_cpu":                3800,
+		"node_node1_example_com_alloc_mem":                8091303936,
+		"node_node1_example_com_alloc_pods":               110,
+		"node_node1_example_com_req_cpu":                  1000,
+		"node_node1_example_com_lim_cpu":                  500,
+		"node_node1_example_com_req_mem":                  167772160,
+		"node_node1_example_com_lim_mem":                  335544320,
+		"node_node1_example_com_pods":                     1,
+
+		"node_node2_cond_ready":               0,
+		"node_node2_cond_memory_pressure":     0,
+		"node_node2_cond_disk_pressure":       1,
+		"node_node2_cond_pid_pressure":        0,
+		"node_node2_cond_network_unavailable": 0,
+		"node_node2_alloc_cpu":                2000,
+		"node_node2_alloc_mem":                4294967296,
+		"node_node2_alloc_pods":               110,
+		"node_node2_req_cpu":                  100,
+		"node_node2_lim_cpu":                  0,
+		"node_node2_req_mem":                  73400320,
+		"node_node2_lim_mem":                  178257920,
+		"node_node2_pods":                     1,
+
+		"ns_default_pods_phase_running":   1,
+		"ns_default_pods_phase_pending":   1,
+		"ns_default_pods_phase_succeeded": 0,
+		"ns_default_pods_phase_failed":    0,
+		"ns_default_pods_phase_unknown":   0,
+		"ns_default_containers_restarts":  3,
+		"ns_default_pvcs_phase_bound":     1,
+		"ns_default_pvcs_phase_pending":   1,
+		"ns_default_pvcs_phase_lost":      0,
+
+		"ns_kube-system_pods_phase_running":   1,
+		"ns_kube-system_pods_phase_pending":   0,
+		"ns_kube-system_pods_phase_succeeded": 1,
+		"ns_kube-system_pods_phase_failed":    0,
+		"ns_kube-system_pods_phase_unknown":   0,
+		"ns_kube-system_containers_restarts":  5,
+		"ns_kube-system_pvcs_phase_bound":     0,
+		"ns_kube-system_pvcs_phase_pending":   0,
+		"ns_kube-system_pvcs_phase_lost":      0,
+
+		"ns_monitoring_pods_phase_running":   0,
+		"ns_monitoring_pods_phase_pending":   0,
+		"ns_monitoring_pods_phase_succeeded": 0,
+		"ns_monitoring_pods_phase_failed":    0,
+		"ns_monitoring_pods_phase_unknown":   0,
+		"ns_monitoring_containers_restarts":  0,
+		"ns_monitoring_pvcs_phase_bound":     0,
+		"ns_monitoring_pvcs_phase_pending":   0,
+		"ns_monitoring_pvcs_phase_lost":      1,
+
+		"deploy_default_web_replicas_desired":     2,
+		"deploy_default_web_replicas_ready":       1,
+		"deploy_default_web_replicas_available":   1,
+		"deploy_default_web_replicas_unavailable": 1,
+		"deploy_default_web_replicas_updated":     2,
+
+		"deploy_kube-system_coredns_replicas_desired":     1,
+		"deploy_kube-system_coredns_replicas_ready":       1,
+		"deploy_kube-system_coredns_replicas_available":   1,
+		"deploy_kube-system_coredns_replicas_unavailable": 0,
+		"deploy_kube-system_coredns_replicas_updated":     1,
+
+		"ds_kube-system_kube-proxy_pods_desired":      2,
+		"ds_kube-system_kube-proxy_pods_current":      2,
+		"ds_kube-system_kube-proxy_pods_ready":        1,
+		"ds_kube-system_kube-proxy_pods_available":    1,
+		"ds_kube-system_kube-proxy_pods_misscheduled": 0,
+	}
+
+	assert.Equal(t, expected, job.Collect())
+	assert.Len(t, *job.Charts(), len(clusterCharts)+
+		2*len(nodeCharts)+3*len(namespaceCharts)+2*len(deploymentCharts)+len(daemonSetCharts))
+
+	chart := job.Charts().Get("deploy_default_web_replicas")
+	require.NotNil(t, chart)
+	assert.Equal(t, "ns default", chart.Fam)
+	assert.Equal(t, "node node1.example.com", job.Charts().Get("node_node1_example_com_conditions").Fam)
+
+	// 'web' deployment is deleted
+	deployments = []byte(`{"items": [{"metadata": {"name": "coredns", "namespace": "kube-system"}}]}`)
+
+	mx := job.Collect()
+	assert.NotContains(t, mx, "deploy_default_web_replicas_desired")
+	assert.True(t, chart.Obsolete)
+	assert.False(t, job.Charts().Get("deploy_kube-system_coredns_replicas").Obsolete)
+}
+
+func TestK8sState_Collect_NamespacesFilter(t *testing.T) {
+	ts := newTestAPIServer(t, &testDeployments)
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	job.NamespacesFilter = "!kube-system *"
+	require.True(t, job.Init())
+
+	mx := job.Collect()
+	assert.Contains(t, mx, "ns_default_pods_phase_running")
+	assert.Contains(t, mx, "deploy_default_web_replicas_desired")
+	assert.NotContains(t, mx, "ns_kube-system_pods_phase_running")
+	assert.NotContains(t, mx, "deploy_kube-system_coredns_replicas_desired")
+	assert.NotContains(t, mx, "ds_kube-system_kube-proxy_pods_desired")
+
+	// the nodes and the cluster wide metrics are not filtered
+	assert.Equal(t, int64(100), mx["node_node2_req_cpu"])
+	assert.Equal(t, int64(2), mx["pods_phase_running"])
+}
+
+func TestK8sState_Collect_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.URL = ts.URL
+	require.True(t, job.Init())
+
+	assert.Nil(t, job.Collect())
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := map[string]float64{
+		"2":     2,
+		"500m":  0.5,
+		"1.5":   1.5,
+		"128Mi": 128 << 20,
+		"1Gi":   1 << 30,
+		"1k":    1000,
+		"2G":    2e9,
+		"1e3":   1000,
+		"100n":  100e-9,
+	}
+
+	for s, expected := range tests {
+		v, err := parseQuantity(s)
+		assert.NoErrorf(t, err, "quantity '%s'", s)
+		assert.InDeltaf(t, expected, v, 1e-12, "quantity '%s'", s)
+	}
+
+	_, err := parseQuantity("1Xi")
+	assert.Error(t, err)
+	_, err = parseQuantity("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package k8s_state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var quantitySuffixes = []struct {
+	suffix string
+	mul    float64
+}{
+	// binary suffixes go first, 'Mi' must not be parsed as 'M'
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"Ei", 1 << 60},
+	{"n", 1e-9},
+	{"u", 1e-6},
+	{"m", 1e-3},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+	{"E", 1e18},
+}
+
+// parseQuantity parses the Kubernetes resource quantity ("500m", "2", "128Mi", "1e3").
+func parseQuantity(s string) (float64, error) {
+	q, mul := strings.TrimSpace(s), 1.0
+
+	for _, v := range quantitySuffixes {
+		if strings.HasSuffix(q, v.suffix) {
+			q, mul = strings.TrimSuffix(q, v.suffix), v.mul
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity '%s'", s)
+	}
+
+	return v * mul, nil
+}
+
+// milli returns the quantity in thousandths (CPU in millicores), invalid and missing quantities are zero.
+func (r resourceList) milli(name string) int64 {
+	v, _ := parseQuantity(r[name])
+	return int64(v * 1000)
+}
+
+// value returns the quantity (memory in bytes), invalid and missing quantities are zero.
+func (r resourceList) value(name string) int64 {
+	v, _ := parseQuantity(r[name])
+	return int64(v)
+}
@@ -0,0 +1,64 @@
+package ping
+
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+type (
+	// Charts is an alias for module.Charts
+	Charts = module.Charts
+	// Dims is an alias for module.Dims
+	Dims = module.Dims
+)
+
+var hostCharts = Charts{
+	{
+		ID:    "%s_rtt",
+		Title: "Round Trip Time", Units: "ms", Fam: "%s", Ctx: "ping.rtt",
+		Dims: Dims{
+			{ID: "%s_min_rtt", Name: "min", Div: 1000},
+			{ID: "%s_max_rtt", Name: "max", Div: 1000},
+			{ID: "%s_avg_rtt", Name: "avg", Div: 1000},
+		},
+	},
+	{
+		ID:    "%s_rtt_variation",
+		Title: "Round Trip Time Variation", Units: "ms", Fam: "%s", Ctx: "ping.rtt_variation",
+		Dims: Dims{
+			{ID: "%s_mdev_rtt", Name: "mdev", Div: 1000},
+			{ID: "%s_jitter", Name: "jitter", Div: 1000},
+		},
+	},
+	{
+		ID:    "%s_packet_loss",
+		Title: "Packet Loss", Units: "percentage", Fam: "%s", Ctx: "ping.packet_loss",
+		Dims: Dims{
+			{ID: "%s_packet_loss", Name: "loss", Div: 1000},
+		},
+	},
+	{
+		ID:    "%s_packets",
+		Title: "Packets", Units: "packets", Fam: "%s", Ctx: "ping.packets",
+		Dims: Dims{
+			{ID: "%s_packets_recv", Name: "received"},
+			{ID: "%s_packets_sent", Name: "sent"},
+		},
+	},
+}
+
+func newHostCharts(h *host) *Charts {
+	charts := hostCharts.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, h.id)
+		chart.Fam = fmt.Sprintf(chart.Fam, h.name)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, h.id)
+		}
+	}
+
+	return charts
+}
@@ -0,0 +1,181 @@
+package ping
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+func init() {
+	creator := module.Creator{
+		DisabledByDefault: true,
+		UpdateEvery:       5,
+		Create:            func() module.Module { return New() },
+	}
+
+	module.Register("ping", creator)
+}
+
+const (
+	defaultPackets  = 5
+	defaultInterval = time.Millisecond * 100
+	defaultTimeout  = time.Second
+)
+
+// New creates Ping with default values
+func New() *Ping {
+	return &Ping{
+		Packets:  defaultPackets,
+		Interval: web.Duration{Duration: defaultInterval},
+		Timeout:  web.Duration{Duration: defaultTimeout},
+
+		resolve: resolveIP,
+	}
+}
+
+type host struct {
+	id   string
+	name string
+
+	stats *stats
+	err   error
+}
+
+// Ping ping module
+type Ping struct {
+	module.Base
+
+	Hosts    []string     `yaml:"hosts"`
+	Packets  int          `yaml:"packets"`
+	Interval web.Duration `yaml:"interval"`
+	Timeout  web.Duration `yaml:"timeout"`
+
+	pinger  pinger
+	resolve func(host string) (net.IP, error)
+	hosts   []*host
+}
+
+// Cleanup makes cleanup
+func (Ping) Cleanup() {}
+
+func (p Ping) validateConfig() error {
+	if len(p.Hosts) == 0 {
+		return errors.New("no hosts specified")
+	}
+
+	if p.Packets <= 0 {
+		return errors.New("packets must be positive")
+	}
+
+	return nil
+}
+
+// Init makes initialization
+func (p *Ping) Init() bool {
+	if err := p.validateConfig(); err != nil {
+		p.Error(err)
+		return false
+	}
+
+	if p.pinger == nil {
+		p.pinger = icmpPinger{
+			packets:  p.Packets,
+			interval: p.Interval.Duration,
+			timeout:  p.Timeout.Duration,
+		}
+	}
+
+	for _, name := range p.Hosts {
+		p.hosts = append(p.hosts, &host{id: hostNameReplacer.Replace(name), name: name})
+	}
+
+	p.Debugf("using hosts %v", p.Hosts)
+	p.Debugf("using %d packets, interval %s, timeout %s", p.Packets, p.Interval.Duration, p.Timeout.Duration)
+
+	return true
+}
+
+// Check makes check
+func (p *Ping) Check() bool {
+	return len(p.Collect()) > 0
+}
+
+// Charts creates Charts
+func (p Ping) Charts() *Charts {
+	charts := &Charts{}
+
+	for _, h := range p.hosts {
+		if err := charts.Add(*newHostCharts(h)...); err != nil {
+			p.Errorf("error on creating charts : %v", err)
+			return nil
+		}
+	}
+
+	return charts
+}
+
+// Collect collects metrics
+func (p *Ping) Collect() map[string]int64 {
+	var wg sync.WaitGroup
+
+	for _, h := range p.hosts {
+		wg.Add(1)
+		go func(h *host) {
+			defer wg.Done()
+			h.stats, h.err = p.ping(h.name)
+		}(h)
+	}
+
+	wg.Wait()
+
+	metrics := make(map[string]int64)
+
+	for _, h := range p.hosts {
+		if h.err != nil {
+			p.Errorf("error on pinging %s : %v", h.name, h.err)
+			continue
+		}
+
+		min, avg, max, mdev := h.stats.summary()
+
+		metrics[h.id+"_packets_sent"] = int64(h.stats.sent)
+		metrics[h.id+"_packets_recv"] = int64(h.stats.recv)
+		metrics[h.id+"_packet_loss"] = int64(h.stats.loss() * 1000)
+		metrics[h.id+"_min_rtt"] = min.Nanoseconds() / 1000
+		metrics[h.id+"_avg_rtt"] = avg.Nanoseconds() / 1000
+		metrics[h.id+"_max_rtt"] = max.Nanoseconds() / 1000
+		metrics[h.id+"_mdev_rtt"] = mdev.Nanoseconds() / 1000
+		metrics[h.id+"_jitter"] = h.stats.jitter().Nanoseconds() / 1000
+	}
+
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	return metrics
+}
+
+func (p Ping) ping(name string) (*stats, error) {
+	ip, err := p.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.pinger.ping(ip)
+}
+
+func resolveIP(host string) (net.IP, error) {
+	addr, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return nil, err
+	}
+	return addr.IP, nil
+}
+
+var hostNameReplacer = strings.NewReplacer(".", "_", ":", "_")
@@ -0,0 +1,155 @@
+package ping
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestPing_Init(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1", "localhost"}
+
+	require.True(t, mod.Init())
+	assert.Len(t, mod.hosts, 2)
+	assert.NotNil(t, mod.pinger)
+}
+
+func TestPing_InitNG(t *testing.T) {
+	mod := New()
+	assert.False(t, mod.Init())
+
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.Packets = 0
+	assert.False(t, mod.Init())
+}
+
+func TestPing_Check(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.pinger = &mockPinger{}
+
+	require.True(t, mod.Init())
+	assert.True(t, mod.Check())
+}
+
+func TestPing_CheckNG(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.pinger = &mockPinger{err: errors.New("network is unreachable")}
+
+	require.True(t, mod.Init())
+	assert.False(t, mod.Check())
+}
+
+func TestPing_Charts(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1", "::1"}
+
+	require.True(t, mod.Init())
+	charts := mod.Charts()
+	require.NotNil(t, charts)
+	assert.Len(t, *charts, len(hostCharts)*2)
+	assert.NoError(t, module.CheckCharts(*charts...))
+}
+
+func TestPing_Cleanup(t *testing.T) {
+	New().Cleanup()
+}
+
+func TestPing_Collect(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.pinger = &mockPinger{
+		stats: &stats{
+			sent: 4,
+			recv: 3,
+			rtts: []time.Duration{time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond},
+		},
+	}
+
+	require.True(t, mod.Init())
+
+	expected := map[string]int64{
+		"127_0_0_1_packets_sent": 4,
+		"127_0_0_1_packets_recv": 3,
+		"127_0_0_1_packet_loss":  25000,
+		"127_0_0_1_min_rtt":      1000,
+		"127_0_0_1_avg_rtt":      2000,
+		"127_0_0_1_max_rtt":      3000,
+		"127_0_0_1_mdev_rtt":     816,
+		"127_0_0_1_jitter":       1500,
+	}
+
+	assert.Equal(t, expected, mod.Collect())
+}
+
+func TestPing_CollectLoopback(t *testing.T) {
+	if conn, err := listen(false); err != nil {
+		t.Skipf("can't open ICMP socket : %v", err)
+	} else {
+		_ = conn.Close()
+	}
+
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.Packets = 3
+	mod.Interval.Duration = time.Millisecond * 10
+
+	require.True(t, mod.Init())
+
+	metrics := mod.Collect()
+	require.NotNil(t, metrics)
+
+	assert.Equal(t, int64(3), metrics["127_0_0_1_packets_sent"])
+	assert.Equal(t, int64(3), metrics["127_0_0_1_packets_recv"])
+	assert.Equal(t, int64(0), metrics["127_0_0_1_packet_loss"])
+	assert.True(t, metrics["127_0_0_1_max_rtt"] >= metrics["127_0_0_1_min_rtt"])
+}
+
+func TestPing_CollectUnresolvable(t *testing.T) {
+	mod := New()
+	mod.Hosts = []string{"127.0.0.1"}
+	mod.pinger = &mockPinger{}
+	mod.resolve = func(string) (net.IP, error) { return nil, errors.New("no such host") }
+
+	require.True(t, mod.Init())
+	assert.Nil(t, mod.Collect())
+}
+
+func TestStats(t *testing.T) {
+	s := stats{sent: 2}
+	assert.Equal(t, float64(100), s.loss())
+	assert.Equal(t, time.Duration(0), s.jitter())
+
+	min, avg, max, mdev := s.summary()
+	assert.Zero(t, min+avg+max+mdev)
+
+	s = stats{sent: 3, recv: 3, rtts: []time.Duration{10, 20, 15}}
+	assert.Equal(t, float64(0), s.loss())
+	assert.Equal(t, time.Duration(7), s.jitter())
+}
+
+type mockPinger struct {
+	stats *stats
+	err   error
+}
+
+func (m mockPinger) ping(net.IP) (*stats, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.stats == nil {
+		return &stats{sent: 1, recv: 1, rtts: []time.Duration{time.Millisecond}}, nil
+	}
+	return m.stats, nil
+}
@@ -0,0 +1,230 @@
+package ping
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+
+	// echo data is a send timestamp followed by padding, 56 bytes like in iputils ping
+	echoDataSize = 56
+)
+
+type pinger interface {
+	ping(ip net.IP) (*stats, error)
+}
+
+type icmpPinger struct {
+	packets  int
+	interval time.Duration
+	timeout  time.Duration
+}
+
+type stats struct {
+	sent int
+	recv int
+	rtts []time.Duration
+}
+
+type icmpConn struct {
+	*icmp.PacketConn
+	// unprivileged (datagram) sockets: the kernel sets the echo identifier and filters replies
+	unprivileged bool
+}
+
+// listen opens unprivileged datagram ICMP socket if it is allowed (net.ipv4.ping_group_range),
+// raw socket otherwise.
+func listen(ipv6 bool) (*icmpConn, error) {
+	dgram, raw, addr := "udp4", "ip4:icmp", "0.0.0.0"
+	if ipv6 {
+		dgram, raw, addr = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	if c, err := icmp.ListenPacket(dgram, addr); err == nil {
+		return &icmpConn{PacketConn: c, unprivileged: true}, nil
+	}
+
+	c, err := icmp.ListenPacket(raw, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &icmpConn{PacketConn: c}, nil
+}
+
+func (p icmpPinger) ping(ip net.IP) (*stats, error) {
+	isIPv6 := ip.To4() == nil
+
+	conn, err := listen(isIPv6)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if conn.unprivileged {
+		dst = &net.UDPAddr{IP: ip}
+	}
+
+	id := rand.Intn(math.MaxUint16)
+	deadline := time.Now().Add(time.Duration(p.packets-1)*p.interval + p.timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- p.send(conn, dst, id, isIPv6) }()
+
+	rtts := p.receive(conn, ip, id, isIPv6)
+
+	if err := <-sendErr; err != nil {
+		return nil, err
+	}
+
+	return &stats{sent: p.packets, recv: len(rtts), rtts: rtts}, nil
+}
+
+func (p icmpPinger) send(conn *icmpConn, dst net.Addr, id int, isIPv6 bool) error {
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if isIPv6 {
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+
+	for seq := 0; seq < p.packets; seq++ {
+		if seq > 0 {
+			time.Sleep(p.interval)
+		}
+
+		data := make([]byte, echoDataSize)
+		binary.BigEndian.PutUint64(data, uint64(time.Now().UnixNano()))
+
+		msg := icmp.Message{
+			Type: typ,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: data},
+		}
+		b, err := msg.Marshal(nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.WriteTo(b, dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// receive reads echo replies until all of them are received or the read deadline is exceeded.
+func (p icmpPinger) receive(conn *icmpConn, ip net.IP, id int, isIPv6 bool) []time.Duration {
+	var (
+		rtts  []time.Duration
+		seen  = make(map[int]bool)
+		buf   = make([]byte, 1500)
+		proto = protocolICMP
+	)
+	if isIPv6 {
+		proto = protocolIPv6ICMP
+	}
+
+	for len(seen) < p.packets {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		now := time.Now()
+
+		if !peerIP(peer).Equal(ip) {
+			continue
+		}
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || (msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply) {
+			continue
+		}
+
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || len(echo.Data) < 8 || echo.Seq >= p.packets || seen[echo.Seq] {
+			continue
+		}
+		if !conn.unprivileged && echo.ID != id {
+			continue
+		}
+
+		seen[echo.Seq] = true
+		sent := time.Unix(0, int64(binary.BigEndian.Uint64(echo.Data)))
+		rtts = append(rtts, now.Sub(sent))
+	}
+
+	return rtts
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		return v.IP
+	case *net.IPAddr:
+		return v.IP
+	}
+	return nil
+}
+
+func (s stats) loss() float64 {
+	if s.sent == 0 {
+		return 0
+	}
+	return float64(s.sent-s.recv) / float64(s.sent) * 100
+}
+
+// summary returns min, avg, max and mean deviation (calculated the same way as iputils ping does) of RTTs.
+func (s stats) summary() (min, avg, max, mdev time.Duration) {
+	if len(s.rtts) == 0 {
+		return
+	}
+
+	var sum, sum2 float64
+	min, max = s.rtts[0], s.rtts[0]
+
+	for _, rtt := range s.rtts {
+		if rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		sum += float64(rtt)
+		sum2 += float64(rtt) * float64(rtt)
+	}
+
+	n := float64(len(s.rtts))
+	mean := sum / n
+
+	return min, time.Duration(mean), max, time.Duration(math.Sqrt(math.Max(sum2/n-mean*mean, 0)))
+}
+
+// jitter returns mean absolute difference between consecutive RTTs.
+func (s stats) jitter() time.Duration {
+	if len(s.rtts) < 2 {
+		return 0
+	}
+
+	var sum time.Duration
+	for i := 1; i < len(s.rtts); i++ {
+		d := s.rtts[i] - s.rtts[i-1]
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+
+	return sum / time.Duration(len(s.rtts)-1)
+}
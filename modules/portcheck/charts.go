@@ -11,50 +11,87 @@ type (
 	Charts = module.Charts
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
 )
 
-func chartsTemplate(port int) Charts {
-	fam := fmt.Sprintf("port %d", port)
+func chartsTemplate(p *port, checkResponse bool) Charts {
+	var fam, latencyTitle string
+
+	switch p.network {
+	case "udp":
+		fam = fmt.Sprintf("udp %s:%d", p.host.name, p.number)
+		latencyTitle = "UDP Response Latency"
+	case "unix":
+		fam = fmt.Sprintf("socket %s", p.address)
+		latencyTitle = "Unix Socket Connect Latency"
+	default:
+		fam = fmt.Sprintf("%s:%d", p.host.name, p.number)
+		latencyTitle = "TCP Connect Latency"
+	}
 
-	return Charts{
+	charts := Charts{
 		{
-			ID:    fmt.Sprintf("status_%d", port),
+			ID:    fmt.Sprintf("status_%s", p.id),
 			Title: "Port Check Status", Units: "boolean", Fam: fam, Ctx: "portcheck.status",
 			Dims: Dims{
 				{
-					ID:   fmt.Sprintf("success_%d", port),
+					ID:   fmt.Sprintf("success_%s", p.id),
 					Name: "success",
 				},
 				{
-					ID:   fmt.Sprintf("failed_%d", port),
+					ID:   fmt.Sprintf("failed_%s", p.id),
 					Name: "failed",
 				},
 				{
-					ID:   fmt.Sprintf("timeout_%d", port),
+					ID:   fmt.Sprintf("timeout_%s", p.id),
 					Name: "timeout",
 				},
 			},
 		},
 		{
-			ID:    fmt.Sprintf("instate_%d", port),
+			ID:    fmt.Sprintf("instate_%s", p.id),
 			Title: "Current State Duration", Units: "seconds", Fam: fam, Ctx: "portcheck.instate",
 			Dims: Dims{
 				{
-					ID:   fmt.Sprintf("instate_%d", port),
+					ID:   fmt.Sprintf("instate_%s", p.id),
 					Name: "time",
 				},
 			},
 		},
 		{
-			ID:    fmt.Sprintf("latency_%d", port),
-			Title: "TCP Connect Latency", Units: "ms", Fam: fam, Ctx: "portcheck.latency",
+			ID:    fmt.Sprintf("latency_%s", p.id),
+			Title: latencyTitle, Units: "ms", Fam: fam, Ctx: "portcheck.latency",
 			Dims: Dims{
 				{
-					ID:   fmt.Sprintf("latency_%d", port),
+					ID:   fmt.Sprintf("latency_%s", p.id),
 					Name: "time",
 					Div:  1000000,
 				},
 			},
 		},
 	}
+
+	if checkResponse {
+		_ = charts[0].AddDim(&Dim{ID: fmt.Sprintf("bad_response_%s", p.id), Name: "bad response"})
+	}
+
+	return charts
+}
+
+func summaryChartTemplate(network string, port int) *module.Chart {
+	id := summaryID(network, port)
+	fam := fmt.Sprintf("port %d", port)
+	if network == "udp" {
+		fam = "udp " + fam
+	}
+
+	return &module.Chart{
+		ID:    fmt.Sprintf("hosts_%s", id),
+		Title: "Hosts Port Status", Units: "hosts", Fam: fam, Ctx: "portcheck.hosts", Type: module.Stacked,
+		Dims: Dims{
+			{ID: fmt.Sprintf("up_%s", id), Name: "up"},
+			{ID: fmt.Sprintf("down_%s", id), Name: "down"},
+		},
+	}
 }
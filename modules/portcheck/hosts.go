@@ -0,0 +1,137 @@
+package portcheck
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const maxNetworkHosts = 1 << 16
+
+type host struct {
+	// id is used in chart and dimension IDs
+	id   string
+	name string
+	ip   string
+}
+
+var hostIDReplacer = strings.NewReplacer(".", "_", ":", "_", "/", "_", " ", "")
+
+func newHost(name, ip string) host {
+	return host{id: hostIDReplacer.Replace(name), name: name, ip: ip}
+}
+
+type lookupFunc func(host string) ([]net.IP, error)
+
+// resolveHosts expands CIDR ranges and resolves host names to all their A/AAAA records.
+// Duplicated addresses are skipped. The names that are failed to resolve are skipped too,
+// the returned error is the last resolve error.
+func resolveHosts(hosts []string, lookup lookupFunc) ([]host, error) {
+	var (
+		resolved []host
+		seen     = make(map[string]bool)
+		lastErr  error
+	)
+
+	add := func(h host) {
+		if seen[h.ip] {
+			return
+		}
+		seen[h.ip] = true
+		resolved = append(resolved, h)
+	}
+
+	for _, name := range hosts {
+		if strings.Contains(name, "/") {
+			ips, err := expandCIDR(name)
+			if err != nil {
+				return nil, err
+			}
+			for _, ip := range ips {
+				add(newHost(ip.String(), ip.String()))
+			}
+			continue
+		}
+
+		if ip := net.ParseIP(name); ip != nil {
+			add(newHost(ip.String(), ip.String()))
+			continue
+		}
+
+		ips, err := lookup(name)
+		if err != nil {
+			lastErr = fmt.Errorf("error on resolving '%s' : %v", name, err)
+			continue
+		}
+		if len(ips) == 0 {
+			lastErr = fmt.Errorf("'%s' has no A/AAAA records", name)
+			continue
+		}
+
+		if len(ips) == 1 {
+			add(newHost(name, ips[0].String()))
+			continue
+		}
+		for _, ip := range ips {
+			add(newHost(name+"/"+ip.String(), ip.String()))
+		}
+	}
+
+	return resolved, lastErr
+}
+
+// validateHosts checks the CIDR ranges, the DNS names are resolved later.
+func validateHosts(hosts []string) error {
+	for _, name := range hosts {
+		if !strings.Contains(name, "/") {
+			continue
+		}
+		if _, err := expandCIDR(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandCIDR returns all usable host addresses in the network.
+// Network and broadcast addresses are excluded for IPv4 networks larger than /31.
+func expandCIDR(cidr string) ([]net.IP, error) {
+	ip, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	ones, bits := network.Mask.Size()
+	if bits-ones > 16 {
+		return nil, fmt.Errorf("network '%s' is too large, max hosts per network is %d", cidr, maxNetworkHosts)
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+
+	var ips []net.IP
+	for cur := ip.Mask(network.Mask); network.Contains(cur); cur = nextIP(cur) {
+		ips = append(ips, cur)
+	}
+
+	if ip.To4() != nil && len(ips) > 2 {
+		ips = ips[1 : len(ips)-1]
+	}
+
+	return ips, nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
+}
@@ -1,8 +1,11 @@
 package portcheck
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/web"
@@ -13,7 +16,7 @@ import (
 func init() {
 	creator := module.Creator{
 		DisabledByDefault: true,
-		UpdateEvery:       5,
+		UpdateEvery:       defaultUpdateEvery,
 		Create:            func() module.Module { return New() },
 	}
 
@@ -22,16 +25,28 @@ func init() {
 
 const (
 	defaultHTTPTimeout = time.Second
+	defaultProtocol    = "tcp"
+	defaultWorkers     = 16
+	defaultUpdateEvery = 5
+	// defaultResolveEvery is the 'hosts' DNS names re-resolve interval in seconds
+	defaultResolveEvery = 60
 )
 
+// maxTargets is the max number of ports (hosts * ports and unix sockets) per job.
+const maxTargets = 1 << 12
+
 // New creates PortCheck with default values
 func New() *PortCheck {
 	return &PortCheck{
-		Timeout: web.Duration{Duration: defaultHTTPTimeout},
+		Timeout:  web.Duration{Duration: defaultHTTPTimeout},
+		Protocol: defaultProtocol,
+		Workers:  defaultWorkers,
 
 		task:     make(chan *port),
 		taskDone: make(chan struct{}),
+		lookup:   net.LookupIP,
 		ports:    make([]*port, 0),
+		charts:   &Charts{},
 		metrics:  make(map[string]int64),
 	}
 
@@ -40,17 +55,28 @@ func New() *PortCheck {
 type state string
 
 var (
-	success state = "success"
-	timeout state = "timeout"
-	failed  state = "failed"
+	success     state = "success"
+	timeout     state = "timeout"
+	failed      state = "failed"
+	badResponse state = "bad_response"
 )
 
 type port struct {
-	number      int
+	// id is used in chart and dimension IDs
+	id      string
+	network string
+	address string
+	host    host
+	number  int
+
 	state       state
 	inState     int
 	updateEvery int
 	latency     time.Duration
+
+	// deadline is the end of the current collection, the port is not checked after it
+	deadline time.Time
+	skipped  bool
 }
 
 func (p *port) setState(s state) {
@@ -66,37 +92,73 @@ func (p *port) setState(s state) {
 
 func (p port) stateText() string {
 	switch p.state {
-	case success:
-		return fmt.Sprintf("success_%d", p.number)
-	case timeout:
-		return fmt.Sprintf("timeout_%d", p.number)
-	case failed:
-		return fmt.Sprintf("failed_%d", p.number)
+	case success, timeout, failed, badResponse:
+		return fmt.Sprintf("%s_%s", p.state, p.id)
 	}
 	panic("unknown state")
 }
 
-func newPort(number, updateEvery int) *port {
+func newPort(network string, host host, number, updateEvery int) *port {
+	id := fmt.Sprintf("%s_%d", host.id, number)
+	if network == "udp" {
+		id = "udp_" + id
+	}
+
 	return &port{
+		id:          id,
+		network:     network,
+		address:     net.JoinHostPort(host.ip, fmt.Sprintf("%d", number)),
+		host:        host,
 		number:      number,
 		updateEvery: updateEvery,
 	}
 }
 
+// summaryID returns ID of the per port summary (all hosts) chart.
+func summaryID(network string, number int) string {
+	if network == "udp" {
+		return fmt.Sprintf("udp_%d", number)
+	}
+	return fmt.Sprintf("%d", number)
+}
+
+func newUnixSocket(path string, updateEvery int) *port {
+	return &port{
+		id:          "unix" + socketPathReplacer.Replace(path),
+		network:     "unix",
+		address:     path,
+		updateEvery: updateEvery,
+	}
+}
+
+var socketPathReplacer = strings.NewReplacer("/", "_", ".", "_", " ", "_")
+
 // PortCheck portcheck module
 type PortCheck struct {
 	module.Base
 
-	Host        string       `yaml:"host" validate:"required"`
-	Ports       []int        `yaml:"ports" validate:"required,gte=1"`
-	Timeout     web.Duration `yaml:"timeout"`
-	UpdateEvery int          `yaml:"update_every"`
+	Host         string       `yaml:"host"`
+	Hosts        []string     `yaml:"hosts"`
+	Ports        []int        `yaml:"ports"`
+	Protocol     string       `yaml:"protocol"`
+	UnixSockets  []string     `yaml:"unix_sockets"`
+	Send         string       `yaml:"send"`
+	Expect       string       `yaml:"expect"`
+	Timeout      web.Duration `yaml:"timeout"`
+	Workers      int          `yaml:"workers"`
+	ResolveEvery int          `yaml:"resolve_every"`
+	UpdateEvery  int          `yaml:"update_every"`
 
 	task     chan *port
 	taskDone chan struct{}
+	lookup   lookupFunc
 
-	ports   []*port
-	workers []*worker
+	// resolvedAt is the time the hosts were resolved last time, only the 'hosts' jobs are re-resolved
+	resolvedAt time.Time
+	hosts      []host
+	ports      []*port
+	workers    []*worker
+	charts     *Charts
 
 	metrics map[string]int64
 }
@@ -110,22 +172,171 @@ func (tc *PortCheck) Cleanup() {
 	tc.workers = make([]*worker, 0)
 }
 
+func (tc PortCheck) validateConfig() error {
+	if len(tc.Ports) == 0 && len(tc.UnixSockets) == 0 {
+		return errors.New("neither ports nor unix sockets are set")
+	}
+
+	if len(tc.Ports) > 0 && tc.Host == "" && len(tc.Hosts) == 0 {
+		return errors.New("neither host nor hosts are set")
+	}
+
+	if tc.Protocol != "tcp" && tc.Protocol != "udp" {
+		return fmt.Errorf("unsupported protocol : %s", tc.Protocol)
+	}
+
+	return validateHosts(tc.Hosts)
+}
+
+// isSingleHost returns whether only 'host' is set. The host name is dialed on every check
+// and the port numbers are used as IDs the same way as before the 'hosts' option was added.
+func (tc PortCheck) isSingleHost() bool {
+	return tc.Host != "" && len(tc.Hosts) == 0
+}
+
+func (tc PortCheck) hostNames() []string {
+	if tc.Host == "" {
+		return tc.Hosts
+	}
+	return append([]string{tc.Host}, tc.Hosts...)
+}
+
 // Init makes initialization
 func (tc *PortCheck) Init() bool {
+	if err := tc.validateConfig(); err != nil {
+		tc.Error(err)
+		return false
+	}
+
+	if tc.ResolveEvery <= 0 {
+		tc.ResolveEvery = defaultResolveEvery
+	}
+
 	sort.Ints(tc.Ports)
 
-	for _, p := range tc.Ports {
-		tc.ports = append(tc.ports, newPort(p, tc.UpdateEvery))
-		tc.workers = append(tc.workers, newWorker(tc.Host, tc.Timeout.Duration, tc.task, tc.taskDone))
+	if tc.isSingleHost() {
+		tc.hosts = []host{newHost(tc.Host, tc.Host)}
+	} else {
+		for _, p := range tc.Ports {
+			_ = tc.charts.Add(summaryChartTemplate(tc.Protocol, p))
+		}
+
+		hosts, err := resolveHosts(tc.hostNames(), tc.lookup)
+		if err != nil {
+			// the names are re-resolved every 'resolve_every' seconds
+			tc.Warning(err)
+		}
+		if n := tc.numTargets(hosts); n > maxTargets {
+			tc.Errorf("too many ports to check (%d), max is %d", n, maxTargets)
+			return false
+		}
+		tc.hosts = hosts
+		tc.resolvedAt = time.Now()
 	}
 
-	tc.Debugf("using host %s", tc.Host)
-	tc.Debugf("using ports %v", tc.Ports)
+	tc.ports = tc.newPorts(tc.hosts)
+
+	probe := probe{
+		timeout: tc.Timeout.Duration,
+		send:    []byte(tc.Send),
+		expect:  []byte(tc.Expect),
+	}
+
+	workers := tc.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	// the number of ports can change only if the hosts are re-resolved
+	if len(tc.Hosts) == 0 && workers > len(tc.ports) {
+		workers = len(tc.ports)
+	}
+
+	for i := 0; i < workers; i++ {
+		tc.workers = append(tc.workers, newWorker(probe, tc.task, tc.taskDone))
+	}
+
+	tc.Debugf("using %d hosts %v", len(tc.hosts), tc.hostNames())
+	tc.Debugf("using %s ports %v", tc.Protocol, tc.Ports)
+	tc.Debugf("using unix sockets %v", tc.UnixSockets)
 	tc.Debugf("using HTTP timeout: %s", tc.Timeout.Duration)
+	tc.Debugf("using %d workers", len(tc.workers))
+	if tc.Expect != "" {
+		tc.Debugf("using expected response '%s'", tc.Expect)
+	}
 
 	return true
 }
 
+func (tc PortCheck) numTargets(hosts []host) int {
+	return len(hosts)*len(tc.Ports) + len(tc.UnixSockets)
+}
+
+// newPorts returns the ports of the hosts and the unix sockets, the existing ports are reused to keep their state.
+// The charts of the new ports are added, the charts of the gone ports are removed.
+func (tc *PortCheck) newPorts(hosts []host) []*port {
+	existing := make(map[string]*port)
+	for _, p := range tc.ports {
+		existing[p.id] = p
+	}
+
+	var ports []*port
+	for _, h := range hosts {
+		for _, number := range tc.Ports {
+			p := newPort(tc.Protocol, h, number, tc.UpdateEvery)
+			if tc.isSingleHost() {
+				p.id = summaryID(tc.Protocol, number)
+			}
+			ports = append(ports, p)
+		}
+	}
+	for _, path := range tc.UnixSockets {
+		ports = append(ports, newUnixSocket(path, tc.UpdateEvery))
+	}
+
+	for i, p := range ports {
+		if e, ok := existing[p.id]; ok {
+			ports[i] = e
+			delete(existing, p.id)
+			continue
+		}
+		_ = tc.charts.Add(chartsTemplate(p, tc.Expect != "")...)
+	}
+
+	for _, p := range existing {
+		for _, chart := range chartsTemplate(p, tc.Expect != "") {
+			chart = tc.charts.Get(chart.ID)
+			if chart == nil {
+				continue
+			}
+			chart.Obsolete = true
+			chart.MarkNotCreated()
+			chart.MarkRemove()
+		}
+	}
+
+	return ports
+}
+
+// reResolve resolves the 'hosts' again, the DNS records can change.
+func (tc *PortCheck) reResolve() {
+	if tc.isSingleHost() || time.Since(tc.resolvedAt) < time.Duration(tc.ResolveEvery)*time.Second {
+		return
+	}
+	tc.resolvedAt = time.Now()
+
+	hosts, err := resolveHosts(tc.hostNames(), tc.lookup)
+	if err != nil {
+		tc.Warning(err)
+	}
+	if n := tc.numTargets(hosts); n > maxTargets {
+		tc.Warningf("too many ports to check (%d), max is %d, the hosts are not updated", n, maxTargets)
+		return
+	}
+
+	tc.hosts = hosts
+	tc.ports = tc.newPorts(hosts)
+}
+
 // Check makes check
 func (PortCheck) Check() bool {
 	return true
@@ -133,34 +344,82 @@ func (PortCheck) Check() bool {
 
 // Charts creates    charts
 func (tc PortCheck) Charts() *Charts {
-	var charts module.Charts
-
-	for _, p := range tc.Ports {
-		_ = charts.Add(chartsTemplate(p)...)
-	}
-
-	return &charts
+	return tc.charts
 }
 
 // Collect collects metrics
 func (tc *PortCheck) Collect() map[string]int64 {
-	for _, p := range tc.ports {
-		tc.task <- p
+	tc.reResolve()
+
+	updateEvery := tc.UpdateEvery
+	if updateEvery <= 0 {
+		updateEvery = defaultUpdateEvery
 	}
+	deadline := time.Now().Add(time.Duration(updateEvery) * time.Second)
+
+	ports := tc.ports
 
-	for i := 0; i < len(tc.ports); i++ {
+	// there can be less workers than ports, tasks are sent in a separate goroutine
+	// to not block on the task channel while workers are waiting on the taskDone channel
+	go func() {
+		for _, p := range ports {
+			p.deadline = deadline
+			tc.task <- p
+		}
+	}()
+
+	for i := 0; i < len(ports); i++ {
 		<-tc.taskDone
 	}
 
-	for _, p := range tc.ports {
-		tc.metrics[fmt.Sprintf("success_%d", p.number)] = 0
-		tc.metrics[fmt.Sprintf("failed_%d", p.number)] = 0
-		tc.metrics[fmt.Sprintf("timeout_%d", p.number)] = 0
+	var skipped int
+	for _, p := range ports {
+		if p.skipped {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		tc.Warningf("%d of %d ports are not checked within update interval (%ds), marked as timeout", skipped, len(ports), updateEvery)
+	}
+
+	tc.metrics = make(map[string]int64)
+
+	if !tc.isSingleHost() {
+		tc.collectSummary()
+	}
+
+	for _, p := range ports {
+		tc.metrics["success_"+p.id] = 0
+		tc.metrics["failed_"+p.id] = 0
+		tc.metrics["timeout_"+p.id] = 0
+		if tc.Expect != "" {
+			tc.metrics["bad_response_"+p.id] = 0
+		}
 
 		tc.metrics[p.stateText()] = 1
-		tc.metrics[fmt.Sprintf("instate_%d", p.number)] = int64(p.inState)
-		tc.metrics[fmt.Sprintf("latency_%d", p.number)] = int64(p.latency)
+		tc.metrics["instate_"+p.id] = int64(p.inState)
+		tc.metrics["latency_"+p.id] = int64(p.latency)
 	}
 
 	return tc.metrics
 }
+
+func (tc *PortCheck) collectSummary() {
+	for _, p := range tc.Ports {
+		id := summaryID(tc.Protocol, p)
+		tc.metrics["up_"+id] = 0
+		tc.metrics["down_"+id] = 0
+	}
+
+	for _, p := range tc.ports {
+		if p.network == "unix" {
+			continue
+		}
+		id := summaryID(p.network, p.number)
+		if p.state == success {
+			tc.metrics["up_"+id]++
+		} else {
+			tc.metrics["down_"+id]++
+		}
+	}
+}
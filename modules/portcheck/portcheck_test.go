@@ -1,7 +1,11 @@
 package portcheck
 
 import (
+	"errors"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -26,6 +30,24 @@ func TestPortCheck_Init(t *testing.T) {
 	assert.Len(t, mod.workers, 2)
 }
 
+func TestPortCheck_InitNG(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	assert.False(t, mod.Init())
+
+	mod.Ports = []int{38001}
+	assert.False(t, mod.Init())
+
+	mod.Host = "127.0.0.1"
+	mod.Protocol = "sctp"
+	assert.False(t, mod.Init())
+
+	mod.Protocol = "tcp"
+	mod.Hosts = []string{"10.0.0.0/16"}
+	assert.False(t, mod.Init())
+}
+
 func TestPortCheck_Check(t *testing.T) {
 	mod := New()
 	defer mod.Cleanup()
@@ -133,14 +155,260 @@ func TestPortCheck_ServerNG(t *testing.T) {
 
 }
 
+func TestPortCheck_Expect(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Host = "127.0.0.1"
+	mod.Ports = []int{38001, 38002}
+	mod.Send = "PING\r\n"
+	mod.Expect = "+PONG"
+
+	require.True(t, mod.Init())
+
+	ok := tcpServer{addr: ":38001", response: "+PONG\r\n"}
+	require.NoError(t, ok.listen())
+	defer ok.close()
+
+	bad := tcpServer{addr: ":38002", response: "-ERR unknown command\r\n"}
+	require.NoError(t, bad.listen())
+	defer bad.close()
+
+	rv := mod.Collect()
+	require.NotNil(t, rv)
+
+	assert.Equal(t, int64(1), rv["success_38001"])
+	assert.Equal(t, int64(0), rv["bad_response_38001"])
+	assert.Equal(t, int64(0), rv["success_38002"])
+	assert.Equal(t, int64(1), rv["bad_response_38002"])
+	assert.NoError(t, module.CheckCharts(*mod.Charts()...))
+}
+
+func TestPortCheck_MultipleHosts(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Hosts = []string{"127.0.0.0/30", "localhost"}
+	mod.Ports = []int{38001, 38002}
+	mod.Workers = 2
+	mod.lookup = func(string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("127.0.0.3"), net.ParseIP("127.0.0.1")}, nil
+	}
+
+	require.True(t, mod.Init())
+	assert.Len(t, mod.hosts, 3)
+	assert.Len(t, mod.ports, 6)
+	assert.Len(t, mod.workers, 2)
+	assert.NoError(t, module.CheckCharts(*mod.Charts()...))
+
+	ts := tcpServer{addr: "127.0.0.1:38001"}
+	require.NoError(t, ts.listen())
+	defer ts.close()
+
+	rv := mod.Collect()
+	require.NotNil(t, rv)
+
+	assert.Equal(t, int64(1), rv["up_38001"])
+	assert.Equal(t, int64(2), rv["down_38001"])
+	assert.Equal(t, int64(0), rv["up_38002"])
+	assert.Equal(t, int64(3), rv["down_38002"])
+	assert.Equal(t, int64(1), rv["success_127_0_0_1_38001"])
+	assert.Equal(t, int64(1), rv["failed_localhost_127_0_0_3_38001"])
+}
+
+func TestWorker_DeadlineExceeded(t *testing.T) {
+	w := &worker{taskDone: make(chan struct{}, 1)}
+
+	p := newPort("tcp", newHost("127.0.0.1", "127.0.0.1"), 38001, 5)
+	p.deadline = time.Now().Add(-time.Second)
+	w.doWork(p)
+
+	assert.True(t, p.skipped)
+	assert.Equal(t, timeout, p.state)
+	assert.Equal(t, time.Duration(0), p.latency)
+}
+
+func TestPortCheck_SingleHostName(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Host = "localhost"
+	mod.Ports = []int{38001}
+	mod.lookup = func(string) ([]net.IP, error) {
+		t.Error("single host name must not be resolved")
+		return nil, errors.New("no such host")
+	}
+
+	require.True(t, mod.Init())
+	assert.Equal(t, "localhost:38001", mod.ports[0].address)
+	assert.Equal(t, "38001", mod.ports[0].id)
+	assert.Nil(t, mod.Charts().Get("hosts_38001"))
+}
+
+func TestPortCheck_ReResolve(t *testing.T) {
+	mod := New()
+	defer mod.Cleanup()
+
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	var lookupErr error
+
+	mod.Hosts = []string{"db"}
+	mod.Ports = []int{38001}
+	mod.lookup = func(string) ([]net.IP, error) { return ips, lookupErr }
+
+	// DNS failure doesn't fail the job, the names are re-resolved later
+	lookupErr = errors.New("no such host")
+	require.True(t, mod.Init())
+	assert.Len(t, mod.ports, 0)
+	assert.NoError(t, module.CheckCharts(*mod.Charts()...))
+
+	lookupErr = nil
+	mod.resolvedAt = time.Time{}
+	rv := mod.Collect()
+	require.Len(t, mod.ports, 1)
+	assert.Contains(t, rv, "success_db_38001")
+
+	// the record has changed
+	ips = []net.IP{net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.3")}
+	mod.resolvedAt = time.Time{}
+	rv = mod.Collect()
+	require.Len(t, mod.ports, 2)
+	assert.NotContains(t, rv, "success_db_38001")
+	assert.Contains(t, rv, "success_db_127_0_0_2_38001")
+	assert.True(t, mod.Charts().Get("status_db_38001").Obsolete)
+	assert.Equal(t, int64(2), rv["down_38001"])
+}
+
+func TestResolveHosts(t *testing.T) {
+	lookup := func(name string) ([]net.IP, error) {
+		if name == "single" {
+			return []net.IP{net.ParseIP("10.0.0.1")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	hosts, err := resolveHosts([]string{"10.0.0.0/29", "single", "::1"}, lookup)
+	require.NoError(t, err)
+
+	var names []string
+	for _, h := range hosts {
+		names = append(names, h.name)
+	}
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6", "::1"}, names)
+
+	_, err = resolveHosts([]string{"unknown"}, lookup)
+	assert.Error(t, err)
+
+	_, err = resolveHosts([]string{"10.0.0.0/8"}, lookup)
+	assert.Error(t, err)
+}
+
+func TestPortCheck_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:38003")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo([]byte("pong"), addr)
+		}
+	}()
+
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Host = "127.0.0.1"
+	mod.Ports = []int{38003, 38004}
+	mod.Protocol = "udp"
+	mod.Send = "ping"
+	mod.Expect = "pong"
+
+	require.True(t, mod.Init())
+
+	rv := mod.Collect()
+	require.NotNil(t, rv)
+
+	assert.Equal(t, int64(1), rv["success_udp_38003"])
+	assert.Equal(t, int64(0), rv["success_udp_38004"])
+}
+
+func TestPortCheck_UDP_NoResponse(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:38003")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.Host = "127.0.0.1"
+	mod.Ports = []int{38003}
+	mod.Protocol = "udp"
+	mod.Timeout.Duration = 100 * time.Millisecond
+
+	require.True(t, mod.Init())
+
+	rv := mod.Collect()
+	require.NotNil(t, rv)
+
+	assert.Equal(t, int64(1), rv["success_udp_38003"])
+	assert.Equal(t, int64(0), rv["latency_udp_38003"])
+}
+
+func TestPortCheck_UnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "portcheck")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.sock")
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	mod := New()
+	defer mod.Cleanup()
+
+	mod.UnixSockets = []string{path, filepath.Join(dir, "missing.sock")}
+
+	require.True(t, mod.Init())
+
+	rv := mod.Collect()
+	require.NotNil(t, rv)
+
+	require.Len(t, mod.ports, 2)
+	assert.Equal(t, success, mod.ports[0].state)
+	assert.Equal(t, failed, mod.ports[1].state)
+	assert.NoError(t, module.CheckCharts(*mod.Charts()...))
+}
+
 type tcpServer struct {
-	addr   string
-	server net.Listener
+	addr     string
+	response string
+	server   net.Listener
 }
 
 func (t *tcpServer) listen() (err error) {
 	t.server, err = net.Listen("tcp", t.addr)
-	return err
+	if err != nil || t.response == "" {
+		return err
+	}
+
+	go func() {
+		for {
+			conn, err := t.server.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(t.response))
+			_ = conn.Close()
+		}
+	}()
+
+	return nil
 }
 
 func (t *tcpServer) close() error {
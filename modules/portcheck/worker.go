@@ -1,25 +1,34 @@
 package portcheck
 
 import (
-	"fmt"
+	"bytes"
 	"net"
 	"time"
 )
 
+const maxResponseSize = 4096
+
+// probe describes how a port is checked.
+// If send is set it is written right after the connection is established,
+// if expect is set the response must contain it.
+type probe struct {
+	timeout time.Duration
+	send    []byte
+	expect  []byte
+}
+
 type worker struct {
 	task     chan *port
 	taskDone chan struct{}
 
-	host        string
-	dialTimeout time.Duration
+	probe probe
 }
 
-func newWorker(host string, dialTimeout time.Duration, task chan *port, taskDone chan struct{}) *worker {
+func newWorker(probe probe, task chan *port, taskDone chan struct{}) *worker {
 	w := &worker{
-		task:        task,
-		taskDone:    taskDone,
-		host:        host,
-		dialTimeout: dialTimeout,
+		task:     task,
+		taskDone: taskDone,
+		probe:    probe,
 	}
 
 	go w.workLoop()
@@ -34,24 +43,122 @@ func (w *worker) workLoop() {
 }
 
 func (w *worker) doWork(port *port) {
+	// the collection takes longer than the update interval, the rest of the ports are not checked
+	port.skipped = !port.deadline.IsZero() && time.Now().After(port.deadline)
+
+	switch {
+	case port.skipped:
+		port.latency = 0
+		port.setState(timeout)
+	case port.network == "udp":
+		port.setState(w.checkUDP(port))
+	default:
+		port.setState(w.checkStream(port))
+	}
+
+	w.taskDone <- struct{}{}
+}
+
+// checkStream checks connection oriented (tcp and unix) ports.
+func (w *worker) checkStream(port *port) state {
 	t := time.Now()
-	c, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", w.host, port.number), w.dialTimeout)
+	c, err := net.DialTimeout(port.network, port.address, w.probe.timeout)
 	port.latency = time.Since(t)
 
-	if err == nil {
-		port.setState(success)
-		_ = c.Close()
+	if err != nil {
+		return parseErr(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if len(w.probe.send) == 0 && len(w.probe.expect) == 0 {
+		return success
+	}
+
+	_ = c.SetDeadline(time.Now().Add(w.probe.timeout))
+
+	if len(w.probe.send) > 0 {
+		if _, err := c.Write(w.probe.send); err != nil {
+			return badResponse
+		}
+	}
+
+	if len(w.probe.expect) == 0 {
+		return success
+	}
+
+	if !w.readExpected(c) {
+		return badResponse
+	}
+
+	return success
+}
+
+// checkUDP checks udp port. There is no handshake in udp, so the port is considered closed
+// only if the host answers with ICMP port unreachable (connection refused on read).
+// If no response is expected silence is treated as success, the latency is reported as 0.
+func (w *worker) checkUDP(port *port) state {
+	c, err := net.DialTimeout(port.network, port.address, w.probe.timeout)
+	if err != nil {
+		port.latency = 0
+		return parseErr(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	_ = c.SetDeadline(time.Now().Add(w.probe.timeout))
+
+	t := time.Now()
+	if _, err := c.Write(w.probe.send); err != nil {
+		port.latency = 0
+		return parseErr(err)
 	}
 
+	buf := make([]byte, maxResponseSize)
+	n, err := c.Read(buf)
+	port.latency = time.Since(t)
+
 	if err != nil {
-		v, ok := err.(interface{ Timeout() bool })
+		s := parseErr(err)
+		if s == timeout && len(w.probe.expect) == 0 {
+			// no response, the latency is unknown
+			port.latency = 0
+			return success
+		}
+		return s
+	}
+
+	if len(w.probe.expect) > 0 && !bytes.Contains(buf[:n], w.probe.expect) {
+		return badResponse
+	}
 
-		if ok && v.Timeout() {
-			port.setState(timeout)
-		} else {
-			port.setState(failed)
+	return success
+}
+
+// readExpected reads from the connection until the expected response is found,
+// the buffer is full or the deadline is exceeded.
+func (w *worker) readExpected(c net.Conn) bool {
+	buf := make([]byte, 0, maxResponseSize)
+
+	for len(buf) < cap(buf) {
+		n, err := c.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+
+		if bytes.Contains(buf, w.probe.expect) {
+			return true
+		}
+		if err != nil {
+			return false
 		}
 	}
 
-	w.taskDone <- struct{}{}
+	return false
+}
+
+func parseErr(err error) state {
+	v, ok := err.(interface{ Timeout() bool })
+
+	if ok && v.Timeout() {
+		return timeout
+	}
+
+	return failed
 }
@@ -13,6 +13,9 @@ import (
 const (
 	overviewURI = "/api/overview"
 	nodeURI     = "/api/node/"
+	nodesURI    = "/api/nodes"
+	vhostsURI   = "/api/vhosts"
+	queuesURI   = "/api/queues"
 )
 
 // https://www.rabbitmq.com/monitoring.html
@@ -61,6 +64,34 @@ type node struct {
 	RunQueue    int `json:"run_queue" stm:"run_queue"`
 }
 
+// clusterNode is an item of the /api/nodes response.
+type clusterNode struct {
+	node
+	Name          string   `json:"name"`
+	Running       bool     `json:"running"`
+	Partitions    []string `json:"partitions"`
+	MemAlarm      bool     `json:"mem_alarm"`
+	DiskFreeAlarm bool     `json:"disk_free_alarm"`
+}
+
+// vhost is an item of the /api/vhosts response.
+type vhost struct {
+	Name                   string       `json:"name"`
+	MessagesReady          int          `json:"messages_ready" stm:"messages_ready"`
+	MessagesUnacknowledged int          `json:"messages_unacknowledged" stm:"messages_unacknowledged"`
+	MessageStats           messageStats `json:"message_stats" stm:"message_stats"`
+}
+
+// queue is an item of the /api/queues response.
+type queue struct {
+	Name                   string       `json:"name"`
+	Vhost                  string       `json:"vhost"`
+	MessagesReady          int          `json:"messages_ready" stm:"messages_ready"`
+	MessagesUnacknowledged int          `json:"messages_unacknowledged" stm:"messages_unacknowledged"`
+	Consumers              int          `json:"consumers" stm:"consumers"`
+	MessageStats           messageStats `json:"message_stats" stm:"message_stats"`
+}
+
 type apiClient struct {
 	req        web.Request
 	httpClient *http.Client
@@ -117,6 +148,46 @@ func (a apiClient) getNodeStats() (node, error) {
 	return node, nil
 }
 
+func (a apiClient) getClusterNodes() ([]clusterNode, error) {
+	var nodes []clusterNode
+	err := a.getJSON(nodesURI, &nodes)
+	return nodes, err
+}
+
+func (a apiClient) getVhosts() ([]vhost, error) {
+	var vhosts []vhost
+	err := a.getJSON(vhostsURI, &vhosts)
+	return vhosts, err
+}
+
+func (a apiClient) getQueues() ([]queue, error) {
+	var queues []queue
+	err := a.getJSON(queuesURI, &queues)
+	return queues, err
+}
+
+func (a apiClient) getJSON(uri string, dst interface{}) error {
+	req, err := a.createRequest(uri)
+
+	if err != nil {
+		return fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := a.doRequestOK(req)
+
+	defer closeBody(resp)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("erorr on decode request to %s : %s", req.URL, err)
+	}
+
+	return nil
+}
+
 func (a apiClient) doRequest(req *http.Request) (*http.Response, error) {
 	return a.httpClient.Do(req)
 }
@@ -1,6 +1,11 @@
 package rabbitmq
 
-import "github.com/netdata/go-orchestrator/module"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go-orchestrator/module"
+)
 
 type (
 	// Charts is an alias for module.Charts
@@ -119,3 +124,170 @@ var charts = Charts{
 		},
 	},
 }
+
+// vhostCharts are the per virtual host charts, '%s' is the vhost id.
+var vhostCharts = Charts{
+	{
+		ID:    "vhost_%s_queued_messages",
+		Title: "Virtual Host %s Queued Messages",
+		Units: "messages",
+		Fam:   "vhost %s",
+		Ctx:   "rabbitmq.vhost_queued_messages",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "vhost_%s_messages_ready", Name: "ready"},
+			{ID: "vhost_%s_messages_unacknowledged", Name: "unacknowledged"},
+		},
+	},
+	{
+		ID:    "vhost_%s_message_rates",
+		Title: "Virtual Host %s Message Rates",
+		Units: "messages/s",
+		Fam:   "vhost %s",
+		Ctx:   "rabbitmq.vhost_message_rates",
+		Dims: Dims{
+			{ID: "vhost_%s_message_stats_ack", Name: "ack", Algo: module.Incremental},
+			{ID: "vhost_%s_message_stats_publish", Name: "publish", Algo: module.Incremental},
+			{ID: "vhost_%s_message_stats_deliver_get", Name: "deliver get", Algo: module.Incremental},
+			{ID: "vhost_%s_message_stats_redeliver", Name: "redeliver", Algo: module.Incremental},
+			{ID: "vhost_%s_message_stats_return_unroutable", Name: "return unroutable", Algo: module.Incremental},
+		},
+	},
+}
+
+// queueCharts are the per queue charts, '%s' is the queue id.
+var queueCharts = Charts{
+	{
+		ID:    "queue_%s_queued_messages",
+		Title: "Queue %s Queued Messages",
+		Units: "messages",
+		Fam:   "vhost %s",
+		Ctx:   "rabbitmq.queue_queued_messages",
+		Type:  module.Stacked,
+		Dims: Dims{
+			{ID: "queue_%s_messages_ready", Name: "ready"},
+			{ID: "queue_%s_messages_unacknowledged", Name: "unacknowledged"},
+		},
+	},
+	{
+		ID:    "queue_%s_message_rates",
+		Title: "Queue %s Message Rates",
+		Units: "messages/s",
+		Fam:   "vhost %s",
+		Ctx:   "rabbitmq.queue_message_rates",
+		Dims: Dims{
+			{ID: "queue_%s_message_stats_ack", Name: "ack", Algo: module.Incremental},
+			{ID: "queue_%s_message_stats_publish", Name: "publish", Algo: module.Incremental},
+			{ID: "queue_%s_message_stats_deliver_get", Name: "deliver get", Algo: module.Incremental},
+			{ID: "queue_%s_message_stats_redeliver", Name: "redeliver", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "queue_%s_consumers",
+		Title: "Queue %s Consumers",
+		Units: "consumers",
+		Fam:   "vhost %s",
+		Ctx:   "rabbitmq.queue_consumers",
+		Dims: Dims{
+			{ID: "queue_%s_consumers", Name: "consumers"},
+		},
+	},
+}
+
+// nodeCharts are the per cluster node charts, '%s' is the node id.
+var nodeCharts = Charts{
+	{
+		ID:    "node_%s_status",
+		Title: "Node %s Status",
+		Units: "status",
+		Fam:   "cluster",
+		Ctx:   "rabbitmq.node_status",
+		Dims: Dims{
+			{ID: "node_%s_running", Name: "running"},
+			{ID: "node_%s_mem_alarm", Name: "memory alarm"},
+			{ID: "node_%s_disk_free_alarm", Name: "disk free alarm"},
+		},
+	},
+	{
+		ID:    "node_%s_partitions",
+		Title: "Node %s Network Partitions",
+		Units: "partitions",
+		Fam:   "cluster",
+		Ctx:   "rabbitmq.node_partitions",
+		Dims: Dims{
+			{ID: "node_%s_partitions", Name: "partitions"},
+		},
+	},
+	{
+		ID:    "node_%s_memory",
+		Title: "Node %s Memory",
+		Units: "MiB",
+		Fam:   "cluster",
+		Ctx:   "rabbitmq.node_memory",
+		Dims: Dims{
+			{ID: "node_%s_mem_used", Name: "used", Div: 1024 << 10},
+		},
+	},
+}
+
+// raftCharts are the quorum queues Raft charts, they are available only in the prometheus mode.
+var raftCharts = Charts{
+	{
+		ID:    "raft_log_commits",
+		Title: "Raft Log Commits",
+		Units: "entries/s",
+		Fam:   "raft",
+		Ctx:   "rabbitmq.raft_log_commits",
+		Dims: Dims{
+			{ID: "raft_log_commit_index", Name: "committed", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "raft_entry_commit_latency",
+		Title: "Raft Entry Commit Latency",
+		Units: "milliseconds",
+		Fam:   "raft",
+		Ctx:   "rabbitmq.raft_entry_commit_latency",
+		Dims: Dims{
+			{ID: "raft_entry_commit_latency", Name: "latency", Div: 1000},
+		},
+	},
+}
+
+// the rabbitmq_prometheus plugin exposes only the local node, it has no network partitions metric
+var prometheusNodeCharts = Charts{nodeCharts[0], nodeCharts[2]}
+
+// the rabbitmq_prometheus plugin per object queue metrics have no message rates
+var prometheusQueueCharts = Charts{queueCharts[0], queueCharts[2]}
+
+// newPrometheusCharts creates the overview charts without the dimensions the rabbitmq_prometheus plugin has no metrics for.
+func newPrometheusCharts() *Charts {
+	c := charts.Copy()
+
+	_ = c.Get("message_rates").RemoveDim("message_stats_publish_in")
+	_ = c.Get("message_rates").RemoveDim("message_stats_publish_out")
+	_ = c.Get("global_counts").RemoveDim("object_totals_exchanges")
+	_ = c.Add(*raftCharts.Copy()...)
+
+	return c
+}
+
+// newCharts creates the object charts from the template, the object id replaces '%s' in the ids,
+// the object name replaces it in the titles and the family replaces it in the families.
+func newCharts(tmpl Charts, id, name, fam string) *Charts {
+	charts := tmpl.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Title = fmt.Sprintf(chart.Title, name)
+		if strings.Contains(chart.Fam, "%s") {
+			chart.Fam = fmt.Sprintf(chart.Fam, fam)
+		}
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	return charts
+}
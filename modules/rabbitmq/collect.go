@@ -0,0 +1,189 @@
+package rabbitmq
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+var nameReplacer = strings.NewReplacer(".", "_", " ", "_", "/", "_", "@", "_")
+
+func (r *Rabbitmq) collect() (map[string]int64, error) {
+	if r.prom != nil {
+		return r.collectPrometheus()
+	}
+
+	overview, err := r.apiClient.getOverview()
+	if err != nil {
+		return nil, err
+	}
+	node, err := r.apiClient.getNodeStats()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := r.apiClient.getClusterNodes()
+	if err != nil {
+		return nil, err
+	}
+	vhosts, err := r.apiClient.getVhosts()
+	if err != nil {
+		return nil, err
+	}
+	queues, err := r.apiClient.getQueues()
+	if err != nil {
+		return nil, err
+	}
+
+	mx := stm.ToMap(overview, node)
+
+	r.collectClusterNodes(mx, nodes, nodeCharts)
+	r.collectVhosts(mx, vhosts)
+	r.collectQueues(mx, queues, queueCharts)
+
+	return mx, nil
+}
+
+// collectClusterNodes collects the nodes metrics, tmpl is the node charts template, it depends on the mode.
+func (r *Rabbitmq) collectClusterNodes(mx map[string]int64, nodes []clusterNode, tmpl Charts) {
+	seen := make(map[string]bool)
+
+	for _, n := range nodes {
+		id := nameReplacer.Replace(n.Name)
+		seen[id] = true
+		if !r.activeNodes[id] {
+			r.activeNodes[id] = true
+			_ = r.charts.Add(*newCharts(tmpl, id, n.Name, "")...)
+		}
+
+		prefix := "node_" + id
+		mx[prefix+"_running"] = boolToInt(n.Running)
+		mx[prefix+"_mem_alarm"] = boolToInt(n.MemAlarm)
+		mx[prefix+"_disk_free_alarm"] = boolToInt(n.DiskFreeAlarm)
+		mx[prefix+"_partitions"] = int64(len(n.Partitions))
+		mx[prefix+"_mem_used"] = int64(n.MemUsed)
+	}
+
+	r.removeInactive(r.activeNodes, seen, tmpl)
+}
+
+func (r *Rabbitmq) collectVhosts(mx map[string]int64, vhosts []vhost) {
+	seen := make(map[string]bool)
+
+	for _, v := range vhosts {
+		if !r.filterVhost(v.Name) {
+			continue
+		}
+
+		id := nameReplacer.Replace(v.Name)
+		seen[id] = true
+		if !r.activeVhosts[id] {
+			r.activeVhosts[id] = true
+			_ = r.charts.Add(*newCharts(vhostCharts, id, v.Name, v.Name)...)
+		}
+
+		for key, value := range stm.ToMap(v) {
+			mx["vhost_"+id+"_"+key] = value
+		}
+	}
+
+	r.removeInactive(r.activeVhosts, seen, vhostCharts)
+}
+
+// collectQueues collects the queues metrics, tmpl is the queue charts template, it depends on the mode.
+func (r *Rabbitmq) collectQueues(mx map[string]int64, queues []queue, tmpl Charts) {
+	var (
+		seen = make(map[string]bool)
+		unp  int
+	)
+
+	for _, q := range queues {
+		if !r.filterVhost(q.Vhost) || !r.filterQueue(q.Name) {
+			continue
+		}
+
+		key := queueKey{vhost: q.Vhost, name: q.Name}
+		id, ok := r.queueIDs[key]
+		if !ok {
+			if r.MaxQueues != 0 && len(r.activeQueues) >= r.MaxQueues {
+				unp++
+				continue
+			}
+			id = r.newQueueID(key)
+			r.queueIDs[key] = id
+			r.activeQueues[id] = true
+			_ = r.charts.Add(*newCharts(tmpl, id, q.Name, q.Vhost)...)
+		}
+		seen[id] = true
+
+		for key, value := range stm.ToMap(q) {
+			mx["queue_"+id+"_"+key] = value
+		}
+	}
+
+	r.removeInactive(r.activeQueues, seen, tmpl)
+
+	for key, id := range r.queueIDs {
+		if !r.activeQueues[id] {
+			delete(r.queueIDs, key)
+		}
+	}
+
+	if unp > 0 {
+		r.Debugf("%d queues were unprocessed due to max_queues limit (%d)", unp, r.MaxQueues)
+	}
+}
+
+// newQueueID returns the queue charts id. Different vhost and queue names may be replaced to the same id
+// ('a.b' and 'a_b', vhost 'a' queue 'b_c' and vhost 'a_b' queue 'c'), the colliding id gets a numeric suffix.
+func (r *Rabbitmq) newQueueID(key queueKey) string {
+	base := nameReplacer.Replace(key.vhost + "_" + key.name)
+
+	id := base
+	for i := 2; r.activeQueues[id]; i++ {
+		id = base + "_" + strconv.Itoa(i)
+	}
+
+	return id
+}
+
+// removeInactive removes the charts of the objects that are not in the last response.
+func (r *Rabbitmq) removeInactive(active, seen map[string]bool, tmpl Charts) {
+	for id := range active {
+		if seen[id] {
+			continue
+		}
+		delete(active, id)
+
+		for _, chart := range *newCharts(tmpl, id, "", "") {
+			chart = r.charts.Get(chart.ID)
+			if chart == nil {
+				continue
+			}
+			chart.Obsolete = true
+			chart.MarkNotCreated()
+			chart.MarkRemove()
+		}
+	}
+}
+
+func (r Rabbitmq) filterVhost(name string) bool {
+	if r.vhostsFilter == nil {
+		return true
+	}
+	return r.vhostsFilter.MatchString(name)
+}
+
+func (r Rabbitmq) filterQueue(name string) bool {
+	if r.queuesFilter == nil {
+		return true
+	}
+	return r.queuesFilter.MatchString(name)
+}
+
+func boolToInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
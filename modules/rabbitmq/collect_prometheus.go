@@ -0,0 +1,118 @@
+package rabbitmq
+
+import (
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+// collectPrometheus collects the rabbitmq_prometheus plugin metrics (RabbitMQ 3.8+) into the management API structures.
+// The plugin aggregates the metrics by default, the per queue metrics are available only if the per object
+// metrics are enabled ('prometheus.return_per_object_metrics = true' or the '/metrics/per-object' endpoint).
+func (r *Rabbitmq) collectPrometheus() (map[string]int64, error) {
+	raw, err := r.prom.Scrape()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		overview overview
+		node     node
+	)
+
+	overview.objectTotals.Connections = sumInt(raw.FindByName("rabbitmq_connections"))
+	overview.objectTotals.Channels = sumInt(raw.FindByName("rabbitmq_channels"))
+	overview.objectTotals.Consumers = sumInt(raw.FindByName("rabbitmq_consumers"))
+	overview.objectTotals.Queues = sumInt(raw.FindByName("rabbitmq_queues"))
+
+	overview.queueTotals.MessagesReady = sumInt(raw.FindByName("rabbitmq_queue_messages_ready"))
+	overview.queueTotals.MessagesUnacknowledged = sumInt(raw.FindByName("rabbitmq_queue_messages_unacked"))
+
+	stats := &overview.messageStats
+	stats.Ack = sumInt(raw.FindByName("rabbitmq_channel_messages_acked_total"))
+	stats.Publish = sumInt(raw.FindByName("rabbitmq_channel_messages_published_total"))
+	stats.Confirm = sumInt(raw.FindByName("rabbitmq_channel_messages_confirmed_total"))
+	stats.Deliver = sumInt(raw.FindByName("rabbitmq_channel_messages_delivered_ack_total"))
+	stats.DeliverNoAck = sumInt(raw.FindByName("rabbitmq_channel_messages_delivered_total"))
+	stats.Get = sumInt(raw.FindByName("rabbitmq_channel_get_ack_total"))
+	stats.GetNoAck = sumInt(raw.FindByName("rabbitmq_channel_get_total"))
+	stats.DeliverGet = stats.Deliver + stats.DeliverNoAck + stats.Get + stats.GetNoAck
+	stats.Redeliver = sumInt(raw.FindByName("rabbitmq_channel_messages_redelivered_total"))
+	stats.ReturnUnroutable = sumInt(raw.FindByName("rabbitmq_channel_messages_unroutable_returned_total"))
+
+	node.FDUsed = sumInt(raw.FindByName("rabbitmq_process_open_fds"))
+	node.SocketsUsed = sumInt(raw.FindByName("rabbitmq_process_open_tcp_sockets"))
+	node.MemUsed = sumInt(raw.FindByName("rabbitmq_process_resident_memory_bytes"))
+	node.DiskFree = sumInt(raw.FindByName("rabbitmq_disk_space_available_bytes"))
+	node.ProcUsed = sumInt(raw.FindByName("erlang_vm_process_count"))
+	node.RunQueue = sumInt(raw.FindByName("erlang_vm_statistics_run_queues_length_total"))
+
+	mx := stm.ToMap(overview, node)
+
+	// the plugin exposes only the node it runs on
+	var nodes []clusterNode
+	if info := raw.FindByName("rabbitmq_identity_info"); len(info) > 0 {
+		if name := info[0].Labels.Get("rabbitmq_node"); name != "" {
+			nodes = append(nodes, clusterNode{
+				node:          node,
+				Name:          name,
+				Running:       true,
+				MemAlarm:      raw.FindByName("rabbitmq_alarms_memory_used_watermark").Max() == 1,
+				DiskFreeAlarm: raw.FindByName("rabbitmq_alarms_free_disk_space_watermark").Max() == 1,
+			})
+		}
+	}
+	r.collectClusterNodes(mx, nodes, prometheusNodeCharts)
+	r.collectQueues(mx, prometheusQueues(raw), prometheusQueueCharts)
+
+	mx["raft_log_commit_index"] = int64(sumInt(raw.FindByName("rabbitmq_raft_log_commit_index")))
+	mx["raft_entry_commit_latency"] = int64(raw.FindByName("rabbitmq_raft_entry_commit_latency_seconds").Max() * 1e6)
+
+	return mx, nil
+}
+
+// prometheusQueues returns the per object queue metrics, there are none if the metrics are aggregated.
+func prometheusQueues(raw prometheus.Metrics) []queue {
+	var (
+		queues []queue
+		index  = make(map[string]int)
+	)
+
+	get := func(metric prometheus.Metric) *queue {
+		name, vhost := metric.Labels.Get("queue"), metric.Labels.Get("vhost")
+		if name == "" {
+			return nil
+		}
+		key := vhost + "/" + name
+		if _, ok := index[key]; !ok {
+			index[key] = len(queues)
+			queues = append(queues, queue{Name: name, Vhost: vhost})
+		}
+		return &queues[index[key]]
+	}
+
+	for _, metric := range raw.FindByName("rabbitmq_queue_messages_ready") {
+		if q := get(metric); q != nil {
+			q.MessagesReady = int(metric.Value)
+		}
+	}
+	for _, metric := range raw.FindByName("rabbitmq_queue_messages_unacked") {
+		if q := get(metric); q != nil {
+			q.MessagesUnacknowledged = int(metric.Value)
+		}
+	}
+	for _, metric := range raw.FindByName("rabbitmq_queue_consumers") {
+		if q := get(metric); q != nil {
+			q.Consumers = int(metric.Value)
+		}
+	}
+
+	return queues
+}
+
+func sumInt(metrics prometheus.Metrics) int {
+	var sum float64
+	for _, metric := range metrics {
+		sum += metric.Value
+	}
+	return int(sum)
+}
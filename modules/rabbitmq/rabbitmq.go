@@ -3,7 +3,8 @@ package rabbitmq
 import (
 	"time"
 
-	"github.com/netdata/go.d.plugin/pkg/stm"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
 	"github.com/netdata/go.d.plugin/pkg/web"
 
 	"github.com/netdata/go-orchestrator/module"
@@ -19,10 +20,17 @@ func init() {
 }
 
 const (
-	defaultURL         = "http://localhost:15672"
-	defaultUsername    = "guest"
-	defaultPassword    = "guest"
-	defaultHTTPTimeout = time.Second
+	modeManagement = "management"
+	modePrometheus = "prometheus"
+)
+
+const (
+	defaultURL           = "http://localhost:15672"
+	defaultPrometheusURL = "http://localhost:15692/metrics"
+	defaultUsername      = "guest"
+	defaultPassword      = "guest"
+	defaultHTTPTimeout   = time.Second
+	defaultMaxQueues     = 50
 )
 
 // New creates Rabbitmq with default values
@@ -36,6 +44,13 @@ func New() *Rabbitmq {
 			},
 			Client: web.Client{Timeout: web.Duration{Duration: defaultHTTPTimeout}},
 		},
+		MaxQueues: defaultMaxQueues,
+
+		charts:       charts.Copy(),
+		activeNodes:  make(map[string]bool),
+		activeVhosts: make(map[string]bool),
+		activeQueues: make(map[string]bool),
+		queueIDs:     make(map[queueKey]string),
 	}
 }
 
@@ -45,7 +60,28 @@ type Rabbitmq struct {
 
 	web.HTTP `yaml:",inline"`
 
-	apiClient *apiClient
+	// Mode is the metrics source: the management API (default) or the rabbitmq_prometheus plugin endpoint
+	Mode         string `yaml:"mode"`
+	MaxQueues    int    `yaml:"max_queues"`
+	VhostsFilter string `yaml:"vhosts_filter"`
+	QueuesFilter string `yaml:"queues_filter"`
+
+	apiClient    *apiClient
+	prom         prometheus.Prometheus
+	vhostsFilter matcher.Matcher
+	queuesFilter matcher.Matcher
+	charts       *Charts
+	activeNodes  map[string]bool
+	activeVhosts map[string]bool
+	activeQueues map[string]bool
+	// queueIDs are the charts ids of the active queues
+	queueIDs map[queueKey]string
+}
+
+// queueKey identifies the queue, the queue names are unique only within the vhost.
+type queueKey struct {
+	vhost string
+	name  string
 }
 
 // Cleanup makes cleanup.
@@ -58,6 +94,36 @@ func (r *Rabbitmq) Init() bool {
 		return false
 	}
 
+	switch r.Mode {
+	case "", modeManagement:
+	case modePrometheus:
+		if r.URL == defaultURL {
+			r.URL = defaultPrometheusURL
+		}
+		r.charts = newPrometheusCharts()
+	default:
+		r.Errorf("unknown mode '%s', supported modes: %s, %s", r.Mode, modeManagement, modePrometheus)
+		return false
+	}
+
+	if r.VhostsFilter != "" {
+		f, err := matcher.NewSimplePatternsMatcher(r.VhostsFilter)
+		if err != nil {
+			r.Errorf("error on creating vhosts filter : %v", err)
+			return false
+		}
+		r.vhostsFilter = matcher.WithCache(f)
+	}
+
+	if r.QueuesFilter != "" {
+		f, err := matcher.NewSimplePatternsMatcher(r.QueuesFilter)
+		if err != nil {
+			r.Errorf("error on creating queues filter : %v", err)
+			return false
+		}
+		r.queuesFilter = matcher.WithCache(f)
+	}
+
 	client, err := web.NewHTTPClient(r.Client)
 
 	if err != nil {
@@ -65,9 +131,13 @@ func (r *Rabbitmq) Init() bool {
 		return false
 	}
 
-	r.apiClient = &apiClient{
-		req:        r.Request,
-		httpClient: client,
+	if r.Mode == modePrometheus {
+		r.prom = prometheus.New(client, r.Request)
+	} else {
+		r.apiClient = &apiClient{
+			req:        r.Request,
+			httpClient: client,
+		}
 	}
 
 	r.Debugf("using URL %s", r.URL)
@@ -82,27 +152,18 @@ func (r *Rabbitmq) Check() bool {
 }
 
 // Charts creates Charts.
-func (Rabbitmq) Charts() *Charts {
-	return charts.Copy()
+func (r Rabbitmq) Charts() *Charts {
+	return r.charts
 }
 
 // Collect collects stats.
 func (r *Rabbitmq) Collect() map[string]int64 {
-	var (
-		overview overview
-		node     node
-		err      error
-	)
+	mx, err := r.collect()
 
-	if overview, err = r.apiClient.getOverview(); err != nil {
-		r.Error(err)
-		return nil
-	}
-
-	if node, err = r.apiClient.getNodeStats(); err != nil {
+	if err != nil {
 		r.Error(err)
 		return nil
 	}
 
-	return stm.ToMap(overview, node)
+	return mx
 }
@@ -16,8 +16,33 @@ import (
 var (
 	overviewData, _ = ioutil.ReadFile("testdata/overview.txt")
 	nodeData, _     = ioutil.ReadFile("testdata/node.txt")
+	nodesData, _    = ioutil.ReadFile("testdata/nodes.json")
+	vhostsData, _   = ioutil.ReadFile("testdata/vhosts.json")
+	queuesData, _   = ioutil.ReadFile("testdata/queues.json")
 )
 
+// newTestServer returns a fake management API, the queues are served by reference to test the queues removal.
+func newTestServer(queues *[]byte) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/overview":
+					_, _ = w.Write(overviewData)
+				case "/api/node/rabbit@rbt0":
+					_, _ = w.Write(nodeData)
+				case "/api/nodes":
+					_, _ = w.Write(nodesData)
+				case "/api/vhosts":
+					_, _ = w.Write(vhostsData)
+				case "/api/queues":
+					_, _ = w.Write(*queues)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+}
+
 func TestRabbitmq_Cleanup(t *testing.T) {
 	New().Cleanup()
 }
@@ -35,19 +60,21 @@ func TestRabbitmq_Init(t *testing.T) {
 	assert.Equal(t, defaultUsername, mod.Username)
 	assert.Equal(t, defaultPassword, mod.Password)
 	assert.Equal(t, defaultHTTPTimeout, mod.Timeout.Duration)
+	assert.Equal(t, defaultMaxQueues, mod.MaxQueues)
+}
+
+func TestRabbitmq_InitNG(t *testing.T) {
+	mod := New()
+	mod.QueuesFilter = "*["
+	assert.False(t, mod.Init())
+
+	mod = New()
+	mod.VhostsFilter = "*["
+	assert.False(t, mod.Init())
 }
 
 func TestRabbitmq_Check(t *testing.T) {
-	ts := httptest.NewServer(
-		http.HandlerFunc(
-			func(w http.ResponseWriter, r *http.Request) {
-				switch r.URL.Path {
-				case "/api/overview":
-					_, _ = w.Write(overviewData)
-				case "/api/node/rabbit@rbt0":
-					_, _ = w.Write(nodeData)
-				}
-			}))
+	ts := newTestServer(&queuesData)
 	defer ts.Close()
 
 	mod := New()
@@ -69,16 +96,7 @@ func TestRabbitmq_Charts(t *testing.T) {
 }
 
 func TestRabbitmq_Collect(t *testing.T) {
-	ts := httptest.NewServer(
-		http.HandlerFunc(
-			func(w http.ResponseWriter, r *http.Request) {
-				switch r.URL.Path {
-				case "/api/overview":
-					_, _ = w.Write(overviewData)
-				case "/api/node/rabbit@rbt0":
-					_, _ = w.Write(nodeData)
-				}
-			}))
+	ts := newTestServer(&queuesData)
 	defer ts.Close()
 
 	mod := New()
@@ -113,9 +131,206 @@ func TestRabbitmq_Collect(t *testing.T) {
 		"message_stats_publish_out":            4,
 		"object_totals_connections":            44,
 		"queue_totals_messages_ready":          150,
+
+		"node_rabbit_rbt0_disk_free_alarm": 0,
+		"node_rabbit_rbt0_mem_alarm":       0,
+		"node_rabbit_rbt0_mem_used":        75022616,
+		"node_rabbit_rbt0_partitions":      0,
+		"node_rabbit_rbt0_running":         1,
+		"node_rabbit_rbt1_disk_free_alarm": 1,
+		"node_rabbit_rbt1_mem_alarm":       1,
+		"node_rabbit_rbt1_mem_used":        104857600,
+		"node_rabbit_rbt1_partitions":      1,
+		"node_rabbit_rbt1_running":         1,
+		"node_rabbit_rbt2_disk_free_alarm": 0,
+		"node_rabbit_rbt2_mem_alarm":       0,
+		"node_rabbit_rbt2_mem_used":        0,
+		"node_rabbit_rbt2_partitions":      0,
+		"node_rabbit_rbt2_running":         0,
+
+		"vhost___message_stats_ack":                       100,
+		"vhost___message_stats_confirm":                   0,
+		"vhost___message_stats_deliver":                   0,
+		"vhost___message_stats_deliver_get":               110,
+		"vhost___message_stats_deliver_no_ack":            0,
+		"vhost___message_stats_get":                       0,
+		"vhost___message_stats_get_no_ack":                0,
+		"vhost___message_stats_publish":                   130,
+		"vhost___message_stats_publish_in":                0,
+		"vhost___message_stats_publish_out":               0,
+		"vhost___message_stats_redeliver":                 5,
+		"vhost___message_stats_return_unroutable":         1,
+		"vhost___messages_ready":                          20,
+		"vhost___messages_unacknowledged":                 10,
+		"vhost_orders_eu_message_stats_ack":               0,
+		"vhost_orders_eu_message_stats_confirm":           0,
+		"vhost_orders_eu_message_stats_deliver":           0,
+		"vhost_orders_eu_message_stats_deliver_get":       0,
+		"vhost_orders_eu_message_stats_deliver_no_ack":    0,
+		"vhost_orders_eu_message_stats_get":               0,
+		"vhost_orders_eu_message_stats_get_no_ack":        0,
+		"vhost_orders_eu_message_stats_publish":           0,
+		"vhost_orders_eu_message_stats_publish_in":        0,
+		"vhost_orders_eu_message_stats_publish_out":       0,
+		"vhost_orders_eu_message_stats_redeliver":         0,
+		"vhost_orders_eu_message_stats_return_unroutable": 0,
+		"vhost_orders_eu_messages_ready":                  0,
+		"vhost_orders_eu_messages_unacknowledged":         0,
+
+		"queue___amq_gen-1_consumers":                              1,
+		"queue___amq_gen-1_message_stats_ack":                      0,
+		"queue___amq_gen-1_message_stats_confirm":                  0,
+		"queue___amq_gen-1_message_stats_deliver":                  0,
+		"queue___amq_gen-1_message_stats_deliver_get":              0,
+		"queue___amq_gen-1_message_stats_deliver_no_ack":           0,
+		"queue___amq_gen-1_message_stats_get":                      0,
+		"queue___amq_gen-1_message_stats_get_no_ack":               0,
+		"queue___amq_gen-1_message_stats_publish":                  0,
+		"queue___amq_gen-1_message_stats_publish_in":               0,
+		"queue___amq_gen-1_message_stats_publish_out":              0,
+		"queue___amq_gen-1_message_stats_redeliver":                0,
+		"queue___amq_gen-1_message_stats_return_unroutable":        0,
+		"queue___amq_gen-1_messages_ready":                         0,
+		"queue___amq_gen-1_messages_unacknowledged":                0,
+		"queue___tasks_consumers":                                  2,
+		"queue___tasks_message_stats_ack":                          100,
+		"queue___tasks_message_stats_confirm":                      0,
+		"queue___tasks_message_stats_deliver":                      0,
+		"queue___tasks_message_stats_deliver_get":                  110,
+		"queue___tasks_message_stats_deliver_no_ack":               0,
+		"queue___tasks_message_stats_get":                          0,
+		"queue___tasks_message_stats_get_no_ack":                   0,
+		"queue___tasks_message_stats_publish":                      130,
+		"queue___tasks_message_stats_publish_in":                   0,
+		"queue___tasks_message_stats_publish_out":                  0,
+		"queue___tasks_message_stats_redeliver":                    5,
+		"queue___tasks_message_stats_return_unroutable":            0,
+		"queue___tasks_messages_ready":                             20,
+		"queue___tasks_messages_unacknowledged":                    10,
+		"queue_orders_eu_invoices_consumers":                       0,
+		"queue_orders_eu_invoices_message_stats_ack":               0,
+		"queue_orders_eu_invoices_message_stats_confirm":           0,
+		"queue_orders_eu_invoices_message_stats_deliver":           0,
+		"queue_orders_eu_invoices_message_stats_deliver_get":       0,
+		"queue_orders_eu_invoices_message_stats_deliver_no_ack":    0,
+		"queue_orders_eu_invoices_message_stats_get":               0,
+		"queue_orders_eu_invoices_message_stats_get_no_ack":        0,
+		"queue_orders_eu_invoices_message_stats_publish":           0,
+		"queue_orders_eu_invoices_message_stats_publish_in":        0,
+		"queue_orders_eu_invoices_message_stats_publish_out":       0,
+		"queue_orders_eu_invoices_message_stats_redeliver":         0,
+		"queue_orders_eu_invoices_message_stats_return_unroutable": 0,
+		"queue_orders_eu_invoices_messages_ready":                  0,
+		"queue_orders_eu_invoices_messages_unacknowledged":         0,
 	}
 
-	assert.Equal(t, expected, mod.Collect())
+	mx := mod.Collect()
+	assert.Equal(t, expected, mx)
+
+	assert.Len(t, *mod.Charts(), len(charts)+3*len(nodeCharts)+2*len(vhostCharts)+3*len(queueCharts))
+	chart := mod.Charts().Get("queue_orders_eu_invoices_consumers")
+	require.NotNil(t, chart)
+	assert.Equal(t, "vhost orders.eu", chart.Fam)
+	assert.Equal(t, "Queue invoices Consumers", chart.Title)
+}
+
+func TestRabbitmq_Collect_RemoveQueues(t *testing.T) {
+	queues := queuesData
+	ts := newTestServer(&queues)
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+
+	require.True(t, mod.Init())
+	require.NotNil(t, mod.Collect())
+
+	chart := mod.Charts().Get("queue___amq_gen-1_queued_messages")
+	require.NotNil(t, chart)
+
+	// the exclusive queue is deleted
+	queues = []byte(`[{"name": "tasks", "vhost": "/"}, {"name": "invoices", "vhost": "orders.eu"}]`)
+
+	mx := mod.Collect()
+	assert.NotContains(t, mx, "queue___amq_gen-1_consumers")
+	assert.Contains(t, mx, "queue___tasks_consumers")
+	assert.True(t, chart.Obsolete)
+	assert.False(t, mod.Charts().Get("queue___tasks_queued_messages").Obsolete)
+}
+
+func TestRabbitmq_Collect_QueueIDsCollision(t *testing.T) {
+	queues := []byte(`[
+  {"name": "a.b", "vhost": "v", "consumers": 1},
+  {"name": "a_b", "vhost": "v", "consumers": 2},
+  {"name": "b", "vhost": "v_a", "consumers": 3}
+]`)
+	ts := newTestServer(&queues)
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+
+	require.True(t, mod.Init())
+
+	mx := mod.Collect()
+	assert.Equal(t, int64(1), mx["queue_v_a_b_consumers"])
+	assert.Equal(t, int64(2), mx["queue_v_a_b_2_consumers"])
+	assert.Equal(t, int64(3), mx["queue_v_a_b_3_consumers"])
+	assert.Equal(t, "Queue a_b Consumers", mod.Charts().Get("queue_v_a_b_2_consumers").Title)
+
+	// the ids are kept while the queues exist
+	queues = []byte(`[{"name": "a_b", "vhost": "v", "consumers": 2}, {"name": "b", "vhost": "v_a", "consumers": 3}]`)
+
+	mx = mod.Collect()
+	assert.NotContains(t, mx, "queue_v_a_b_consumers")
+	assert.Equal(t, int64(2), mx["queue_v_a_b_2_consumers"])
+	assert.Equal(t, int64(3), mx["queue_v_a_b_3_consumers"])
+	assert.Len(t, mod.queueIDs, 2)
+}
+
+func TestRabbitmq_Collect_Filters(t *testing.T) {
+	ts := newTestServer(&queuesData)
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.VhostsFilter = "!/ *"
+	mod.QueuesFilter = "!amq.* *"
+
+	require.True(t, mod.Init())
+
+	mx := mod.Collect()
+	assert.Contains(t, mx, "vhost_orders_eu_messages_ready")
+	assert.Contains(t, mx, "queue_orders_eu_invoices_consumers")
+	assert.NotContains(t, mx, "vhost___messages_ready")
+	assert.NotContains(t, mx, "queue___tasks_consumers")
+
+	mod = New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.QueuesFilter = "!amq.* *"
+
+	require.True(t, mod.Init())
+
+	mx = mod.Collect()
+	assert.Contains(t, mx, "queue___tasks_consumers")
+	assert.NotContains(t, mx, "queue___amq_gen-1_consumers")
+}
+
+func TestRabbitmq_Collect_MaxQueues(t *testing.T) {
+	ts := newTestServer(&queuesData)
+	defer ts.Close()
+
+	mod := New()
+	mod.HTTP.Request = web.Request{URL: ts.URL}
+	mod.MaxQueues = 2
+
+	require.True(t, mod.Init())
+
+	mx := mod.Collect()
+	assert.Len(t, mod.activeQueues, 2)
+	assert.Contains(t, mx, "queue___tasks_consumers")
+	assert.Contains(t, mx, "queue___amq_gen-1_consumers")
+	assert.NotContains(t, mx, "queue_orders_eu_invoices_consumers")
 }
 
 func TestRabbitmq_InvalidData(t *testing.T) {
@@ -145,3 +360,83 @@ func TestRabbitmq_404(t *testing.T) {
 	require.True(t, mod.Init())
 	assert.False(t, mod.Check())
 }
+
+func TestRabbitmq_Collect_Prometheus(t *testing.T) {
+	data, _ := ioutil.ReadFile("testdata/prometheus.txt")
+	ts := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(data)
+			}))
+	defer ts.Close()
+
+	mod := New()
+	mod.Mode = modePrometheus
+	mod.HTTP.Request = web.Request{URL: ts.URL + "/metrics"}
+
+	require.True(t, mod.Init())
+	require.True(t, mod.Check())
+
+	expected := map[string]int64{
+		"object_totals_connections":            12,
+		"object_totals_channels":               24,
+		"object_totals_consumers":              3,
+		"object_totals_queues":                 2,
+		"queue_totals_messages_ready":          25,
+		"queue_totals_messages_unacknowledged": 11,
+		"message_stats_ack":                    98,
+		"message_stats_publish":                130,
+		"message_stats_confirm":                120,
+		"message_stats_deliver":                100,
+		"message_stats_deliver_no_ack":         6,
+		"message_stats_get":                    4,
+		"message_stats_get_no_ack":             3,
+		"message_stats_deliver_get":            113,
+		"message_stats_redeliver":              5,
+		"message_stats_return_unroutable":      1,
+		"fd_used":                              75,
+		"sockets_used":                         40,
+		"mem_used":                             115343360,
+		"disk_free":                            79493152768,
+		"proc_used":                            452,
+		"run_queue":                            2,
+
+		"node_rabbit_rbt0_running":         1,
+		"node_rabbit_rbt0_mem_alarm":       1,
+		"node_rabbit_rbt0_disk_free_alarm": 0,
+		"node_rabbit_rbt0_mem_used":        115343360,
+
+		"queue___tasks_messages_ready":                     20,
+		"queue___tasks_messages_unacknowledged":            10,
+		"queue___tasks_consumers":                          2,
+		"queue_orders_eu_invoices_messages_ready":          5,
+		"queue_orders_eu_invoices_messages_unacknowledged": 1,
+		"queue_orders_eu_invoices_consumers":               1,
+
+		"raft_log_commit_index":     1500,
+		"raft_entry_commit_latency": 2500,
+	}
+
+	mx := mod.Collect()
+	for key, value := range expected {
+		assert.Containsf(t, mx, key, "metric '%s'", key)
+		assert.Equalf(t, value, mx[key], "metric '%s'", key)
+	}
+
+	assert.Len(t, *mod.Charts(), len(charts)+len(raftCharts)+len(prometheusNodeCharts)+2*len(prometheusQueueCharts))
+	assert.False(t, mod.Charts().Get("message_rates").HasDim("message_stats_publish_in"))
+	assert.Nil(t, mod.Charts().Get("node_rabbit_rbt0_partitions"))
+}
+
+func TestRabbitmq_Init_Prometheus(t *testing.T) {
+	mod := New()
+	mod.Mode = modePrometheus
+
+	require.True(t, mod.Init())
+	assert.Equal(t, defaultPrometheusURL, mod.URL)
+	assert.NotNil(t, mod.Charts().Get("raft_log_commits"))
+
+	mod = New()
+	mod.Mode = "jmx"
+	assert.False(t, mod.Init())
+}
@@ -1,14 +1,20 @@
 package springboot2
 
 import (
+	"fmt"
+
 	"github.com/netdata/go-orchestrator/module"
 )
 
 type (
 	// Charts is an alias for module.Charts
 	Charts = module.Charts
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
 )
 
 var charts = Charts{
@@ -29,6 +35,7 @@ var charts = Charts{
 		Dims: Dims{
 			{ID: "threads_daemon", Name: "daemon"},
 			{ID: "threads", Name: "total"},
+			{ID: "threads_peak", Name: "peak"},
 		},
 	},
 	{
@@ -73,3 +80,178 @@ var charts = Charts{
 		},
 	},
 }
+
+var threadStatesCharts = Charts{
+	{
+		ID:    "thread_states",
+		Title: "Thread States", Units: "threads", Fam: "threads", Type: module.Stacked,
+		Dims: Dims{
+			{ID: "threads_states_runnable", Name: "runnable"},
+			{ID: "threads_states_blocked", Name: "blocked"},
+			{ID: "threads_states_waiting", Name: "waiting"},
+			{ID: "threads_states_timed_waiting", Name: "timed-waiting"},
+			{ID: "threads_states_new", Name: "new"},
+			{ID: "threads_states_terminated", Name: "terminated"},
+		},
+	},
+}
+
+var classesCharts = Charts{
+	{
+		ID:    "classes_loaded",
+		Title: "Loaded Classes", Units: "classes", Fam: "classes", Type: module.Line,
+		Dims: Dims{
+			{ID: "classes_loaded", Name: "loaded"},
+		},
+	},
+	{
+		ID:    "classes_unloaded",
+		Title: "Unloaded Classes", Units: "classes/s", Fam: "classes", Type: module.Line,
+		Dims: Dims{
+			{ID: "classes_unloaded", Name: "unloaded", Algo: module.Incremental},
+		},
+	},
+}
+
+// gcCharts pause times are in microseconds.
+var gcCharts = Charts{
+	{
+		ID:    "gc_pauses",
+		Title: "GC Pauses", Units: "pauses/s", Fam: "gc", Type: module.Stacked,
+		Dims: Dims{
+			{ID: "gc_pauses_count_minor", Name: "minor", Algo: module.Incremental},
+			{ID: "gc_pauses_count_major", Name: "major", Algo: module.Incremental},
+			{ID: "gc_pauses_count_other", Name: "other", Algo: module.Incremental},
+		},
+	},
+	{
+		ID:    "gc_pause_time",
+		Title: "GC Pause Time", Units: "milliseconds/s", Fam: "gc", Type: module.Stacked,
+		Dims: Dims{
+			{ID: "gc_pauses_time_minor", Name: "minor", Algo: module.Incremental, Div: 1000},
+			{ID: "gc_pauses_time_major", Name: "major", Algo: module.Incremental, Div: 1000},
+			{ID: "gc_pauses_time_other", Name: "other", Algo: module.Incremental, Div: 1000},
+		},
+	},
+	{
+		ID:    "gc_pause_max",
+		Title: "GC Max Pause Time", Units: "milliseconds", Fam: "gc", Type: module.Line,
+		Dims: Dims{
+			{ID: "gc_pauses_max_minor", Name: "minor", Div: 1000},
+			{ID: "gc_pauses_max_major", Name: "major", Div: 1000},
+			{ID: "gc_pauses_max_other", Name: "other", Div: 1000},
+		},
+	},
+}
+
+// gcHistogramCharts buckets dims are added dynamically, the histogram is exported only if it is enabled
+// in the application ('management.metrics.distribution.*' properties).
+var gcHistogramCharts = Charts{
+	{
+		ID:    "gc_pause_histogram",
+		Title: "GC Pauses Histogram", Units: "pauses/s", Fam: "gc", Type: module.Stacked,
+	},
+}
+
+var logbackCharts = Charts{
+	{
+		ID:    "logback_events",
+		Title: "Logback Events", Units: "events/s", Fam: "logback", Type: module.Stacked,
+		Dims: Dims{
+			{ID: "logback_events_error", Name: "error", Algo: module.Incremental},
+			{ID: "logback_events_warn", Name: "warn", Algo: module.Incremental},
+			{ID: "logback_events_info", Name: "info", Algo: module.Incremental},
+			{ID: "logback_events_debug", Name: "debug", Algo: module.Incremental},
+			{ID: "logback_events_trace", Name: "trace", Algo: module.Incremental},
+		},
+	},
+}
+
+var tomcatSessionsCharts = Charts{
+	{
+		ID:    "tomcat_sessions",
+		Title: "Tomcat Active Sessions", Units: "sessions", Fam: "tomcat", Type: module.Line,
+		Dims: Dims{
+			{ID: "tomcat_sessions_active", Name: "active"},
+			{ID: "tomcat_sessions_active_max", Name: "max"},
+		},
+	},
+	{
+		ID:    "tomcat_sessions_events",
+		Title: "Tomcat Sessions", Units: "sessions/s", Fam: "tomcat", Type: module.Line,
+		Dims: Dims{
+			{ID: "tomcat_sessions_created", Name: "created", Algo: module.Incremental},
+			{ID: "tomcat_sessions_expired", Name: "expired", Algo: module.Incremental},
+			{ID: "tomcat_sessions_rejected", Name: "rejected", Algo: module.Incremental},
+		},
+	},
+}
+
+var tomcatThreadsCharts = Charts{
+	{
+		ID:    "tomcat_threads",
+		Title: "Tomcat Threads", Units: "threads", Fam: "tomcat", Type: module.Stacked,
+		Dims: Dims{
+			{ID: "tomcat_threads_busy", Name: "busy"},
+			{ID: "tomcat_threads_idle", Name: "idle"},
+		},
+	},
+}
+
+var hikariPoolCharts = Charts{
+	{
+		ID:    "hikaricp_%s_connections",
+		Title: "HikariCP Connections, Pool %s", Units: "connections", Fam: "hikaricp %s", Type: module.Stacked,
+		Ctx: "springboot2.hikaricp_connections",
+		Dims: Dims{
+			{ID: "hikaricp_%s_active", Name: "active"},
+			{ID: "hikaricp_%s_idle", Name: "idle"},
+		},
+	},
+	{
+		ID:    "hikaricp_%s_pending",
+		Title: "HikariCP Pending Threads, Pool %s", Units: "threads", Fam: "hikaricp %s", Type: module.Line,
+		Ctx: "springboot2.hikaricp_pending",
+		Dims: Dims{
+			{ID: "hikaricp_%s_pending", Name: "pending"},
+		},
+	},
+	{
+		ID:    "hikaricp_%s_timeouts",
+		Title: "HikariCP Connection Timeouts, Pool %s", Units: "timeouts/s", Fam: "hikaricp %s", Type: module.Line,
+		Ctx: "springboot2.hikaricp_timeouts",
+		Dims: Dims{
+			{ID: "hikaricp_%s_timeouts", Name: "timeouts", Algo: module.Incremental},
+		},
+	},
+}
+
+// uriCharts methods dims are added dynamically, the latency is in microseconds.
+var uriCharts = Charts{
+	{
+		ID:    "uri_%s_requests",
+		Title: "Requests, URI %s", Units: "requests/s", Fam: "uri %s", Type: module.Stacked,
+		Ctx: "springboot2.uri_requests",
+	},
+	{
+		ID:    "uri_%s_latency",
+		Title: "Average Latency, URI %s", Units: "milliseconds", Fam: "uri %s", Type: module.Line,
+		Ctx: "springboot2.uri_latency",
+	},
+}
+
+func newInstanceCharts(tmpl Charts, id, name string) *Charts {
+	charts := tmpl.Copy()
+
+	for _, chart := range *charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		chart.Title = fmt.Sprintf(chart.Title, name)
+		chart.Fam = fmt.Sprintf(chart.Fam, name)
+
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+	}
+
+	return charts
+}
@@ -0,0 +1,395 @@
+package springboot2
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	mtx "github.com/netdata/go.d.plugin/pkg/metrics"
+	"github.com/netdata/go.d.plugin/pkg/prometheus"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+var nameReplacer = strings.NewReplacer(".", "_", " ", "_", "/", "_")
+
+type threadStates struct {
+	Runnable     mtx.Gauge `stm:"runnable"`
+	Blocked      mtx.Gauge `stm:"blocked"`
+	Waiting      mtx.Gauge `stm:"waiting"`
+	TimedWaiting mtx.Gauge `stm:"timed_waiting"`
+	New          mtx.Gauge `stm:"new"`
+	Terminated   mtx.Gauge `stm:"terminated"`
+}
+
+type classes struct {
+	Loaded   mtx.Gauge   `stm:"loaded"`
+	Unloaded mtx.Counter `stm:"unloaded"`
+}
+
+type gcPauses struct {
+	Count gcActions `stm:"count"`
+	// Time and Max are in microseconds
+	Time    gcActions            `stm:"time"`
+	Max     gcActions            `stm:"max"`
+	Buckets map[string]mtx.Gauge `stm:"bucket"`
+}
+
+type gcActions struct {
+	Minor mtx.Gauge `stm:"minor"`
+	Major mtx.Gauge `stm:"major"`
+	Other mtx.Gauge `stm:"other"`
+}
+
+type logbackEvents struct {
+	Error mtx.Counter `stm:"error"`
+	Warn  mtx.Counter `stm:"warn"`
+	Info  mtx.Counter `stm:"info"`
+	Debug mtx.Counter `stm:"debug"`
+	Trace mtx.Counter `stm:"trace"`
+}
+
+type tomcatSessions struct {
+	Active    mtx.Gauge   `stm:"active"`
+	ActiveMax mtx.Gauge   `stm:"active_max"`
+	Created   mtx.Counter `stm:"created"`
+	Expired   mtx.Counter `stm:"expired"`
+	Rejected  mtx.Counter `stm:"rejected"`
+}
+
+type tomcatThreads struct {
+	Busy mtx.Gauge `stm:"busy"`
+	Idle mtx.Gauge `stm:"idle"`
+}
+
+type hikariPool struct {
+	Active   mtx.Gauge   `stm:"active"`
+	Idle     mtx.Gauge   `stm:"idle"`
+	Pending  mtx.Gauge   `stm:"pending"`
+	Timeouts mtx.Counter `stm:"timeouts"`
+}
+
+type uriRequests struct {
+	Requests map[string]mtx.Counter `stm:"requests"`
+	// Latency is the average latency of the last interval requests, in microseconds
+	Latency map[string]mtx.Gauge `stm:"latency,1000000"`
+}
+
+type requestsTotal struct {
+	count float64
+	sum   float64
+}
+
+func (s *SpringBoot2) gatherThreadStates(rawMetrics prometheus.Metrics, m *metrics) {
+	states := rawMetrics.FindByName("jvm_threads_states_threads")
+	if len(states) == 0 {
+		return
+	}
+	s.addCharts("thread_states", threadStatesCharts)
+
+	m.ThreadStates = &threadStates{}
+	for _, metric := range states {
+		value := metric.Value
+		switch metric.Labels.Get("state") {
+		case "runnable":
+			m.ThreadStates.Runnable.Add(value)
+		case "blocked":
+			m.ThreadStates.Blocked.Add(value)
+		case "waiting":
+			m.ThreadStates.Waiting.Add(value)
+		case "timed-waiting":
+			m.ThreadStates.TimedWaiting.Add(value)
+		case "new":
+			m.ThreadStates.New.Add(value)
+		case "terminated":
+			m.ThreadStates.Terminated.Add(value)
+		}
+	}
+}
+
+func (s *SpringBoot2) gatherClasses(rawMetrics prometheus.Metrics, m *metrics) {
+	loaded := rawMetrics.FindByNames("jvm_classes_loaded", "jvm_classes_loaded_classes")
+	if len(loaded) == 0 {
+		return
+	}
+	s.addCharts("classes", classesCharts)
+
+	m.Classes = &classes{}
+	m.Classes.Loaded.Set(loaded.Max())
+	m.Classes.Unloaded.Add(rawMetrics.FindByNames("jvm_classes_unloaded_total", "jvm_classes_unloaded_classes_total").Max())
+}
+
+func (s *SpringBoot2) gatherGCPauses(rawMetrics prometheus.Metrics, m *metrics) {
+	counts := rawMetrics.FindByName("jvm_gc_pause_seconds_count")
+	if len(counts) == 0 {
+		return
+	}
+	s.addCharts("gc", gcCharts)
+
+	m.GCPauses = &gcPauses{}
+	for _, metric := range counts {
+		gcAction(&m.GCPauses.Count, metric).Add(metric.Value)
+	}
+	for _, metric := range rawMetrics.FindByName("jvm_gc_pause_seconds_sum") {
+		gcAction(&m.GCPauses.Time, metric).Add(metric.Value * 1e6)
+	}
+	for _, metric := range rawMetrics.FindByName("jvm_gc_pause_seconds_max") {
+		if max := gcAction(&m.GCPauses.Max, metric); max.Value() < metric.Value*1e6 {
+			max.Set(metric.Value * 1e6)
+		}
+	}
+
+	s.gatherGCPausesHistogram(rawMetrics.FindByName("jvm_gc_pause_seconds_bucket"), m.GCPauses)
+}
+
+// gatherGCPausesHistogram converts the cumulative histogram buckets of all the GC actions and causes
+// to the pauses count per bucket.
+func (s *SpringBoot2) gatherGCPausesHistogram(rawMetrics prometheus.Metrics, m *gcPauses) {
+	if len(rawMetrics) == 0 {
+		return
+	}
+	s.addCharts("gc_histogram", gcHistogramCharts)
+
+	cumulative := make(map[string]float64)
+	for _, metric := range rawMetrics {
+		cumulative[metric.Labels.Get("le")] += metric.Value
+	}
+
+	type bucket struct {
+		le    string
+		bound float64
+	}
+	var buckets []bucket
+	for le := range cumulative {
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, bucket{le: le, bound: bound})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+
+	chart := s.charts.Get("gc_pause_histogram")
+	m.Buckets = make(map[string]mtx.Gauge)
+
+	var prev float64
+	for _, b := range buckets {
+		m.Buckets[b.le] = mtx.Gauge(cumulative[b.le] - prev)
+		prev = cumulative[b.le]
+
+		id := "gc_pauses_bucket_" + b.le
+		if chart == nil || chart.HasDim(id) {
+			continue
+		}
+		name := "+Inf"
+		if !math.IsInf(b.bound, 1) {
+			name = strconv.FormatFloat(b.bound*1000, 'g', 6, 64) + "ms"
+		}
+		if err := chart.AddDim(&Dim{ID: id, Name: name, Algo: module.Incremental}); err == nil {
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func gcAction(m *gcActions, metric prometheus.Metric) *mtx.Gauge {
+	action := metric.Labels.Get("action")
+	switch {
+	case strings.Contains(action, "minor"):
+		return &m.Minor
+	case strings.Contains(action, "major"):
+		return &m.Major
+	default:
+		return &m.Other
+	}
+}
+
+func (s *SpringBoot2) gatherLogback(rawMetrics prometheus.Metrics, m *metrics) {
+	events := rawMetrics.FindByName("logback_events_total")
+	if len(events) == 0 {
+		return
+	}
+	s.addCharts("logback", logbackCharts)
+
+	m.Logback = &logbackEvents{}
+	for _, metric := range events {
+		value := metric.Value
+		switch metric.Labels.Get("level") {
+		case "error":
+			m.Logback.Error.Add(value)
+		case "warn":
+			m.Logback.Warn.Add(value)
+		case "info":
+			m.Logback.Info.Add(value)
+		case "debug":
+			m.Logback.Debug.Add(value)
+		case "trace":
+			m.Logback.Trace.Add(value)
+		}
+	}
+}
+
+// gatherTomcat gathers the embedded Tomcat metrics, the Spring Boot 2.0 metrics names don't have the units suffixes.
+func (s *SpringBoot2) gatherTomcat(rawMetrics prometheus.Metrics, m *metrics) {
+	if active := rawMetrics.FindByNames("tomcat_sessions_active_current", "tomcat_sessions_active_current_sessions"); len(active) > 0 {
+		s.addCharts("tomcat_sessions", tomcatSessionsCharts)
+
+		m.TomcatSessions = &tomcatSessions{}
+		m.TomcatSessions.Active.Set(active.Max())
+		m.TomcatSessions.ActiveMax.Set(rawMetrics.FindByNames("tomcat_sessions_active_max", "tomcat_sessions_active_max_sessions").Max())
+		m.TomcatSessions.Created.Add(rawMetrics.FindByNames("tomcat_sessions_created_total", "tomcat_sessions_created_sessions_total").Max())
+		m.TomcatSessions.Expired.Add(rawMetrics.FindByNames("tomcat_sessions_expired_total", "tomcat_sessions_expired_sessions_total").Max())
+		m.TomcatSessions.Rejected.Add(rawMetrics.FindByNames("tomcat_sessions_rejected_total", "tomcat_sessions_rejected_sessions_total").Max())
+	}
+
+	if current := rawMetrics.FindByNames("tomcat_threads_current", "tomcat_threads_current_threads"); len(current) > 0 {
+		s.addCharts("tomcat_threads", tomcatThreadsCharts)
+
+		// the threads are summed up across the connectors
+		busy := sum(rawMetrics.FindByNames("tomcat_threads_busy", "tomcat_threads_busy_threads"))
+		m.TomcatThreads = &tomcatThreads{}
+		m.TomcatThreads.Busy.Set(busy)
+		m.TomcatThreads.Idle.Set(math.Max(sum(current)-busy, 0))
+	}
+}
+
+func (s *SpringBoot2) gatherHikariCP(rawMetrics prometheus.Metrics, m *metrics) {
+	pools := make(map[string]*hikariPool)
+
+	pool := func(metric prometheus.Metric) *hikariPool {
+		name := metric.Labels.Get("pool")
+		id := nameReplacer.Replace(name)
+		if !s.activePools[id] {
+			s.activePools[id] = true
+			_ = s.charts.Add(*newInstanceCharts(hikariPoolCharts, id, name)...)
+		}
+		if _, ok := pools[id]; !ok {
+			pools[id] = &hikariPool{}
+		}
+		return pools[id]
+	}
+
+	for _, metric := range rawMetrics.FindByName("hikaricp_connections_active") {
+		pool(metric).Active.Set(metric.Value)
+	}
+	for _, metric := range rawMetrics.FindByName("hikaricp_connections_idle") {
+		pool(metric).Idle.Set(metric.Value)
+	}
+	for _, metric := range rawMetrics.FindByName("hikaricp_connections_pending") {
+		pool(metric).Pending.Set(metric.Value)
+	}
+	for _, metric := range rawMetrics.FindByName("hikaricp_connections_timeout_total") {
+		pool(metric).Timeouts.Add(metric.Value)
+	}
+
+	m.HikariCP = pools
+}
+
+// gatherURIs gathers the requests rates and the average latencies per uri and method, the uris are filtered
+// by the uris filter.
+func (s *SpringBoot2) gatherURIs(rawMetrics prometheus.Metrics, m *metrics) {
+	totals := make(map[string]map[string]*requestsTotal)
+
+	total := func(metric prometheus.Metric) *requestsTotal {
+		uri, method := metric.Labels.Get("uri"), metric.Labels.Get("method")
+		if uri == "" || method == "" || (s.urisFilter != nil && !s.urisFilter.MatchString(uri)) {
+			return nil
+		}
+		if _, ok := totals[uri]; !ok {
+			totals[uri] = make(map[string]*requestsTotal)
+		}
+		if _, ok := totals[uri][method]; !ok {
+			totals[uri][method] = &requestsTotal{}
+		}
+		return totals[uri][method]
+	}
+
+	for _, metric := range rawMetrics.FindByName("http_server_requests_seconds_count") {
+		if t := total(metric); t != nil {
+			t.count += metric.Value
+		}
+	}
+	for _, metric := range rawMetrics.FindByName("http_server_requests_seconds_sum") {
+		if t := total(metric); t != nil {
+			t.sum += metric.Value
+		}
+	}
+
+	uris := make([]string, 0, len(totals))
+	for uri := range totals {
+		uris = append(uris, uri)
+	}
+	// the dims are added in the same order every time
+	sort.Strings(uris)
+
+	m.URIs = make(map[string]*uriRequests)
+	for _, uri := range uris {
+		id := nameReplacer.Replace(uri)
+		if !s.activeURIs[id] {
+			s.activeURIs[id] = true
+			_ = s.charts.Add(*newInstanceCharts(uriCharts, id, uri)...)
+		}
+
+		requests := &uriRequests{
+			Requests: make(map[string]mtx.Counter),
+			Latency:  make(map[string]mtx.Gauge),
+		}
+		m.URIs[id] = requests
+
+		methods := make([]string, 0, len(totals[uri]))
+		for method := range totals[uri] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			cur := *totals[uri][method]
+
+			var latency float64
+			key := uri + " " + method
+			if prev, ok := s.prevRequests[key]; ok && cur.count > prev.count {
+				latency = (cur.sum - prev.sum) / (cur.count - prev.count)
+			}
+			s.prevRequests[key] = cur
+
+			var count mtx.Counter
+			count.Add(cur.count)
+			requests.Requests[method] = count
+			requests.Latency[method] = mtx.Gauge(latency)
+
+			s.addURIDims(id, method)
+		}
+	}
+}
+
+func (s *SpringBoot2) addURIDims(id, method string) {
+	for _, dim := range []*Dim{
+		{ID: "uri_" + id + "_requests_" + method, Name: method, Algo: module.Incremental},
+		{ID: "uri_" + id + "_latency_" + method, Name: method, Div: 1000},
+	} {
+		chart := s.charts.Get(strings.TrimSuffix(dim.ID, "_"+method))
+		if chart == nil || chart.HasDim(dim.ID) {
+			continue
+		}
+		if err := chart.AddDim(dim); err == nil {
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+// addCharts adds the optional charts group once.
+func (s *SpringBoot2) addCharts(group string, charts Charts) {
+	if s.activeCharts[group] {
+		return
+	}
+	s.activeCharts[group] = true
+	_ = s.charts.Add(*charts.Copy()...)
+}
+
+func sum(rawMetrics prometheus.Metrics) float64 {
+	var v float64
+	for _, metric := range rawMetrics {
+		v += metric.Value
+	}
+	return v
+}
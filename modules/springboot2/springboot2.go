@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/netdata/go.d.plugin/pkg/matcher"
 	mtx "github.com/netdata/go.d.plugin/pkg/metrics"
 	"github.com/netdata/go.d.plugin/pkg/prometheus"
 	"github.com/netdata/go.d.plugin/pkg/stm"
@@ -20,6 +21,8 @@ func init() {
 
 const (
 	defaultHTTPTimeout = time.Second
+	// the actuator endpoints (including the prometheus endpoint itself) are not charted by default
+	defaultURIsFilter = "!/actuator* *"
 )
 
 // New returns SpringBoot2 instance with default values
@@ -28,6 +31,12 @@ func New() *SpringBoot2 {
 		HTTP: web.HTTP{
 			Client: web.Client{Timeout: web.Duration{Duration: defaultHTTPTimeout}},
 		},
+		URIsFilter:   defaultURIsFilter,
+		charts:       charts.Copy(),
+		activeCharts: make(map[string]bool),
+		activeURIs:   make(map[string]bool),
+		activePools:  make(map[string]bool),
+		prevRequests: make(map[string]requestsTotal),
 	}
 }
 
@@ -37,14 +46,27 @@ type SpringBoot2 struct {
 
 	web.HTTP `yaml:",inline"`
 
-	prom prometheus.Prometheus
+	URIsFilter string `yaml:"uris_filter"`
+
+	prom       prometheus.Prometheus
+	urisFilter matcher.Matcher
+	charts     *Charts
+
+	// activeCharts is a set of the optional charts groups that have charts
+	activeCharts map[string]bool
+	activeURIs   map[string]bool
+	activePools  map[string]bool
+	// prevRequests are the previous requests totals by uri and method, they are needed for the average latency
+	prevRequests map[string]requestsTotal
 }
 
 type metrics struct {
 	Uptime mtx.Gauge `stm:"uptime,1000"`
 
-	ThreadsDaemon mtx.Gauge `stm:"threads_daemon"`
-	Threads       mtx.Gauge `stm:"threads"`
+	ThreadsDaemon mtx.Gauge     `stm:"threads_daemon"`
+	Threads       mtx.Gauge     `stm:"threads"`
+	ThreadsPeak   mtx.Gauge     `stm:"threads_peak"`
+	ThreadStates  *threadStates `stm:"threads_states"`
 
 	Resp1xx mtx.Counter `stm:"resp_1xx"`
 	Resp2xx mtx.Counter `stm:"resp_2xx"`
@@ -56,6 +78,16 @@ type metrics struct {
 	HeapCommitted heap `stm:"heap_committed"`
 
 	MemFree mtx.Gauge `stm:"mem_free"`
+
+	// the optional metrics, they are nil if the application doesn't export them
+	Classes        *classes        `stm:"classes"`
+	GCPauses       *gcPauses       `stm:"gc_pauses"`
+	Logback        *logbackEvents  `stm:"logback_events"`
+	TomcatSessions *tomcatSessions `stm:"tomcat_sessions"`
+	TomcatThreads  *tomcatThreads  `stm:"tomcat_threads"`
+
+	HikariCP map[string]*hikariPool  `stm:"hikaricp"`
+	URIs     map[string]*uriRequests `stm:"uri"`
 }
 
 type heap struct {
@@ -75,6 +107,16 @@ func (s *SpringBoot2) Init() bool {
 		return false
 	}
 	s.prom = prometheus.New(client, s.Request)
+
+	if s.URIsFilter != "" {
+		f, err := matcher.NewSimplePatternsMatcher(s.URIsFilter)
+		if err != nil {
+			s.Errorf("error on creating uris filter : %v", err)
+			return false
+		}
+		s.urisFilter = matcher.WithCache(f)
+	}
+
 	return true
 }
 
@@ -91,8 +133,8 @@ func (s *SpringBoot2) Check() bool {
 }
 
 // Charts creates Charts
-func (SpringBoot2) Charts() *Charts {
-	return charts.Copy()
+func (s SpringBoot2) Charts() *Charts {
+	return s.charts
 }
 
 // Collect collects metrics
@@ -113,12 +155,21 @@ func (s *SpringBoot2) Collect() map[string]int64 {
 	// threads
 	m.ThreadsDaemon.Set(rawMetrics.FindByNames("jvm_threads_daemon", "jvm_threads_daemon_threads").Max())
 	m.Threads.Set(rawMetrics.FindByNames("jvm_threads_live", "jvm_threads_live_threads").Max())
+	m.ThreadsPeak.Set(rawMetrics.FindByNames("jvm_threads_peak", "jvm_threads_peak_threads").Max())
+	s.gatherThreadStates(rawMetrics, &m)
 
 	// heap memory
 	gatherHeap(rawMetrics.FindByName("jvm_memory_used_bytes"), &m.HeapUsed)
 	gatherHeap(rawMetrics.FindByName("jvm_memory_committed_bytes"), &m.HeapCommitted)
 	m.MemFree.Set(m.HeapCommitted.Sum() - m.HeapUsed.Sum())
 
+	s.gatherClasses(rawMetrics, &m)
+	s.gatherGCPauses(rawMetrics, &m)
+	s.gatherLogback(rawMetrics, &m)
+	s.gatherTomcat(rawMetrics, &m)
+	s.gatherHikariCP(rawMetrics, &m)
+	s.gatherURIs(rawMetrics, &m)
+
 	return stm.ToMap(m)
 }
 
@@ -4,9 +4,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -31,21 +33,51 @@ func TestSpringboot2_Collect(t *testing.T) {
 	assert.EqualValues(
 		t,
 		map[string]int64{
-			"threads":                 23,
-			"threads_daemon":          21,
-			"resp_1xx":                1,
-			"resp_2xx":                19,
-			"resp_3xx":                1,
-			"resp_4xx":                4,
-			"resp_5xx":                1,
-			"heap_used_eden":          129649936,
-			"heap_used_survivor":      8900136,
-			"heap_used_old":           17827920,
-			"heap_committed_eden":     153616384,
-			"heap_committed_survivor": 8912896,
-			"heap_committed_old":      40894464,
-			"mem_free":                47045752,
-			"uptime":                  191730,
+			"threads":                          23,
+			"threads_daemon":                   21,
+			"resp_1xx":                         1,
+			"resp_2xx":                         19,
+			"resp_3xx":                         1,
+			"resp_4xx":                         4,
+			"resp_5xx":                         1,
+			"heap_used_eden":                   129649936,
+			"heap_used_survivor":               8900136,
+			"heap_used_old":                    17827920,
+			"heap_committed_eden":              153616384,
+			"heap_committed_survivor":          8912896,
+			"heap_committed_old":               40894464,
+			"mem_free":                         47045752,
+			"uptime":                           191730,
+			"threads_peak":                     25,
+			"classes_loaded":                   7846,
+			"classes_unloaded":                 0,
+			"gc_pauses_count_minor":            2,
+			"gc_pauses_count_major":            0,
+			"gc_pauses_count_other":            0,
+			"gc_pauses_time_minor":             60000,
+			"gc_pauses_time_major":             0,
+			"gc_pauses_time_other":             0,
+			"gc_pauses_max_minor":              0,
+			"gc_pauses_max_major":              0,
+			"gc_pauses_max_other":              0,
+			"logback_events_error":             0,
+			"logback_events_warn":              0,
+			"logback_events_info":              41,
+			"logback_events_debug":             0,
+			"logback_events_trace":             0,
+			"tomcat_sessions_active":           0,
+			"tomcat_sessions_active_max":       0,
+			"tomcat_sessions_created":          0,
+			"tomcat_sessions_expired":          0,
+			"tomcat_sessions_rejected":         0,
+			"tomcat_threads_busy":              1,
+			"tomcat_threads_idle":              9,
+			"uri__hello_requests_GET":          7,
+			"uri__hello_latency_GET":           0,
+			"uri__**_requests_GET":             3,
+			"uri__**_latency_GET":              0,
+			"uri__**_favicon_ico_requests_GET": 5,
+			"uri__**_favicon_ico_latency_GET":  0,
 		},
 		job1.Collect(),
 	)
@@ -57,21 +89,59 @@ func TestSpringboot2_Collect(t *testing.T) {
 	assert.EqualValues(
 		t,
 		map[string]int64{
-			"threads":                 36,
-			"threads_daemon":          22,
-			"resp_1xx":                0,
-			"resp_2xx":                57740,
-			"resp_3xx":                0,
-			"resp_4xx":                4,
-			"resp_5xx":                0,
-			"heap_used_eden":          18052960,
-			"heap_used_survivor":      302704,
-			"heap_used_old":           40122672,
-			"heap_committed_eden":     21430272,
-			"heap_committed_survivor": 2621440,
-			"heap_committed_old":      53182464,
-			"mem_free":                18755840,
-			"uptime":                  45501125,
+			"threads":                          36,
+			"threads_daemon":                   22,
+			"resp_1xx":                         0,
+			"resp_2xx":                         57740,
+			"resp_3xx":                         0,
+			"resp_4xx":                         4,
+			"resp_5xx":                         0,
+			"heap_used_eden":                   18052960,
+			"heap_used_survivor":               302704,
+			"heap_used_old":                    40122672,
+			"heap_committed_eden":              21430272,
+			"heap_committed_survivor":          2621440,
+			"heap_committed_old":               53182464,
+			"mem_free":                         18755840,
+			"uptime":                           45501125,
+			"threads_peak":                     36,
+			"threads_states_runnable":          10,
+			"threads_states_blocked":           0,
+			"threads_states_waiting":           22,
+			"threads_states_timed_waiting":     4,
+			"threads_states_new":               0,
+			"threads_states_terminated":        0,
+			"classes_loaded":                   12360,
+			"classes_unloaded":                 0,
+			"gc_pauses_count_minor":            1269,
+			"gc_pauses_count_major":            1,
+			"gc_pauses_count_other":            0,
+			"gc_pauses_time_minor":             5909000,
+			"gc_pauses_time_major":             100000,
+			"gc_pauses_time_other":             0,
+			"gc_pauses_max_minor":              4000,
+			"gc_pauses_max_major":              0,
+			"gc_pauses_max_other":              0,
+			"logback_events_error":             0,
+			"logback_events_warn":              1,
+			"logback_events_info":              30,
+			"logback_events_debug":             0,
+			"logback_events_trace":             0,
+			"tomcat_sessions_active":           0,
+			"tomcat_sessions_active_max":       0,
+			"tomcat_sessions_created":          0,
+			"tomcat_sessions_expired":          0,
+			"tomcat_sessions_rejected":         0,
+			"tomcat_threads_busy":              1,
+			"tomcat_threads_idle":              9,
+			"uri__search_form_requests_GET":    13,
+			"uri__search_form_latency_GET":     0,
+			"uri__search__requests_GET":        1,
+			"uri__search__latency_GET":         0,
+			"uri__**_requests_GET":             4,
+			"uri__**_latency_GET":              0,
+			"uri__**_favicon_ico_requests_GET": 9,
+			"uri__**_favicon_ico_latency_GET":  0,
 		},
 		job2.Collect(),
 	)
@@ -99,3 +169,109 @@ func TestSpringBoot2_Charts(t *testing.T) {
 	assert.True(t, charts.Has("response_codes"))
 	assert.True(t, charts.Has("uptime"))
 }
+
+func TestSpringBoot2_Collect_Micrometer(t *testing.T) {
+	testdata3, err := ioutil.ReadFile("tests/testdata3.txt")
+	require.NoError(t, err)
+
+	var scrapes int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scrapes++
+		data := string(testdata3)
+		if scrapes > 2 {
+			// the first scrape is the check, 10 more GET /api/orders requests, 0.5 seconds in total
+			data = strings.NewReplacer(
+				`status="200",uri="/api/orders",} 100.0`, `status="200",uri="/api/orders",} 110.0`,
+				`status="200",uri="/api/orders",} 5.0`, `status="200",uri="/api/orders",} 5.5`,
+			).Replace(data)
+		}
+		_, _ = w.Write([]byte(data))
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.HTTP.Request.URL = ts.URL + "/actuator/prometheus"
+	require.True(t, job.Init())
+	require.True(t, job.Check())
+
+	expected := map[string]int64{
+		"threads_peak":                      45,
+		"threads_states_blocked":            1,
+		"threads_states_timed_waiting":      9,
+		"classes_loaded":                    15230,
+		"classes_unloaded":                  12,
+		"gc_pauses_count_minor":             40,
+		"gc_pauses_count_major":             2,
+		"gc_pauses_time_minor":              520000,
+		"gc_pauses_time_major":              310000,
+		"gc_pauses_max_minor":               12000,
+		"gc_pauses_max_major":               250000,
+		"gc_pauses_bucket_0.01":             30,
+		"gc_pauses_bucket_0.05":             8,
+		"gc_pauses_bucket_0.1":              3,
+		"gc_pauses_bucket_+Inf":             1,
+		"logback_events_error":              2,
+		"logback_events_warn":               7,
+		"tomcat_sessions_active":            5,
+		"tomcat_sessions_active_max":        8,
+		"tomcat_sessions_created":           25,
+		"tomcat_sessions_expired":           20,
+		"tomcat_threads_busy":               3,
+		"tomcat_threads_idle":               7,
+		"hikaricp_HikariPool-1_active":      4,
+		"hikaricp_HikariPool-1_idle":        6,
+		"hikaricp_HikariPool-1_pending":     1,
+		"hikaricp_HikariPool-1_timeouts":    3,
+		"uri__api_orders_requests_GET":      102,
+		"uri__api_orders_latency_GET":       0,
+		"uri__api_orders_requests_POST":     20,
+		"uri__api_orders_latency_POST":      0,
+		"uri__api_orders_{id}_requests_GET": 50,
+		"uri__api_orders_{id}_latency_GET":  0,
+	}
+
+	mx := job.Collect()
+	for key, value := range expected {
+		assert.Equalf(t, value, mx[key], "key '%s'", key)
+	}
+	// the actuator endpoints are filtered out by default
+	assert.NotContains(t, mx, "uri__actuator_prometheus_requests_GET")
+	assert.False(t, job.Charts().Has("uri__actuator_prometheus_requests"))
+
+	for _, id := range []string{
+		"thread_states", "classes_loaded", "gc_pauses", "gc_pause_histogram", "logback_events",
+		"tomcat_sessions", "tomcat_threads", "hikaricp_HikariPool-1_connections", "uri__api_orders_requests",
+		"uri__api_orders_latency", "uri__api_orders_{id}_requests",
+	} {
+		assert.Truef(t, job.Charts().Has(id), "chart '%s'", id)
+	}
+	assert.Len(t, job.Charts().Get("gc_pause_histogram").Dims, 4)
+	assert.Len(t, job.Charts().Get("uri__api_orders_requests").Dims, 2)
+
+	mx = job.Collect()
+	assert.Equal(t, int64(112), mx["uri__api_orders_requests_GET"])
+	assert.Equal(t, int64(50000), mx["uri__api_orders_latency_GET"])
+	assert.Equal(t, int64(0), mx["uri__api_orders_latency_POST"])
+	assert.Len(t, job.Charts().Get("uri__api_orders_latency").Dims, 2)
+}
+
+func TestSpringBoot2_URIsFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testdata)
+	}))
+	defer ts.Close()
+
+	job := New()
+	job.HTTP.Request.URL = ts.URL
+	job.URIsFilter = "/hello /actuator/health"
+	require.True(t, job.Init())
+
+	mx := job.Collect()
+	assert.Equal(t, int64(7), mx["uri__hello_requests_GET"])
+	assert.Equal(t, int64(1), mx["uri__actuator_health_requests_GET"])
+	assert.NotContains(t, mx, "uri__**_requests_GET")
+
+	job = New()
+	job.URIsFilter = "*["
+	assert.False(t, job.Init())
+}
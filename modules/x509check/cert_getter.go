@@ -3,7 +3,6 @@ package x509check
 import (
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -23,6 +22,12 @@ var supportedSchemes = []string{
 	"udp",
 	"udp4",
 	"udp6",
+	"smtp",
+	"imap",
+	"pop3",
+	"ldap",
+	"postgres",
+	"mysql",
 }
 
 func newCertGetter(config Config) (certGetter, error) {
@@ -36,41 +41,54 @@ func newCertGetter(config Config) (certGetter, error) {
 		return nil, fmt.Errorf("error on parsing source : %v", err)
 	}
 
+	var starttls starttlsFunc
+
 	switch u.Scheme {
 	case "file":
-		return newFileCertGetter(u.Path), nil
+		return newFileCertGetter(u.Path, config.KeystorePassword), nil
 	case "https":
 		u.Scheme = "tcp"
-		fallthrough
 	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
-		tlsCfg, err := web.NewTLSConfig(config.ClientTLSConfig)
-
-		if err != nil {
-			return nil, fmt.Errorf("error on creating tls config : %v", err)
+	case "smtp", "imap", "pop3", "ldap", "postgres", "mysql":
+		proto := starttlsProtocols[u.Scheme]
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), proto.defaultPort)
 		}
+		u.Scheme = "tcp"
+		starttls = proto.starttls
+	default:
+		return nil, fmt.Errorf("unsupported scheme in '%s', supported schemes : %v", u, supportedSchemes)
+	}
 
-		if tlsCfg == nil {
-			tlsCfg = &tls.Config{}
-		}
+	tlsCfg, err := web.NewTLSConfig(config.ClientTLSConfig)
 
-		tlsCfg.ServerName = u.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("error on creating tls config : %v", err)
+	}
 
-		return newURLCertGetter(u, tlsCfg, config.Timeout.Duration), nil
+	if tlsCfg == nil {
+		tlsCfg = &tls.Config{}
 	}
 
-	return nil, fmt.Errorf("unsupported scheme in '%s', supported schemes : %v", u, supportedSchemes)
+	tlsCfg.ServerName = u.Hostname()
+
+	getter := newURLCertGetter(u, tlsCfg, config.Timeout.Duration)
+	getter.starttls = starttls
+
+	return getter, nil
 }
 
 type certGetter interface {
 	getCert() ([]*x509.Certificate, error)
 }
 
-func newFileCertGetter(path string) *fileCertGetter {
-	return &fileCertGetter{path: path}
+func newFileCertGetter(path, password string) *fileCertGetter {
+	return &fileCertGetter{path: path, password: password}
 }
 
 type fileCertGetter struct {
-	path string
+	path     string
+	password string
 }
 
 func (fg fileCertGetter) getCert() ([]*x509.Certificate, error) {
@@ -79,17 +97,16 @@ func (fg fileCertGetter) getCert() ([]*x509.Certificate, error) {
 		return nil, fmt.Errorf("error on reading '%s' : %v", fg.path, err)
 	}
 
-	block, _ := pem.Decode(content)
-	if block == nil {
-		return nil, fmt.Errorf("error on decoding '%s' : %v", fg.path, err)
+	certs, err := parseCerts(content, fg.password)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing certificate '%s' : %v", fg.path, err)
 	}
 
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("error on parsing certigicate '%s' : %v", fg.path, err)
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("error on decoding '%s' : no certificates found", fg.path)
 	}
 
-	return []*x509.Certificate{cert}, nil
+	return certs, nil
 }
 
 func newURLCertGetter(url *url.URL, tlsCfg *tls.Config, timeout time.Duration) *urlCertGetter {
@@ -104,9 +121,18 @@ type urlCertGetter struct {
 	url     *url.URL
 	tlsCfg  *tls.Config
 	timeout time.Duration
+	// starttls negotiates TLS upgrade before the handshake, nil for implicit TLS
+	starttls starttlsFunc
+
+	// ocspResponse is the OCSP response stapled in the last handshake
+	ocspResponse []byte
 }
 
-func (ug urlCertGetter) getCert() ([]*x509.Certificate, error) {
+func (ug *urlCertGetter) stapledOCSPResponse() []byte {
+	return ug.ocspResponse
+}
+
+func (ug *urlCertGetter) getCert() ([]*x509.Certificate, error) {
 	ipConn, err := net.DialTimeout(ug.url.Scheme, ug.url.Host, ug.timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error on dial to '%s' : %v", ug.url, err)
@@ -114,7 +140,19 @@ func (ug urlCertGetter) getCert() ([]*x509.Certificate, error) {
 
 	defer ipConn.Close()
 
-	conn := tls.Client(ipConn, ug.tlsCfg.Clone())
+	_ = ipConn.SetDeadline(time.Now().Add(ug.timeout))
+
+	if ug.starttls != nil {
+		if err := ug.starttls(ipConn); err != nil {
+			return nil, fmt.Errorf("error on starttls negotiation with '%s' : %v", ug.url, err)
+		}
+	}
+
+	// the chain and the hostname are verified by the module, so certificates are available even if verification fails
+	tlsCfg := ug.tlsCfg.Clone()
+	tlsCfg.InsecureSkipVerify = true
+
+	conn := tls.Client(ipConn, tlsCfg)
 
 	defer conn.Close()
 
@@ -122,7 +160,8 @@ func (ug urlCertGetter) getCert() ([]*x509.Certificate, error) {
 		return nil, fmt.Errorf("error on ssl handshake with '%s' : %v", ug.url, err)
 	}
 
-	certs := conn.ConnectionState().PeerCertificates
+	state := conn.ConnectionState()
+	ug.ocspResponse = state.OCSPResponse
 
-	return certs, nil
+	return state.PeerCertificates, nil
 }
@@ -7,6 +7,10 @@ type (
 	Charts = module.Charts
 	// Dims is an alias for module.Dims
 	Dims = module.Dims
+	// Chart is an alias for module.Chart
+	Chart = module.Chart
+	// Dim is an alias for module.Dim
+	Dim = module.Dim
 	// Vars is an alias for module.Vars
 	Vars = module.Vars
 )
@@ -26,4 +30,84 @@ var charts = Charts{
 			{ID: "days_until_expiration_critical"},
 		},
 	},
+	{
+		ID:    "chain_time_until_expiration",
+		Title: "Time Until Chain Certificates Expiration",
+		Units: "seconds",
+		Fam:   "expiration time",
+		Ctx:   "x509check.chain_time_until_expiration",
+	},
+	{
+		ID:    "verification",
+		Title: "Certificate Verification Status",
+		Units: "boolean",
+		Fam:   "verification",
+		Ctx:   "x509check.verification",
+		Dims: Dims{
+			{ID: "chain_verified", Name: "chain"},
+			{ID: "hostname_verified", Name: "hostname"},
+		},
+	},
+	{
+		ID:    "key_size",
+		Title: "Certificate Public Key Size",
+		Units: "bits",
+		Fam:   "key",
+		Ctx:   "x509check.key_size",
+	},
+	{
+		ID:    "weakness",
+		Title: "Certificate Weakness",
+		Units: "boolean",
+		Fam:   "key",
+		Ctx:   "x509check.weakness",
+		Dims: Dims{
+			{ID: "weak_key", Name: "weak key"},
+			{ID: "weak_signature", Name: "weak signature"},
+		},
+	},
+	{
+		ID:    "ocsp_status",
+		Title: "Certificate OCSP Revocation Status",
+		Units: "boolean",
+		Fam:   "revocation",
+		Ctx:   "x509check.ocsp_status",
+		Dims: Dims{
+			{ID: "ocsp_good", Name: "good"},
+			{ID: "ocsp_revoked", Name: "revoked"},
+			{ID: "ocsp_unknown", Name: "unknown"},
+		},
+	},
+	{
+		ID:    "ocsp_response_age",
+		Title: "Certificate OCSP Response Age",
+		Units: "seconds",
+		Fam:   "revocation",
+		Ctx:   "x509check.ocsp_response_age",
+		Dims: Dims{
+			{ID: "ocsp_this_update_age", Name: "since this update"},
+			{ID: "ocsp_until_next_update", Name: "until next update"},
+		},
+	},
+	{
+		ID:    "crl_status",
+		Title: "Certificate CRL Revocation Status",
+		Units: "boolean",
+		Fam:   "revocation",
+		Ctx:   "x509check.crl_status",
+		Dims: Dims{
+			{ID: "crl_good", Name: "good"},
+			{ID: "crl_revoked", Name: "revoked"},
+			{ID: "crl_unknown", Name: "unknown"},
+		},
+	},
+}
+
+// certsExpirationChart is used instead of the chain charts when a directory or a glob pattern is scanned.
+var certsExpirationChart = Chart{
+	ID:    "certs_time_until_expiration",
+	Title: "Time Until Certificates Expiration",
+	Units: "seconds",
+	Fam:   "expiration time",
+	Ctx:   "x509check.certs_time_until_expiration",
 }
@@ -0,0 +1,231 @@
+package x509check
+
+import (
+	"bytes"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	minRSAKeySize   = 2048
+	minECDSAKeySize = 256
+)
+
+var weakSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA:    true,
+	x509.MD5WithRSA:    true,
+	x509.SHA1WithRSA:   true,
+	x509.DSAWithSHA1:   true,
+	x509.ECDSAWithSHA1: true,
+}
+
+func (x X509Check) collectExpiration(metrics map[string]int64, certs []*x509.Certificate) {
+	metrics["expiry"] = int64(time.Until(certs[0].NotAfter).Seconds())
+	metrics["days_until_expiration_warning"] = int64(x.DaysUntilWarn)
+	metrics["days_until_expiration_critical"] = int64(x.DaysUntilCrit)
+}
+
+// collectChainExpiration collects expiration time of every certificate in the chain (or in the bundle file).
+// Every certificate has its own dimension, dimensions of certificates that are gone are removed.
+func (x *X509Check) collectChainExpiration(metrics map[string]int64, certs []*x509.Certificate) {
+	seen := make(map[string]string)
+
+	for _, cert := range certs {
+		id := "chain_expiry_" + fingerprint(cert)
+		seen[id] = certName(cert)
+		metrics[id] = int64(time.Until(cert.NotAfter).Seconds())
+	}
+
+	updateDims(x.charts.Get("chain_time_until_expiration"), x.chainDims, seen)
+}
+
+// updateDims adds dimensions (id to name) that are seen for the first time and removes dimensions that are gone.
+func updateDims(chart *Chart, dims map[string]bool, seen map[string]string) {
+	for id, name := range seen {
+		if !dims[id] {
+			dims[id] = true
+			_ = chart.AddDim(&Dim{ID: id, Name: name})
+			chart.MarkNotCreated()
+		}
+	}
+
+	for id := range dims {
+		if _, ok := seen[id]; !ok {
+			delete(dims, id)
+			_ = chart.RemoveDim(id)
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func (x *X509Check) collectKey(metrics map[string]int64, cert *x509.Certificate) {
+	size := publicKeySize(cert)
+	if size == 0 {
+		return
+	}
+
+	chart := x.charts.Get("key_size")
+	alg := cert.PublicKeyAlgorithm.String()
+	id := "key_size_" + alg
+
+	if !chart.HasDim(id) {
+		chart.Dims = nil
+		_ = chart.AddDim(&Dim{ID: id, Name: alg})
+		chart.MarkNotCreated()
+	}
+
+	metrics[id] = int64(size)
+}
+
+// collectWeakness checks the leaf key size and signature algorithms of all certificates except self-signed ones,
+// signature of a self-signed (root) certificate is not used during verification.
+func collectWeakness(metrics map[string]int64, certs []*x509.Certificate) {
+	metrics["weak_key"] = 0
+	metrics["weak_signature"] = 0
+
+	if isWeakKey(certs[0]) {
+		metrics["weak_key"] = 1
+	}
+
+	for _, cert := range certs {
+		if isSelfSigned(cert) {
+			continue
+		}
+		if weakSignatureAlgorithms[cert.SignatureAlgorithm] {
+			metrics["weak_signature"] = 1
+		}
+	}
+}
+
+func (x X509Check) collectVerification(metrics map[string]int64, certs []*x509.Certificate) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         x.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	metrics["chain_verified"] = 1
+	if _, err := certs[0].Verify(opts); err != nil {
+		x.Debugf("chain verification of '%s' failed : %v", x.Source, err)
+		metrics["chain_verified"] = 0
+	}
+
+	if x.serverName == "" {
+		return
+	}
+
+	metrics["hostname_verified"] = 1
+	if err := certs[0].VerifyHostname(x.serverName); err != nil {
+		x.Debugf("hostname verification of '%s' failed : %v", x.Source, err)
+		metrics["hostname_verified"] = 0
+	}
+}
+
+func publicKeySize(cert *x509.Certificate) int {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case *dsa.PublicKey:
+		return key.P.BitLen()
+	}
+	return 0
+}
+
+func isWeakKey(cert *x509.Certificate) bool {
+	switch cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return publicKeySize(cert) < minRSAKeySize
+	case *ecdsa.PublicKey:
+		return publicKeySize(cert) < minECDSAKeySize
+	case *dsa.PublicKey:
+		return true
+	}
+	return false
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject)
+}
+
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+func certName(cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if name == "" {
+		name = cert.Subject.String()
+	}
+	// names are single-quoted in the plugins.d protocol
+	return strings.Replace(name, "'", "", -1)
+}
+
+// collectRevocation checks the leaf certificate revocation status. The issuer is expected to be the second
+// certificate in the chain (or in the bundle file), it is mandatory for OCSP and optional for CRL check.
+func (x *X509Check) collectRevocation(metrics map[string]int64, certs []*x509.Certificate) {
+	leaf := certs[0]
+
+	var issuer *x509.Certificate
+	if len(certs) > 1 {
+		issuer = certs[1]
+	}
+
+	if x.CheckOCSP {
+		x.collectOCSP(metrics, leaf, issuer)
+	}
+
+	if x.CheckCRL || x.CRLFile != "" {
+		status, err := x.revocation.checkCRL(leaf, issuer)
+		if err != nil {
+			x.Errorf("error on CRL check of '%s' : %v", x.Source, err)
+			status = revocationUnknown
+		}
+
+		metrics["crl_good"] = 0
+		metrics["crl_revoked"] = 0
+		metrics["crl_unknown"] = 0
+		metrics["crl_"+string(status)] = 1
+	}
+}
+
+func (x *X509Check) collectOCSP(metrics map[string]int64, leaf, issuer *x509.Certificate) {
+	if issuer == nil {
+		x.Errorf("error on OCSP check of '%s' : issuer certificate is not available", x.Source)
+		return
+	}
+
+	var stapled []byte
+	if getter, ok := x.certGetter.(stapledOCSPGetter); ok {
+		stapled = getter.stapledOCSPResponse()
+	}
+
+	resp, err := x.revocation.checkOCSP(leaf, issuer, stapled)
+	if err != nil {
+		x.Errorf("error on OCSP check of '%s' : %v", x.Source, err)
+		return
+	}
+
+	metrics["ocsp_good"] = 0
+	metrics["ocsp_revoked"] = 0
+	metrics["ocsp_unknown"] = 0
+	metrics["ocsp_"+string(ocspStatus(resp.Status))] = 1
+
+	metrics["ocsp_this_update_age"] = int64(time.Since(resp.ThisUpdate).Seconds())
+	if !resp.NextUpdate.IsZero() {
+		metrics["ocsp_until_next_update"] = int64(time.Until(resp.NextUpdate).Seconds())
+	}
+}
@@ -0,0 +1,192 @@
+package x509check
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+const (
+	jksMagic   = 0xfeedfeed
+	jksVersion = 2
+
+	jksPrivateKeyTag   = 1
+	jksTrustedCertTag  = 2
+	maxJKSElementSize  = 1 << 20
+	maxJKSEntriesCount = 1 << 16
+)
+
+var pemHeader = []byte("-----BEGIN ")
+
+// parseCerts parses certificates from PEM, DER, JKS or PKCS#12 encoded data.
+// The password is used only for PKCS#12 files, JKS certificates are stored unencrypted.
+func parseCerts(data []byte, password string) ([]*x509.Certificate, error) {
+	switch {
+	case bytes.Contains(data, pemHeader):
+		return parsePEMCerts(data)
+	case len(data) >= 4 && binary.BigEndian.Uint32(data) == jksMagic:
+		return parseJKSCerts(data)
+	}
+
+	if certs, err := x509.ParseCertificates(data); err == nil {
+		return certs, nil
+	}
+
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("unknown format (not PEM, DER, JKS or PKCS#12) : %v", err)
+	}
+
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+func parsePEMCerts(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+// parseJKSCerts parses Java KeyStore, see sun.security.provider.JavaKeyStore.
+// Private keys are skipped, the certificate chains of the key entries and the trusted certificates are returned.
+// The integrity of the keystore isn't checked, that requires the keystore password.
+func parseJKSCerts(data []byte) ([]*x509.Certificate, error) {
+	r := jksReader{r: bytes.NewReader(data)}
+
+	if r.readUint32() != jksMagic {
+		return nil, errors.New("not a JKS keystore")
+	}
+	version := r.readUint32()
+	if r.err == nil && version != 1 && version != jksVersion {
+		return nil, fmt.Errorf("unsupported JKS version %d", version)
+	}
+
+	count := r.readUint32()
+	if r.err == nil && count > maxJKSEntriesCount {
+		return nil, fmt.Errorf("too many JKS entries (%d)", count)
+	}
+
+	var certs []*x509.Certificate
+
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		tag := r.readUint32()
+		r.readUTF()    // alias
+		r.readUint64() // timestamp
+
+		var chainLen uint32
+		switch tag {
+		case jksPrivateKeyTag:
+			r.readBytes() // encrypted private key
+			chainLen = r.readUint32()
+		case jksTrustedCertTag:
+			chainLen = 1
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("unsupported JKS entry tag %d", tag)
+			}
+		}
+
+		for j := uint32(0); j < chainLen && r.err == nil; j++ {
+			if version == jksVersion {
+				r.readUTF() // certificate type
+			}
+			der := r.readBytes()
+			if r.err != nil {
+				break
+			}
+
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("error on reading JKS keystore : %v", r.err)
+	}
+
+	return certs, nil
+}
+
+// jksReader reads big-endian JKS elements, the first error is kept and makes subsequent reads no-op.
+type jksReader struct {
+	r   io.Reader
+	err error
+}
+
+func (jr *jksReader) read(n int) []byte {
+	if jr.err != nil {
+		return nil
+	}
+	if n > maxJKSElementSize {
+		jr.err = fmt.Errorf("element size %d exceeds the limit", n)
+		return nil
+	}
+
+	buf := make([]byte, n)
+	if _, jr.err = io.ReadFull(jr.r, buf); jr.err != nil {
+		return nil
+	}
+	return buf
+}
+
+func (jr *jksReader) readUint32() uint32 {
+	if b := jr.read(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (jr *jksReader) readUint64() uint64 {
+	if b := jr.read(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (jr *jksReader) readUTF() string {
+	b := jr.read(2)
+	if b == nil {
+		return ""
+	}
+	return string(jr.read(int(binary.BigEndian.Uint16(b))))
+}
+
+func (jr *jksReader) readBytes() []byte {
+	return jr.read(int(jr.readUint32()))
+}
@@ -0,0 +1,170 @@
+package x509check
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const maxRevocationResponseSize = 10 << 20
+
+type revocationStatus string
+
+const (
+	revocationGood    revocationStatus = "good"
+	revocationRevoked revocationStatus = "revoked"
+	revocationUnknown revocationStatus = "unknown"
+)
+
+func ocspStatus(status int) revocationStatus {
+	switch status {
+	case ocsp.Good:
+		return revocationGood
+	case ocsp.Revoked:
+		return revocationRevoked
+	}
+	return revocationUnknown
+}
+
+// stapledOCSPGetter is implemented by cert getters that can provide OCSP response stapled in the TLS handshake.
+type stapledOCSPGetter interface {
+	stapledOCSPResponse() []byte
+}
+
+type revocationChecker struct {
+	httpClient *http.Client
+	crlFile    string
+	// crls caches downloaded CRLs by distribution point until their next update
+	crls map[string]*pkix.CertificateList
+}
+
+func newRevocationChecker(httpClient *http.Client, crlFile string) *revocationChecker {
+	return &revocationChecker{
+		httpClient: httpClient,
+		crlFile:    crlFile,
+		crls:       make(map[string]*pkix.CertificateList),
+	}
+}
+
+// checkOCSP returns the OCSP response for the certificate. Stapled response is used if it is available,
+// otherwise the certificate AIA OCSP responders are queried.
+func (rc revocationChecker) checkOCSP(cert, issuer *x509.Certificate, stapled []byte) (*ocsp.Response, error) {
+	if len(stapled) > 0 {
+		return ocsp.ParseResponseForCert(stapled, cert, issuer)
+	}
+
+	if len(cert.OCSPServer) == 0 {
+		return nil, errors.New("certificate has no OCSP responder URL")
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating OCSP request : %v", err)
+	}
+
+	var lastErr error
+	for _, server := range cert.OCSPServer {
+		body, err := rc.doRequest(http.MethodPost, server, req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return ocsp.ParseResponseForCert(body, cert, issuer)
+	}
+
+	return nil, lastErr
+}
+
+// checkCRL checks whether the certificate serial number is in the CRL.
+// Local CRL file is used if it is set, otherwise the certificate CRL distribution points.
+func (rc *revocationChecker) checkCRL(cert, issuer *x509.Certificate) (revocationStatus, error) {
+	crl, err := rc.getCRL(cert)
+	if err != nil {
+		return "", err
+	}
+
+	if issuer != nil {
+		if err := issuer.CheckCRLSignature(crl); err != nil {
+			return "", fmt.Errorf("error on checking CRL signature : %v", err)
+		}
+	}
+
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return revocationRevoked, nil
+		}
+	}
+
+	return revocationGood, nil
+}
+
+func (rc *revocationChecker) getCRL(cert *x509.Certificate) (*pkix.CertificateList, error) {
+	if rc.crlFile != "" {
+		data, err := ioutil.ReadFile(rc.crlFile)
+		if err != nil {
+			return nil, fmt.Errorf("error on reading CRL file '%s' : %v", rc.crlFile, err)
+		}
+		return x509.ParseCRL(data)
+	}
+
+	if len(cert.CRLDistributionPoints) == 0 {
+		return nil, errors.New("certificate has no CRL distribution points")
+	}
+
+	var lastErr error
+	for _, point := range cert.CRLDistributionPoints {
+		if crl, ok := rc.crls[point]; ok && time.Now().Before(crl.TBSCertList.NextUpdate) {
+			return crl, nil
+		}
+
+		data, err := rc.doRequest(http.MethodGet, point, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		crl, err := x509.ParseCRL(data)
+		if err != nil {
+			lastErr = fmt.Errorf("error on parsing CRL from '%s' : %v", point, err)
+			continue
+		}
+
+		rc.crls[point] = crl
+		return crl, nil
+	}
+
+	return nil, lastErr
+}
+
+func (rc revocationChecker) doRequest(method, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+
+	resp, err := rc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to '%s' : %v", url, err)
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("'%s' returned HTTP status code %d", url, resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxRevocationResponseSize))
+}
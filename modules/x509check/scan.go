@@ -0,0 +1,109 @@
+package x509check
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// scanPattern returns the glob pattern of the files to scan if the source is a directory or a glob pattern.
+func scanPattern(source string) (string, bool) {
+	if !strings.HasPrefix(source, "file://") {
+		return "", false
+	}
+	// the path isn't parsed as url, '?' is a glob meta character here
+	path := strings.TrimPrefix(source, "file://")
+
+	if strings.ContainsAny(path, "*?[") {
+		return path, true
+	}
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return filepath.Join(path, "*"), true
+	}
+	return "", false
+}
+
+// collectScan collects expiration time of every certificate in every file that matches the scan pattern.
+// Every certificate (identified by the certificate and the file) has its own dimension,
+// the soonest expiration time is reported as 'expiry'.
+func (x *X509Check) collectScan() map[string]int64 {
+	paths, err := filepath.Glob(x.scanPattern)
+	if err != nil {
+		x.Errorf("error on matching '%s' : %v", x.scanPattern, err)
+		return nil
+	}
+
+	metrics := make(map[string]int64)
+	seen := make(map[string]string)
+
+	for _, path := range paths {
+		certs, err := readCertFile(path, x.KeystorePassword)
+		if err != nil {
+			x.Warning(err)
+			continue
+		}
+		if len(certs) == 0 {
+			x.Debugf("no certificates found in '%s'", path)
+			continue
+		}
+
+		for _, cert := range certs {
+			id := "cert_expiry_" + scanID(path, cert)
+			seen[id] = fmt.Sprintf("%s (%s)", certName(cert), strings.Replace(path, "'", "", -1))
+
+			expiry := int64(time.Until(cert.NotAfter).Seconds())
+			metrics[id] = expiry
+
+			if v, ok := metrics["expiry"]; !ok || expiry < v {
+				metrics["expiry"] = expiry
+			}
+		}
+	}
+
+	updateDims(x.charts.Get("certs_time_until_expiration"), x.certDims, seen)
+
+	if len(seen) == 0 {
+		x.Errorf("no certificates found in '%s'", x.scanPattern)
+		return nil
+	}
+
+	metrics["days_until_expiration_warning"] = int64(x.DaysUntilWarn)
+	metrics["days_until_expiration_critical"] = int64(x.DaysUntilCrit)
+
+	return metrics
+}
+
+func readCertFile(path string, password string) ([]*x509.Certificate, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading '%s' : %v", path, err)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading '%s' : %v", path, err)
+	}
+
+	certs, err := parseCerts(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing '%s' : %v", path, err)
+	}
+
+	return certs, nil
+}
+
+func scanID(path string, cert *x509.Certificate) string {
+	h := sha1.New()
+	_, _ = h.Write([]byte(path))
+	_, _ = h.Write(cert.Raw)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
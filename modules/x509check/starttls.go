@@ -0,0 +1,301 @@
+package x509check
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// starttlsFunc negotiates TLS upgrade of a plain text protocol connection.
+type starttlsFunc func(conn net.Conn) error
+
+var starttlsProtocols = map[string]struct {
+	defaultPort string
+	starttls    starttlsFunc
+}{
+	"smtp":     {defaultPort: "25", starttls: starttlsSMTP},
+	"imap":     {defaultPort: "143", starttls: starttlsIMAP},
+	"pop3":     {defaultPort: "110", starttls: starttlsPOP3},
+	"ldap":     {defaultPort: "389", starttls: starttlsLDAP},
+	"postgres": {defaultPort: "5432", starttls: starttlsPostgres},
+	"mysql":    {defaultPort: "3306", starttls: starttlsMySQL},
+}
+
+// starttlsSMTP implements RFC 3207.
+func starttlsSMTP(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	if _, err := readSMTPReply(r, "220"); err != nil {
+		return fmt.Errorf("unexpected SMTP greeting : %v", err)
+	}
+
+	if _, err := io.WriteString(conn, "EHLO x509check\r\n"); err != nil {
+		return err
+	}
+	lines, err := readSMTPReply(r, "250")
+	if err != nil {
+		return fmt.Errorf("unexpected SMTP EHLO reply : %v", err)
+	}
+	if !hasSMTPExtension(lines, "STARTTLS") {
+		return errors.New("SMTP server doesn't support STARTTLS")
+	}
+
+	if _, err := io.WriteString(conn, "STARTTLS\r\n"); err != nil {
+		return err
+	}
+	if _, err := readSMTPReply(r, "220"); err != nil {
+		return fmt.Errorf("unexpected SMTP STARTTLS reply : %v", err)
+	}
+
+	return nil
+}
+
+// readSMTPReply reads (multiline) SMTP reply and checks its code.
+func readSMTPReply(r *bufio.Reader, code string) ([]string, error) {
+	var lines []string
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		if len(line) < 4 || line[:3] != code {
+			return nil, fmt.Errorf("'%s'", line)
+		}
+		if line[3] == ' ' {
+			return lines, nil
+		}
+	}
+}
+
+func hasSMTPExtension(lines []string, ext string) bool {
+	for _, line := range lines {
+		if len(line) < 4 {
+			continue
+		}
+		if fields := strings.Fields(line[4:]); len(fields) > 0 && strings.EqualFold(fields[0], ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// starttlsIMAP implements RFC 2595.
+func starttlsIMAP(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected IMAP greeting : '%s'", strings.TrimSpace(line))
+	}
+
+	if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		// skip untagged responses
+		if strings.HasPrefix(line, "*") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a1 OK") {
+			return fmt.Errorf("unexpected IMAP STARTTLS reply : '%s'", strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+// starttlsPOP3 implements RFC 2595.
+func starttlsPOP3(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected POP3 greeting : '%s'", strings.TrimSpace(line))
+	}
+
+	if _, err := io.WriteString(conn, "STLS\r\n"); err != nil {
+		return err
+	}
+
+	if line, err = r.ReadString('\n'); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected POP3 STLS reply : '%s'", strings.TrimSpace(line))
+	}
+
+	return nil
+}
+
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// starttlsLDAP implements RFC 4511 StartTLS extended operation.
+func starttlsLDAP(conn net.Conn) error {
+	// LDAPMessage { messageID 1, ExtendedRequest [APPLICATION 23] { requestName [0] startTLS OID } }
+	req := []byte{0x77, byte(2 + len(ldapStartTLSOID)), 0x80, byte(len(ldapStartTLSOID))}
+	req = append(req, ldapStartTLSOID...)
+	req = append([]byte{0x02, 0x01, 0x01}, req...)
+	req = append([]byte{0x30, byte(len(req))}, req...)
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+
+	// LDAPMessage SEQUENCE
+	if _, err := readBERHeader(r, 0x30); err != nil {
+		return err
+	}
+	// messageID INTEGER
+	n, err := readBERHeader(r, 0x02)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Discard(n); err != nil {
+		return err
+	}
+	// ExtendedResponse [APPLICATION 24]
+	if _, err := readBERHeader(r, 0x78); err != nil {
+		return err
+	}
+	// resultCode ENUMERATED
+	if n, err = readBERHeader(r, 0x0a); err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("unexpected LDAP result code length %d", n)
+	}
+	code, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("LDAP StartTLS failed, result code %d", code)
+	}
+
+	return nil
+}
+
+// readBERHeader reads BER tag and length, it returns the length of the content.
+func readBERHeader(r *bufio.Reader, tag byte) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != tag {
+		return 0, fmt.Errorf("unexpected LDAP BER tag 0x%x, expected 0x%x", b, tag)
+	}
+
+	b, err = r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+
+	num := int(b & 0x7f)
+	if num > 4 {
+		return 0, fmt.Errorf("unsupported LDAP BER length of %d bytes", num)
+	}
+
+	var length int
+	for i := 0; i < num; i++ {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+
+	return length, nil
+}
+
+const postgresSSLRequestCode = 80877103
+
+// starttlsPostgres sends SSLRequest message, see https://www.postgresql.org/docs/current/protocol-flow.html#id-1.10.5.7.11
+func starttlsPostgres(conn net.Conn) error {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], postgresSSLRequestCode)
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	if resp[0] != 'S' {
+		return errors.New("PostgreSQL server doesn't support SSL")
+	}
+
+	return nil
+}
+
+const (
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	mysqlCharsetUTF8            = 33
+	mysqlMaxPacketSize          = 1<<24 - 1
+)
+
+// starttlsMySQL reads the server handshake and sends SSL request packet,
+// see https://dev.mysql.com/doc/internals/en/connection-phase-packets.html
+func starttlsMySQL(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+
+	if len(payload) == 0 || payload[0] == 0xff {
+		return errors.New("MySQL server returned error packet")
+	}
+
+	// protocol version (1), server version (null terminated), connection id (4), auth data (8), filler (1)
+	idx := strings.IndexByte(string(payload[1:]), 0)
+	if idx == -1 || len(payload) < 1+idx+1+4+8+1+2 {
+		return errors.New("malformed MySQL handshake packet")
+	}
+	pos := 1 + idx + 1 + 4 + 8 + 1
+	capabilities := binary.LittleEndian.Uint16(payload[pos : pos+2])
+
+	if capabilities&mysqlClientSSL == 0 {
+		return errors.New("MySQL server doesn't support SSL")
+	}
+
+	// capability flags (4), max packet size (4), character set (1), reserved (23)
+	req := make([]byte, 4+32)
+	req[0], req[3] = 32, header[3]+1
+	binary.LittleEndian.PutUint32(req[4:8], mysqlClientProtocol41|mysqlClientSSL|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(req[8:12], mysqlMaxPacketSize)
+	req[12] = mysqlCharsetUTF8
+
+	_, err := conn.Write(req)
+	return err
+}
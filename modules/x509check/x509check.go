@@ -1,6 +1,8 @@
 package x509check
 
 import (
+	"crypto/x509"
+	"net/url"
 	"time"
 
 	"github.com/netdata/go.d.plugin/pkg/web"
@@ -31,6 +33,9 @@ func New() *X509Check {
 			DaysUntilWarn: defaultDaysUntilWarn,
 			DaysUntilCrit: defaultDaysUntilCrit,
 		},
+		charts:    charts.Copy(),
+		chainDims: make(map[string]bool),
+		certDims:  make(map[string]bool),
 	}
 }
 
@@ -39,8 +44,12 @@ type Config struct {
 	web.ClientTLSConfig `yaml:",inline"`
 	Timeout             web.Duration
 	Source              string
-	DaysUntilWarn       int `yaml:"days_until_expiration_warning"`
-	DaysUntilCrit       int `yaml:"days_until_expiration_critical"`
+	DaysUntilWarn       int    `yaml:"days_until_expiration_warning"`
+	DaysUntilCrit       int    `yaml:"days_until_expiration_critical"`
+	CheckOCSP           bool   `yaml:"check_ocsp"`
+	CheckCRL            bool   `yaml:"check_crl"`
+	CRLFile             string `yaml:"crl_file"`
+	KeystorePassword    string `yaml:"keystore_password"`
 }
 
 // X509Check X509Check module.
@@ -48,6 +57,20 @@ type X509Check struct {
 	module.Base
 	Config `yaml:",inline"`
 	certGetter
+
+	// roots is the pool the chain is verified against, nil means the system pool
+	roots *x509.CertPool
+	// serverName is the name the leaf certificate is verified against, empty for file sources
+	serverName string
+
+	revocation *revocationChecker
+
+	// scanPattern is the glob pattern of the files to scan, set if the source is a directory or a glob pattern
+	scanPattern string
+
+	charts    *Charts
+	chainDims map[string]bool
+	certDims  map[string]bool
 }
 
 // Cleanup makes cleanup.
@@ -55,6 +78,12 @@ func (X509Check) Cleanup() {}
 
 // Init makes initialization.
 func (x *X509Check) Init() bool {
+	if pattern, ok := scanPattern(x.Source); ok {
+		x.scanPattern = pattern
+		x.charts = &Charts{charts.Get("time_until_expiration").Copy(), certsExpirationChart.Copy()}
+		return true
+	}
+
 	getter, err := newCertGetter(x.Config)
 
 	if err != nil {
@@ -64,6 +93,43 @@ func (x *X509Check) Init() bool {
 
 	x.certGetter = getter
 
+	tlsCfg, err := web.NewTLSConfig(x.ClientTLSConfig)
+	if err != nil {
+		x.Errorf("error on creating tls config : %v", err)
+		return false
+	}
+	if tlsCfg != nil {
+		x.roots = tlsCfg.RootCAs
+	}
+
+	if u, err := url.Parse(x.Source); err == nil && u.Scheme != "file" {
+		x.serverName = u.Hostname()
+	}
+
+	if x.CheckOCSP || x.CheckCRL || x.CRLFile != "" {
+		client, err := web.NewHTTPClient(web.Client{Timeout: x.Timeout})
+		if err != nil {
+			x.Error(err)
+			return false
+		}
+		x.revocation = newRevocationChecker(client, x.CRLFile)
+	}
+
+	if !x.CheckOCSP {
+		_ = x.charts.Remove("ocsp_status")
+		_ = x.charts.Remove("ocsp_response_age")
+	}
+
+	if !x.CheckCRL && x.CRLFile == "" {
+		_ = x.charts.Remove("crl_status")
+	}
+
+	if x.InsecureSkipVerify {
+		_ = x.charts.Remove("verification")
+	} else if x.serverName == "" {
+		_ = x.charts.Get("verification").RemoveDim("hostname_verified")
+	}
+
 	return true
 }
 
@@ -73,12 +139,16 @@ func (x *X509Check) Check() bool {
 }
 
 // Charts creates Charts.
-func (X509Check) Charts() *Charts {
-	return charts.Copy()
+func (x X509Check) Charts() *Charts {
+	return x.charts
 }
 
 // Collect collects metrics.
 func (x *X509Check) Collect() map[string]int64 {
+	if x.scanPattern != "" {
+		return x.collectScan()
+	}
+
 	certs, err := x.getCert()
 
 	if err != nil {
@@ -87,16 +157,24 @@ func (x *X509Check) Collect() map[string]int64 {
 	}
 
 	if len(certs) == 0 {
-		x.Error("no certificate was provided by '%s'", x.Config.Source)
+		x.Errorf("no certificate was provided by '%s'", x.Config.Source)
 		return nil
 	}
 
-	now := time.Now()
-	notAfter := certs[0].NotAfter
+	metrics := make(map[string]int64)
+
+	x.collectExpiration(metrics, certs)
+	x.collectChainExpiration(metrics, certs)
+	x.collectKey(metrics, certs[0])
+	collectWeakness(metrics, certs)
 
-	return map[string]int64{
-		"expiry":                         int64(notAfter.Sub(now).Seconds()),
-		"days_until_expiration_warning":  int64(x.DaysUntilWarn),
-		"days_until_expiration_critical": int64(x.DaysUntilCrit),
+	if !x.InsecureSkipVerify {
+		x.collectVerification(metrics, certs)
 	}
+
+	if x.revocation != nil {
+		x.collectRevocation(metrics, certs)
+	}
+
+	return metrics
 }
@@ -1,10 +1,33 @@
 package x509check
 
 import (
-	"github.com/netdata/go-orchestrator/module"
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/netdata/go-orchestrator/module"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
 )
 
 func TestNew(t *testing.T) {
@@ -22,7 +45,7 @@ func TestX509Check_Charts(t *testing.T) {
 	job := New()
 
 	assert.NotNil(t, job.Charts())
-
+	assert.NoError(t, module.CheckCharts(*job.Charts()...))
 }
 
 func TestX509Check_Init(t *testing.T) {
@@ -36,9 +59,625 @@ func TestX509Check_Init(t *testing.T) {
 	job = New()
 	job.Source = "https://example.org:443"
 	assert.True(t, job.Init())
+	assert.Equal(t, "example.org", job.serverName)
+	assert.True(t, job.Charts().Get("verification").HasDim("hostname_verified"))
+
+	job = New()
+	job.Source = "file:///etc/ssl/cert.pem"
+	assert.True(t, job.Init())
+	assert.False(t, job.Charts().Get("verification").HasDim("hostname_verified"))
+
+	job = New()
+	job.Source = "https://example.org:443"
+	job.InsecureSkipVerify = true
+	assert.True(t, job.Init())
+	assert.False(t, job.Charts().Has("verification"))
+
+	job = New()
+	job.Source = "smtp://example.org"
+	assert.True(t, job.Init())
+	assert.Equal(t, "example.org:25", job.certGetter.(*urlCertGetter).url.Host)
+	assert.Equal(t, "example.org", job.serverName)
+}
+
+func TestX509Check_Check(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	job := New()
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf)
+	require.True(t, job.Init())
+	assert.True(t, job.Check())
+
+	job = New()
+	job.Source = "file://" + filepath.Join(dir, "missing.pem")
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
 }
 
-// TODO:
-func TestX509Check_Check(t *testing.T) {}
+func TestX509Check_Check_Keystore(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	p12, err := ioutil.ReadFile("testdata/keystore.p12")
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		"leaf.der":     chain.leaf.Raw,
+		"keystore.jks": newJKS(chain.leaf, chain.root),
+		"keystore.p12": p12,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+		job := New()
+		job.Source = "file://" + path
+		job.KeystorePassword = "changeit"
+		require.True(t, job.Init(), name)
+		assert.True(t, job.Check(), name)
+	}
+}
+
+func TestX509Check_Collect_FileBundle(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	job := New()
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf, chain.intermediate)
+	job.TLSCA = chain.writeBundle(t, dir, chain.root)
+	require.True(t, job.Init())
+
+	metrics := job.Collect()
+	require.NotNil(t, metrics)
+
+	assert.InDelta(t, time.Until(chain.leaf.NotAfter).Seconds(), metrics["expiry"], 5)
+	assert.InDelta(t, time.Until(chain.leaf.NotAfter).Seconds(), metrics["chain_expiry_"+fingerprint(chain.leaf)], 5)
+	assert.InDelta(t, time.Until(chain.intermediate.NotAfter).Seconds(), metrics["chain_expiry_"+fingerprint(chain.intermediate)], 5)
+	assert.Equal(t, int64(1), metrics["chain_verified"])
+	assert.Equal(t, int64(256), metrics["key_size_ECDSA"])
+	assert.Equal(t, int64(0), metrics["weak_key"])
+	assert.Equal(t, int64(0), metrics["weak_signature"])
+	assert.Len(t, job.Charts().Get("chain_time_until_expiration").Dims, 2)
+	assert.NoError(t, module.CheckCharts(*job.Charts()...))
+}
+
+func TestX509Check_Collect_ChainNotVerified(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	job := New()
+	// intermediate is missing
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf)
+	job.TLSCA = chain.writeBundle(t, dir, chain.root)
+	require.True(t, job.Init())
+
+	metrics := job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["chain_verified"])
+}
+
+func TestX509Check_Collect_ChainChanged(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	job := New()
+	path := chain.writeBundle(t, dir, chain.leaf, chain.intermediate)
+	job.Source = "file://" + path
+	require.True(t, job.Init())
+	require.NotNil(t, job.Collect())
+
+	renewed := newTestChain(t)
+	require.NoError(t, ioutil.WriteFile(path, pemEncode(renewed.leaf), 0644))
+	require.NotNil(t, job.Collect())
+
+	chart := job.Charts().Get("chain_time_until_expiration")
+	require.Len(t, chart.Dims, 1)
+	assert.Equal(t, "chain_expiry_"+fingerprint(renewed.leaf), chart.Dims[0].ID)
+}
+
+func TestX509Check_Collect_URL(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	ca := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(ca, pemEncode(ts.Certificate()), 0644))
+
+	job := New()
+	job.Source = ts.URL
+	job.TLSCA = ca
+	require.True(t, job.Init())
+
+	metrics := job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(1), metrics["chain_verified"])
+	assert.Equal(t, int64(1), metrics["hostname_verified"])
+
+	// system pool doesn't have the test certificate, but certificates are collected anyway
+	job = New()
+	job.Source = ts.URL
+	require.True(t, job.Init())
+
+	metrics = job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["chain_verified"])
+}
+
+func TestX509Check_Collect_STARTTLS(t *testing.T) {
+	tests := map[string]func(conn net.Conn, r *bufio.Reader) error{
+		"smtp": func(conn net.Conn, r *bufio.Reader) error {
+			return converse(conn, r, "220 test ESMTP\r\n", "250-test\r\n250-PIPELINING\r\n250 STARTTLS\r\n", "220 ready\r\n")
+		},
+		"imap": func(conn net.Conn, r *bufio.Reader) error {
+			return converse(conn, r, "* OK ready\r\n", "a1 OK begin TLS\r\n")
+		},
+		"pop3": func(conn net.Conn, r *bufio.Reader) error {
+			return converse(conn, r, "+OK ready\r\n", "+OK begin TLS\r\n")
+		},
+		"ldap": func(conn net.Conn, r *bufio.Reader) error {
+			req := make([]byte, 31)
+			if _, err := io.ReadFull(r, req); err != nil {
+				return err
+			}
+			if !bytes.Contains(req, []byte(ldapStartTLSOID)) {
+				return fmt.Errorf("unexpected request %x", req)
+			}
+			_, err := conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+			return err
+		},
+		"postgres": func(conn net.Conn, r *bufio.Reader) error {
+			req := make([]byte, 8)
+			if _, err := io.ReadFull(r, req); err != nil {
+				return err
+			}
+			if binary.BigEndian.Uint32(req[4:]) != postgresSSLRequestCode {
+				return fmt.Errorf("unexpected request %x", req)
+			}
+			_, err := conn.Write([]byte("S"))
+			return err
+		},
+		"mysql": func(conn net.Conn, r *bufio.Reader) error {
+			payload := append([]byte{0x0a}, "8.0.0\x00"...)
+			payload = append(payload, make([]byte, 4+8+1)...)
+			payload = append(payload, 0x00, 0x0a)
+			payload = append(payload, make([]byte, 16)...)
+			if _, err := conn.Write(append([]byte{byte(len(payload)), 0, 0, 0}, payload...)); err != nil {
+				return err
+			}
+			req := make([]byte, 36)
+			if _, err := io.ReadFull(r, req); err != nil {
+				return err
+			}
+			if binary.LittleEndian.Uint32(req[4:8])&mysqlClientSSL == 0 || req[3] != 1 {
+				return fmt.Errorf("unexpected request %x", req)
+			}
+			return nil
+		},
+	}
+
+	for scheme, negotiate := range tests {
+		t.Run(scheme, func(t *testing.T) {
+			addr, errCh := newSTARTTLSServer(t, negotiate)
+
+			job := New()
+			job.Source = scheme + "://" + addr
+			job.InsecureSkipVerify = true
+			require.True(t, job.Init())
+
+			metrics := job.Collect()
+			require.NoError(t, <-errCh)
+			require.NotNil(t, metrics)
+			assert.Contains(t, metrics, "expiry")
+		})
+	}
+}
+
+func TestX509Check_Collect_STARTTLSNotSupported(t *testing.T) {
+	addr, _ := newSTARTTLSServer(t, func(conn net.Conn, r *bufio.Reader) error {
+		return converse(conn, r, "220 test ESMTP\r\n", "250-test\r\n250 PIPELINING\r\n")
+	})
+
+	job := New()
+	job.Source = "smtp://" + addr
+	require.True(t, job.Init())
+
+	assert.Nil(t, job.Collect())
+}
+
+// newSTARTTLSServer accepts one connection, negotiates the upgrade and makes the TLS handshake.
+func newSTARTTLSServer(t *testing.T, negotiate func(conn net.Conn, r *bufio.Reader) error) (string, chan error) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tlsCfg := ts.TLS.Clone()
+	ts.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+
+		r := bufio.NewReader(conn)
+		if err := negotiate(conn, r); err != nil {
+			errCh <- err
+			return
+		}
+		// the client may send the hello before the negotiation reply is read, so it can be already buffered
+		errCh <- tls.Server(bufferedConn{Conn: conn, r: r}, tlsCfg).Handshake()
+	}()
+
+	return ln.Addr().String(), errCh
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+// converse writes the first reply and then writes every next reply after reading a line from the client.
+func converse(conn net.Conn, r *bufio.Reader, replies ...string) error {
+	for i, reply := range replies {
+		if i > 0 {
+			if _, err := r.ReadString('\n'); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestX509Check_Collect_OCSP(t *testing.T) {
+	tests := map[string]struct {
+		status   int
+		expected string
+	}{
+		"good":    {status: ocsp.Good, expected: "ocsp_good"},
+		"revoked": {status: ocsp.Revoked, expected: "ocsp_revoked"},
+		"unknown": {status: ocsp.Unknown, expected: "ocsp_unknown"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var chain testChain
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				req, err := ocsp.ParseRequest(body)
+				if err != nil || req.SerialNumber.Cmp(chain.leaf.SerialNumber) != 0 {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				resp, err := ocsp.CreateResponse(chain.intermediate, chain.intermediate, ocsp.Response{
+					Status:       test.status,
+					SerialNumber: req.SerialNumber,
+					ThisUpdate:   time.Now().Add(-time.Hour),
+					NextUpdate:   time.Now().Add(time.Hour),
+					RevokedAt:    time.Now().Add(-time.Hour),
+				}, chain.intermediateKey)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				_, _ = w.Write(resp)
+			}))
+			defer ts.Close()
+
+			chain = newTestChainWithLeaf(t, &x509.Certificate{OCSPServer: []string{ts.URL}})
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
 
-func TestX509Check_Collect(t *testing.T) {}
+			job := New()
+			job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf, chain.intermediate)
+			job.CheckOCSP = true
+			require.True(t, job.Init())
+			assert.True(t, job.Charts().Has("ocsp_status"))
+			assert.False(t, job.Charts().Has("crl_status"))
+
+			metrics := job.Collect()
+			require.NotNil(t, metrics)
+
+			assert.Equal(t, int64(1), metrics[test.expected])
+			assert.Equal(t, int64(1), metrics["ocsp_good"]+metrics["ocsp_revoked"]+metrics["ocsp_unknown"])
+			assert.InDelta(t, 3600, metrics["ocsp_this_update_age"], 5)
+			assert.InDelta(t, 3600, metrics["ocsp_until_next_update"], 5)
+		})
+	}
+}
+
+func TestX509Check_Collect_CRL(t *testing.T) {
+	var crl []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(crl) }))
+	defer ts.Close()
+
+	chain := newTestChainWithLeaf(t, &x509.Certificate{CRLDistributionPoints: []string{ts.URL}})
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	newCRL := func(revoked ...*x509.Certificate) []byte {
+		var list []pkix.RevokedCertificate
+		for _, cert := range revoked {
+			list = append(list, pkix.RevokedCertificate{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()})
+		}
+		b, err := chain.intermediate.CreateCRL(rand.Reader, chain.intermediateKey, list, time.Now(), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		return b
+	}
+
+	// distribution point
+	crl = newCRL(chain.intermediate)
+
+	job := New()
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf, chain.intermediate)
+	job.CheckCRL = true
+	require.True(t, job.Init())
+	assert.True(t, job.Charts().Has("crl_status"))
+	assert.False(t, job.Charts().Has("ocsp_status"))
+
+	metrics := job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(1), metrics["crl_good"])
+	assert.Equal(t, int64(0), metrics["crl_revoked"])
+
+	// local file
+	crlFile := filepath.Join(dir, "revoked.crl")
+	require.NoError(t, ioutil.WriteFile(crlFile, newCRL(chain.leaf), 0644))
+
+	job = New()
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf, chain.intermediate)
+	job.CRLFile = crlFile
+	require.True(t, job.Init())
+
+	metrics = job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["crl_good"])
+	assert.Equal(t, int64(1), metrics["crl_revoked"])
+	assert.Equal(t, int64(0), metrics["crl_unknown"])
+
+	// signed by a wrong issuer
+	require.NoError(t, ioutil.WriteFile(crlFile, newCRL(), 0644))
+
+	job = New()
+	job.Source = "file://" + chain.writeBundle(t, dir, chain.leaf, chain.root)
+	job.CRLFile = crlFile
+	require.True(t, job.Init())
+
+	metrics = job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["crl_good"])
+	assert.Equal(t, int64(0), metrics["crl_revoked"])
+	assert.Equal(t, int64(1), metrics["crl_unknown"])
+
+	// not a CRL
+	require.NoError(t, ioutil.WriteFile(crlFile, []byte("not a crl"), 0644))
+
+	metrics = job.Collect()
+	require.NotNil(t, metrics)
+	assert.Equal(t, int64(0), metrics["crl_good"])
+	assert.Equal(t, int64(1), metrics["crl_unknown"])
+}
+
+func TestX509Check_Collect_Scan(t *testing.T) {
+	chain := newTestChain(t)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	p12, err := ioutil.ReadFile("testdata/keystore.p12")
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		"bundle.pem":   append(pemEncode(chain.leaf), pemEncode(chain.intermediate)...),
+		"root.der":     chain.root.Raw,
+		"keystore.jks": newJKS(chain.leaf, chain.root),
+		"keystore.p12": p12,
+		"key.pem":      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")}),
+	}
+	for name, data := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+
+	job := New()
+	job.Source = "file://" + dir
+	job.KeystorePassword = "changeit"
+	require.True(t, job.Init())
+	require.True(t, job.Check())
+	assert.False(t, job.Charts().Has("chain_time_until_expiration"))
+
+	metrics := job.Collect()
+	chart := job.Charts().Get("certs_time_until_expiration")
+	require.Len(t, chart.Dims, 6)
+	assert.Equal(t, int64(time.Until(chain.root.NotAfter).Seconds()), metrics["expiry"])
+
+	var names []string
+	for _, dim := range chart.Dims {
+		names = append(names, dim.Name)
+		assert.Contains(t, metrics, dim.ID)
+	}
+	assert.Contains(t, names, "example.org ("+filepath.Join(dir, "keystore.jks")+")")
+	assert.Contains(t, names, "p12.example.org ("+filepath.Join(dir, "keystore.p12")+")")
+	assert.Contains(t, names, "Test Root CA ("+filepath.Join(dir, "root.der")+")")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "keystore.jks")))
+	require.NotNil(t, job.Collect())
+	assert.Len(t, chart.Dims, 4)
+
+	// glob pattern
+	job = New()
+	job.Source = "file://" + filepath.Join(dir, "*.pem")
+	require.True(t, job.Init())
+	require.NotNil(t, job.Collect())
+	assert.Len(t, job.Charts().Get("certs_time_until_expiration").Dims, 2)
+
+	job = New()
+	job.Source = "file://" + filepath.Join(dir, "*.missing")
+	require.True(t, job.Init())
+	assert.False(t, job.Check())
+}
+
+func TestParseCerts_WrongPKCS12Password(t *testing.T) {
+	p12, err := ioutil.ReadFile("testdata/keystore.p12")
+	require.NoError(t, err)
+
+	_, err = parseCerts(p12, "wrong")
+	assert.Error(t, err)
+}
+
+// newJKS creates JKS keystore with a private key entry (the leaf chain) and a trusted certificate entry.
+func newJKS(leaf, root *x509.Certificate) []byte {
+	var buf bytes.Buffer
+	write := func(v interface{}) { _ = binary.Write(&buf, binary.BigEndian, v) }
+	writeUTF := func(s string) { write(uint16(len(s))); buf.WriteString(s) }
+	writeCert := func(cert *x509.Certificate) { writeUTF("X.509"); write(uint32(len(cert.Raw))); buf.Write(cert.Raw) }
+
+	write(uint32(jksMagic))
+	write(uint32(jksVersion))
+	write(uint32(2))
+
+	write(uint32(jksPrivateKeyTag))
+	writeUTF("leaf")
+	write(uint64(0))
+	write(uint32(3))
+	buf.WriteString("key")
+	write(uint32(1))
+	writeCert(leaf)
+
+	write(uint32(jksTrustedCertTag))
+	writeUTF("root")
+	write(uint64(0))
+	writeCert(root)
+
+	buf.Write(make([]byte, 20)) // digest
+	return buf.Bytes()
+}
+
+func TestCollectWeakness(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "weak"},
+		NotBefore:          time.Now(),
+		NotAfter:           time.Now().Add(time.Hour),
+		SignatureAlgorithm: x509.SHA256WithRSA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	metrics := make(map[string]int64)
+	collectWeakness(metrics, []*x509.Certificate{cert})
+	assert.Equal(t, int64(1), metrics["weak_key"])
+
+	// signature algorithm of self-signed certificates is ignored
+	cert.SignatureAlgorithm = x509.SHA1WithRSA
+	collectWeakness(metrics, []*x509.Certificate{cert})
+	assert.Equal(t, int64(0), metrics["weak_signature"])
+
+	cert.RawIssuer = []byte("issuer")
+	collectWeakness(metrics, []*x509.Certificate{cert})
+	assert.Equal(t, int64(1), metrics["weak_signature"])
+}
+
+type testChain struct {
+	root, intermediate, leaf *x509.Certificate
+	intermediateKey          *ecdsa.PrivateKey
+}
+
+func newTestChain(t *testing.T) testChain {
+	return newTestChainWithLeaf(t, &x509.Certificate{})
+}
+
+func newTestChainWithLeaf(t *testing.T, leafTmpl *x509.Certificate) testChain {
+	rootKey := newKey(t)
+	root := newCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, rootKey, rootKey)
+
+	interKey := newKey(t)
+	inter := newCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, interKey, rootKey)
+
+	leafTmpl.Subject = pkix.Name{CommonName: "example.org"}
+	leafTmpl.DNSNames = []string{"example.org"}
+	leaf := newCert(t, leafTmpl, inter, newKey(t), interKey)
+
+	return testChain{root: root, intermediate: inter, leaf: leaf, intermediateKey: interKey}
+}
+
+func (c testChain) writeBundle(t *testing.T, dir string, certs ...*x509.Certificate) string {
+	f, err := ioutil.TempFile(dir, "bundle")
+	require.NoError(t, err)
+	defer f.Close()
+
+	for _, cert := range certs {
+		_, err = f.Write(pemEncode(cert))
+		require.NoError(t, err)
+	}
+
+	return f.Name()
+}
+
+var serial int64
+
+func newCert(t *testing.T, tmpl, parent *x509.Certificate, key, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	serial++
+	tmpl.SerialNumber = big.NewInt(serial)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour * 24 * time.Duration(serial))
+	if parent == nil {
+		parent = tmpl
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func pemEncode(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "x509check")
+	require.NoError(t, err)
+	return dir
+}
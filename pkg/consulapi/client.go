@@ -0,0 +1,115 @@
+// Package consulapi is a minimal Consul HTTP API client shared by the consul module and the consul discoverer.
+package consulapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+// ErrNoLeader is returned by the endpoints which need the raft leader when the cluster has no leader.
+var ErrNoLeader = errors.New("no cluster leader")
+
+// NewClient creates Client, the ACL token is optional.
+func NewClient(config web.HTTP, aclToken string) (*Client, error) {
+	if config.URL == "" {
+		return nil, errors.New("URL is not set")
+	}
+
+	client, err := web.NewHTTPClient(config.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		aclToken:   aclToken,
+		req:        config.Request,
+		httpClient: client,
+	}, nil
+}
+
+// Client sends requests to the Consul HTTP API with the ACL token set.
+type Client struct {
+	aclToken string
+
+	req        web.Request
+	httpClient *http.Client
+}
+
+// Get sends GET request to the uri, the caller must close the response body (see CloseBody).
+func (c Client) Get(uri string) (*http.Response, error) {
+	req, err := c.createRequest(uri)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to %s : %v", req.URL, err)
+	}
+
+	return resp, nil
+}
+
+// GetJSON decodes the response body into dst, the body is decoded for 200 and the accepted status codes.
+func (c Client) GetJSON(uri string, dst interface{}, accepted ...int) error {
+	resp, err := c.Get(uri)
+
+	defer CloseBody(resp)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK && !hasStatus(accepted, resp.StatusCode) {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		if strings.Contains(string(body), "No cluster leader") {
+			return ErrNoLeader
+		}
+		return fmt.Errorf("%s returned HTTP status %d", resp.Request.URL, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("error on decoding resp from %s : %v", resp.Request.URL, err)
+	}
+
+	return nil
+}
+
+func (c Client) createRequest(uri string) (*http.Request, error) {
+	c.req.URI = uri
+
+	req, err := web.NewHTTPRequest(c.req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.aclToken != "" {
+		req.Header.Set("X-Consul-Token", c.aclToken)
+	}
+
+	return req, nil
+}
+
+// CloseBody drains and closes the response body, the response may be nil.
+func CloseBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
+
+func hasStatus(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
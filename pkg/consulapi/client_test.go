@@ -0,0 +1,79 @@
+package consulapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+
+		switch r.URL.Path {
+		case "/v1/ok":
+			_, _ = w.Write([]byte(`{"Healthy": true}`))
+		case "/v1/unhealthy":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"Healthy": false}`))
+		case "/v1/no_leader":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("No cluster leader"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(web.HTTP{}, "")
+	assert.Error(t, err)
+
+	client, err := NewClient(web.HTTP{Request: web.Request{URL: "http://127.0.0.1:8500"}}, "")
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestClient_GetJSON(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	client, err := NewClient(web.HTTP{Request: web.Request{URL: ts.URL}}, "secret")
+	require.NoError(t, err)
+
+	var health struct{ Healthy bool }
+
+	require.NoError(t, client.GetJSON("/v1/ok", &health))
+	assert.True(t, health.Healthy)
+
+	assert.Error(t, client.GetJSON("/v1/unhealthy", &health))
+	require.NoError(t, client.GetJSON("/v1/unhealthy", &health, http.StatusTooManyRequests))
+	assert.False(t, health.Healthy)
+
+	assert.Equal(t, ErrNoLeader, client.GetJSON("/v1/no_leader", &health))
+	assert.Error(t, client.GetJSON("/v1/missing", &health))
+}
+
+func TestClient_Get(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	client, err := NewClient(web.HTTP{Request: web.Request{URL: ts.URL}}, "secret")
+	require.NoError(t, err)
+
+	resp, err := client.Get("/v1/missing")
+	require.NoError(t, err)
+	CloseBody(resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	client, err = NewClient(web.HTTP{Request: web.Request{URL: "http://127.0.0.1:38001"}}, "secret")
+	require.NoError(t, err)
+
+	_, err = client.Get("/v1/ok")
+	assert.Error(t, err)
+}
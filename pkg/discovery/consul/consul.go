@@ -0,0 +1,258 @@
+// Package consul discovers collection jobs from the Consul catalog services.
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/consulapi"
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/logger"
+)
+
+const (
+	defaultURL          = "http://127.0.0.1:8500"
+	defaultHTTPTimeout  = time.Second * 2
+	defaultRefreshEvery = 30
+)
+
+// Config is the Consul discoverer configuration.
+type Config struct {
+	web.HTTP `yaml:",inline"`
+
+	ACLToken     string `yaml:"acl_token"`
+	RefreshEvery int    `yaml:"refresh_every"`
+	Rules        []Rule `yaml:"rules"`
+}
+
+// Rule creates a job for every instance of the services with the tag.
+type Rule struct {
+	Tag    string `yaml:"tag"`
+	Module string `yaml:"module"`
+	// Config is the job configuration template, it is executed with the Service as data.
+	Config string `yaml:"config"`
+
+	tmpl *template.Template
+}
+
+// Service is a Consul service instance.
+type Service struct {
+	ID   string
+	Name string
+	Node string
+	// Address is the service address, or the node address if the service has no address.
+	Address string
+	Port    int
+	Tags    []string
+	Meta    map[string]string
+}
+
+type catalogService struct {
+	ID             string
+	Node           string
+	Address        string
+	ServiceID      string
+	ServiceName    string
+	ServiceAddress string
+	ServicePort    int
+	ServiceTags    []string
+	ServiceMeta    map[string]string
+}
+
+// NewDefaultConfig creates Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		HTTP: web.HTTP{
+			Request: web.Request{URL: defaultURL},
+			Client:  web.Client{Timeout: web.Duration{Duration: defaultHTTPTimeout}},
+		},
+		RefreshEvery: defaultRefreshEvery,
+	}
+}
+
+// New creates Consul discoverer.
+func New(config Config) (*Consul, error) {
+	if config.URL == "" {
+		return nil, errors.New("URL is not set")
+	}
+
+	if len(config.Rules) == 0 {
+		return nil, errors.New("rules are not set")
+	}
+
+	rules := make([]Rule, len(config.Rules))
+
+	for i, rule := range config.Rules {
+		if rule.Tag == "" || rule.Module == "" {
+			return nil, fmt.Errorf("rule #%d : 'tag' and 'module' are mandatory", i+1)
+		}
+
+		tmpl, err := discovery.NewConfigTemplate(rule.Tag, rule.Config)
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d : error on parsing config template : %v", i+1, err)
+		}
+
+		rule.tmpl = tmpl
+		rules[i] = rule
+	}
+
+	client, err := consulapi.NewClient(config.HTTP, config.ACLToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RefreshEvery <= 0 {
+		config.RefreshEvery = defaultRefreshEvery
+	}
+
+	return &Consul{
+		Logger: logger.New("go.d", "discovery", "consul"),
+		config: config,
+		rules:  rules,
+		client: client,
+	}, nil
+}
+
+// Consul discovers the instances of the Consul catalog services with the rules tags.
+type Consul struct {
+	*logger.Logger
+
+	config Config
+	rules  []Rule
+	client *consulapi.Client
+}
+
+// Discover polls the catalog every refresh interval and sends the groups if the catalog is queried successfully.
+func (c *Consul) Discover(ctx context.Context, out chan<- []*discovery.Group) {
+	tk := time.NewTicker(time.Duration(c.config.RefreshEvery) * time.Second)
+	defer tk.Stop()
+
+	for {
+		if groups, err := c.discover(); err != nil {
+			c.Error(err)
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- groups:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+func (c *Consul) discover() ([]*discovery.Group, error) {
+	var services map[string][]string
+
+	if err := c.client.GetJSON("/v1/catalog/services", &services); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(services))
+	for name, tags := range services {
+		if c.hasRuleTag(tags) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var groups []*discovery.Group
+
+	for _, name := range names {
+		var instances []catalogService
+
+		if err := c.client.GetJSON("/v1/catalog/service/"+url.PathEscape(name), &instances); err != nil {
+			return nil, err
+		}
+
+		for _, inst := range instances {
+			if group := c.buildGroup(newService(inst)); group != nil {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+func (c *Consul) buildGroup(srv Service) *discovery.Group {
+	group := &discovery.Group{Source: "consul/" + srv.Node + "/" + srv.ID}
+
+	for _, rule := range c.rules {
+		if !hasTag(srv.Tags, rule.Tag) {
+			continue
+		}
+
+		conf, err := discovery.RenderConfig(rule.tmpl, srv)
+		if err != nil {
+			c.Errorf("service '%s' on node '%s' : %v", srv.ID, srv.Node, err)
+			continue
+		}
+
+		if _, ok := conf["name"]; !ok {
+			conf["name"] = discovery.JobName(srv.Node, srv.ID)
+		}
+
+		group.Configs = append(group.Configs, discovery.Config{Module: rule.Module, Conf: conf})
+	}
+
+	if len(group.Configs) == 0 {
+		return nil
+	}
+
+	return group
+}
+
+func (c *Consul) hasRuleTag(tags []string) bool {
+	for _, rule := range c.rules {
+		if hasTag(tags, rule.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func newService(inst catalogService) Service {
+	address := inst.ServiceAddress
+	if address == "" {
+		address = inst.Address
+	}
+
+	return Service{
+		ID:      inst.ServiceID,
+		Name:    inst.ServiceName,
+		Node:    inst.Node,
+		Address: address,
+		Port:    inst.ServicePort,
+		Tags:    inst.ServiceTags,
+		Meta:    inst.ServiceMeta,
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HostPort returns the service address and port joined, it is handy in the config templates.
+func (s Service) HostPort() string {
+	return net.JoinHostPort(s.Address, strconv.Itoa(s.Port))
+}
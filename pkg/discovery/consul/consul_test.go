@@ -0,0 +1,146 @@
+package consul
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	catalogServices = `{"consul": [], "web": ["nginx-status", "v1"], "db": ["mysql"], "cache": ["redis"]}`
+	catalogWeb      = `[
+  {"Node": "node1", "Address": "10.0.0.1", "ServiceID": "web1", "ServiceName": "web", "ServiceAddress": "", "ServicePort": 80, "ServiceTags": ["nginx-status", "v1"], "ServiceMeta": {"status_path": "/stub_status"}},
+  {"Node": "node2", "Address": "10.0.0.2", "ServiceID": "web2", "ServiceName": "web", "ServiceAddress": "10.1.0.2", "ServicePort": 8080, "ServiceTags": ["v1"], "ServiceMeta": {}}
+]`
+	catalogDB = `[
+  {"Node": "node1", "Address": "10.0.0.1", "ServiceID": "db", "ServiceName": "db", "ServiceAddress": "", "ServicePort": 3306, "ServiceTags": ["mysql"], "ServiceMeta": {}}
+]`
+)
+
+func newTestConfig(url string) Config {
+	cfg := NewDefaultConfig()
+	cfg.URL = url
+	cfg.ACLToken = "secret"
+	cfg.Rules = []Rule{
+		{
+			Tag:    "nginx-status",
+			Module: "nginx",
+			Config: "name: {{.Name}}_{{.ID}}\nurl: http://{{.HostPort}}{{index .Meta \"status_path\"}}\n",
+		},
+		{
+			Tag:    "mysql",
+			Module: "mysql",
+			Config: "dsn: netdata@tcp({{.HostPort}})/\n",
+		},
+	}
+	return cfg
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/catalog/services":
+			_, _ = w.Write([]byte(catalogServices))
+		case "/v1/catalog/service/web":
+			_, _ = w.Write([]byte(catalogWeb))
+		case "/v1/catalog/service/db":
+			_, _ = w.Write([]byte(catalogDB))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(newTestConfig("http://127.0.0.1:8500"))
+	assert.NoError(t, err)
+
+	cfg := newTestConfig("http://127.0.0.1:8500")
+	cfg.Rules = nil
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = newTestConfig("http://127.0.0.1:8500")
+	cfg.Rules[0].Module = ""
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = newTestConfig("http://127.0.0.1:8500")
+	cfg.Rules[0].Config = "{{"
+	_, err = New(cfg)
+	assert.Error(t, err)
+}
+
+func TestConsul_discover(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	c, err := New(newTestConfig(ts.URL))
+	require.NoError(t, err)
+
+	groups, err := c.discover()
+	require.NoError(t, err)
+
+	expected := []*discovery.Group{
+		{
+			Source: "consul/node1/db",
+			Configs: []discovery.Config{
+				{Module: "mysql", Conf: map[string]interface{}{"name": "node1_db", "dsn": "netdata@tcp(10.0.0.1:3306)/"}},
+			},
+		},
+		{
+			Source: "consul/node1/web1",
+			Configs: []discovery.Config{
+				{Module: "nginx", Conf: map[string]interface{}{"name": "web_web1", "url": "http://10.0.0.1:80/stub_status"}},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, groups)
+}
+
+func TestConsul_discover_Error(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	cfg := newTestConfig(ts.URL)
+	cfg.ACLToken = "wrong"
+	c, err := New(cfg)
+	require.NoError(t, err)
+
+	_, err = c.discover()
+	assert.Error(t, err)
+}
+
+func TestConsul_Discover(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	c, err := New(newTestConfig(ts.URL))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan []*discovery.Group)
+	go c.Discover(ctx, out)
+
+	select {
+	case groups := <-out:
+		assert.Len(t, groups, 2)
+	case <-time.After(time.Second * 5):
+		t.Fatal("no groups were discovered")
+	}
+}
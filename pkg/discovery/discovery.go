@@ -0,0 +1,42 @@
+// Package discovery runs collection jobs for targets that are discovered at runtime
+// (e.g. Consul catalog services), in addition to the jobs from the modules configuration files.
+package discovery
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Config is a discovered job configuration.
+type Config struct {
+	// Module is the name of the module that serves the job.
+	Module string
+	// Conf is the job configuration, the job name must be unique within the module.
+	Conf map[string]interface{}
+}
+
+// Group is a set of job configurations discovered from one target.
+type Group struct {
+	// Source identifies the target within the discoverer.
+	Source  string
+	Configs []Config
+}
+
+// Discoverer discovers targets. It sends the full list of the current target groups on every change,
+// targets that are not in the list anymore are considered gone.
+type Discoverer interface {
+	Discover(ctx context.Context, out chan<- []*Group)
+}
+
+// JobName joins the parts with '_' and replaces characters that are not allowed in the job name.
+func JobName(parts ...string) string {
+	name := strings.Join(parts, "_")
+
+	return strings.Map(func(r rune) rune {
+		if r == '.' || unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
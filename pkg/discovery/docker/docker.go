@@ -0,0 +1,276 @@
+// Package docker discovers collection jobs from the Docker containers.
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+	"github.com/netdata/go.d.plugin/pkg/dockerapi"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/logger"
+)
+
+const (
+	// LabelModule is the container label with the name of the module that serves the container.
+	LabelModule = "netdata.cloud/module"
+	// LabelConfig is the container label with the job configuration template.
+	LabelConfig = "netdata.cloud/config"
+)
+
+const (
+	defaultHTTPTimeout = time.Second * 2
+	defaultRetryEvery  = 10
+)
+
+// eventsFilters are the container events that change the running containers list.
+var eventsFilters = map[string][]string{
+	"type":  {"container"},
+	"event": {"start", "die", "destroy"},
+}
+
+// Config is the Docker discoverer configuration.
+type Config struct {
+	Address    string       `yaml:"address"`
+	Timeout    web.Duration `yaml:"timeout"`
+	RetryEvery int          `yaml:"retry_every"`
+	Rules      []Rule       `yaml:"rules"`
+}
+
+// Rule creates a job for every container with the image that matches the rule.
+type Rule struct {
+	// Image is the image name simple patterns.
+	Image  string `yaml:"image"`
+	Module string `yaml:"module"`
+	// Config is the job configuration template, it is executed with the Container as data.
+	Config string `yaml:"config"`
+
+	matcher matcher.Matcher
+	tmpl    *template.Template
+}
+
+// Container is a Docker container, it is the job configuration template data.
+type Container struct {
+	ID    string
+	Name  string
+	Image string
+	// IP is the container IP in the first (sorted by name) network, '127.0.0.1' for the host network containers.
+	IP     string
+	Labels map[string]string
+	Ports  []dockerapi.Port
+}
+
+// HostPort returns the container IP and the port joined, it is handy in the config templates.
+func (c Container) HostPort(port int) string {
+	return net.JoinHostPort(c.IP, strconv.Itoa(port))
+}
+
+// NewDefaultConfig creates Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Address:    dockerapi.DefaultAddress,
+		Timeout:    web.Duration{Duration: defaultHTTPTimeout},
+		RetryEvery: defaultRetryEvery,
+	}
+}
+
+// New creates Docker discoverer.
+func New(config Config) (*Docker, error) {
+	rules := make([]Rule, len(config.Rules))
+
+	for i, rule := range config.Rules {
+		if rule.Image == "" || rule.Module == "" {
+			return nil, fmt.Errorf("rule #%d : 'image' and 'module' are mandatory", i+1)
+		}
+
+		m, err := matcher.NewSimplePatternsMatcher(rule.Image)
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d : error on creating image matcher : %v", i+1, err)
+		}
+
+		tmpl, err := discovery.NewConfigTemplate(rule.Image, rule.Config)
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d : error on parsing config template : %v", i+1, err)
+		}
+
+		rule.matcher = m
+		rule.tmpl = tmpl
+		rules[i] = rule
+	}
+
+	client, err := dockerapi.NewClient(config.Address, config.Timeout.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RetryEvery <= 0 {
+		config.RetryEvery = defaultRetryEvery
+	}
+
+	return &Docker{
+		Logger: logger.New("go.d", "discovery", "docker"),
+		config: config,
+		rules:  rules,
+		client: client,
+	}, nil
+}
+
+// Docker discovers the running containers that match the rules or have the module label.
+type Docker struct {
+	*logger.Logger
+
+	config Config
+	rules  []Rule
+	client *dockerapi.Client
+
+	sent []*discovery.Group
+}
+
+// Discover lists the containers every time a container is started or stopped.
+// The events stream is reopened (and the containers are listed again) if it fails.
+func (d *Docker) Discover(ctx context.Context, out chan<- []*discovery.Group) {
+	for {
+		if err := d.run(ctx, out); err != nil && ctx.Err() == nil {
+			d.Error(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(d.config.RetryEvery) * time.Second):
+		}
+	}
+}
+
+func (d *Docker) run(ctx context.Context, out chan<- []*discovery.Group) error {
+	// the stream is opened before listing to not miss the changes in between
+	events, err := d.client.Events(ctx, eventsFilters)
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+
+	for {
+		groups, err := d.discover()
+		if err != nil {
+			return err
+		}
+		d.send(ctx, out, groups)
+
+		if _, err := events.Next(); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Docker) discover() ([]*discovery.Group, error) {
+	containers, err := d.client.Containers(false)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(containers, func(i, j int) bool { return containers[i].ID < containers[j].ID })
+
+	groups := make([]*discovery.Group, 0)
+
+	for _, c := range containers {
+		if group := d.buildGroup(newContainer(c)); group != nil {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+// send sends the groups if they have changed since the last sending.
+func (d *Docker) send(ctx context.Context, out chan<- []*discovery.Group, groups []*discovery.Group) {
+	if d.sent != nil && reflect.DeepEqual(groups, d.sent) {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case out <- groups:
+		d.sent = groups
+	}
+}
+
+func (d *Docker) buildGroup(c Container) *discovery.Group {
+	if c.IP == "" {
+		return nil
+	}
+
+	group := &discovery.Group{Source: "docker/" + c.ID}
+
+	add := func(module string, tmpl *template.Template) {
+		conf, err := discovery.RenderConfig(tmpl, c)
+		if err != nil {
+			d.Errorf("container '%s' : %v", c.Name, err)
+			return
+		}
+
+		if _, ok := conf["name"]; !ok {
+			conf["name"] = discovery.JobName(c.Name)
+		}
+
+		group.Configs = append(group.Configs, discovery.Config{Module: module, Conf: conf})
+	}
+
+	if module := c.Labels[LabelModule]; module != "" {
+		tmpl, err := discovery.NewConfigTemplate(c.Name, c.Labels[LabelConfig])
+		if err != nil {
+			d.Errorf("container '%s' : error on parsing config template : %v", c.Name, err)
+		} else {
+			add(module, tmpl)
+		}
+	}
+
+	for _, rule := range d.rules {
+		if rule.matcher.MatchString(c.Image) {
+			add(rule.Module, rule.tmpl)
+		}
+	}
+
+	if len(group.Configs) == 0 {
+		return nil
+	}
+
+	return group
+}
+
+func newContainer(c dockerapi.Container) Container {
+	container := Container{
+		ID:     c.ID,
+		Name:   c.Name(),
+		Image:  c.Image,
+		Labels: c.Labels,
+		Ports:  c.Ports,
+	}
+
+	if c.HostConfig.NetworkMode == "host" {
+		container.IP = "127.0.0.1"
+		return container
+	}
+
+	networks := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name, network := range c.NetworkSettings.Networks {
+		if network.IPAddress != "" {
+			networks = append(networks, name)
+		}
+	}
+	sort.Strings(networks)
+
+	if len(networks) > 0 {
+		container.IP = c.NetworkSettings.Networks[networks[0]].IPAddress
+	}
+
+	return container
+}
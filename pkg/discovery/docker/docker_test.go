@@ -0,0 +1,189 @@
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	containerRedis = `{"Id": "1111", "Names": ["/cache"], "Image": "redis:5",
+  "Ports": [{"PrivatePort": 6379, "Type": "tcp"}], "HostConfig": {"NetworkMode": "default"},
+  "NetworkSettings": {"Networks": {"frontend": {"IPAddress": "172.18.0.2"}, "backend": {"IPAddress": "172.19.0.2"}}}}`
+	containerLabeled = `{"Id": "2222", "Names": ["/web"], "Image": "my/web:latest",
+  "Labels": {"netdata.cloud/module": "nginx", "netdata.cloud/config": "url: http://{{.HostPort 8080}}/stub_status"},
+  "HostConfig": {"NetworkMode": "host"}, "NetworkSettings": {"Networks": {"host": {"IPAddress": ""}}}}`
+	containerOther = `{"Id": "3333", "Names": ["/app"], "Image": "my/app:latest", "HostConfig": {"NetworkMode": "default"},
+  "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.3"}}}}`
+	containerNoIP = `{"Id": "4444", "Names": ["/redis-none"], "Image": "redis:5", "HostConfig": {"NetworkMode": "none"},
+  "NetworkSettings": {"Networks": {"none": {"IPAddress": ""}}}}`
+)
+
+var (
+	groupRedis = &discovery.Group{
+		Source: "docker/1111",
+		Configs: []discovery.Config{
+			{Module: "redis", Conf: map[string]interface{}{"name": "cache", "address": "redis://172.19.0.2:6379"}},
+		},
+	}
+	groupWeb = &discovery.Group{
+		Source: "docker/2222",
+		Configs: []discovery.Config{
+			{Module: "nginx", Conf: map[string]interface{}{"name": "web", "url": "http://127.0.0.1:8080/stub_status"}},
+		},
+	}
+)
+
+type testDaemon struct {
+	*httptest.Server
+	address string
+	dir     string
+
+	mux        sync.Mutex
+	containers string
+	events     chan string
+}
+
+func newTestDaemon(t *testing.T) *testDaemon {
+	dir, err := ioutil.TempDir("", "docker")
+	require.NoError(t, err)
+
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	d := &testDaemon{address: "unix://" + socket, dir: dir, events: make(chan string)}
+
+	d.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			d.mux.Lock()
+			_, _ = w.Write([]byte(d.containers))
+			d.mux.Unlock()
+		case "/events":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case event := <-d.events:
+					_, _ = w.Write([]byte(event + "\n"))
+					w.(http.Flusher).Flush()
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	_ = d.Server.Listener.Close()
+	d.Server.Listener = l
+	d.Server.Start()
+
+	return d
+}
+
+func (d *testDaemon) setContainers(containers string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.containers = containers
+}
+
+func (d *testDaemon) close() {
+	d.Server.Close()
+	_ = os.RemoveAll(d.dir)
+}
+
+const dockerAddress = "unix:///var/run/docker.sock"
+
+func newTestConfig(address string) Config {
+	cfg := NewDefaultConfig()
+	cfg.Address = address
+	cfg.Rules = []Rule{
+		{Image: "redis redis:*", Module: "redis", Config: "address: redis://{{.HostPort 6379}}"},
+	}
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(NewDefaultConfig())
+	assert.NoError(t, err)
+
+	cfg := newTestConfig(dockerAddress)
+	cfg.Rules[0].Module = ""
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = newTestConfig(dockerAddress)
+	cfg.Rules[0].Config = "{{"
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = newTestConfig("ftp://127.0.0.1")
+	_, err = New(cfg)
+	assert.Error(t, err)
+}
+
+func TestDocker_Discover(t *testing.T) {
+	daemon := newTestDaemon(t)
+	defer daemon.close()
+
+	daemon.setContainers("[" + containerRedis + "," + containerOther + "," + containerNoIP + "]")
+
+	d, err := New(newTestConfig(daemon.address))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan []*discovery.Group)
+	go d.Discover(ctx, out)
+
+	assert.Equal(t, []*discovery.Group{groupRedis}, receive(t, out))
+
+	daemon.setContainers("[" + containerLabeled + "," + containerRedis + "]")
+	daemon.events <- `{"Type": "container", "Action": "start", "Actor": {"ID": "2222"}}`
+	assert.Equal(t, []*discovery.Group{groupRedis, groupWeb}, receive(t, out))
+
+	// the same containers, nothing is sent
+	daemon.events <- `{"Type": "container", "Action": "die", "Actor": {"ID": "3333"}}`
+
+	daemon.setContainers("[]")
+	daemon.events <- `{"Type": "container", "Action": "die", "Actor": {"ID": "1111"}}`
+	assert.Equal(t, []*discovery.Group{}, receive(t, out))
+}
+
+func TestDocker_discover_Error(t *testing.T) {
+	daemon := newTestDaemon(t)
+	defer daemon.close()
+
+	daemon.setContainers("not json")
+
+	d, err := New(newTestConfig(daemon.address))
+	require.NoError(t, err)
+
+	_, err = d.discover()
+	assert.Error(t, err)
+}
+
+func receive(t *testing.T, out chan []*discovery.Group) []*discovery.Group {
+	select {
+	case groups := <-out:
+		return groups
+	case <-time.After(time.Second * 5):
+		t.Fatal("no groups were discovered")
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// Package kubernetes discovers collection jobs from the Kubernetes pods annotations.
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/netdata/go-orchestrator/logger"
+)
+
+const (
+	// AnnotationModule is the pod annotation with the name of the module that serves the pod.
+	AnnotationModule = "netdata.cloud/module"
+	// AnnotationConfig is the pod annotation with the job configuration template.
+	AnnotationConfig = "netdata.cloud/config"
+)
+
+const (
+	defaultURL          = "https://kubernetes.default.svc"
+	defaultTokenFile    = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	defaultCAFile       = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	defaultHTTPTimeout  = time.Second * 2
+	defaultRetryEvery   = 10
+	defaultWatchTimeout = 300
+)
+
+// Config is the Kubernetes discoverer configuration.
+type Config struct {
+	web.HTTP `yaml:",inline"`
+
+	// Modules is the list of the modules the pods are allowed to use, it is mandatory.
+	// The job configuration comes from the pod annotations and runs with the plugin privileges.
+	Modules       []string `yaml:"modules"`
+	Namespace     string   `yaml:"namespace"`
+	LabelSelector string   `yaml:"label_selector"`
+	NodeName      string   `yaml:"node_name"`
+	RetryEvery    int      `yaml:"retry_every"`
+}
+
+// NewDefaultConfig creates Config with default values, the defaults are set for running in a pod.
+func NewDefaultConfig() Config {
+	address := defaultURL
+	if host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"); host != "" && port != "" {
+		address = "https://" + net.JoinHostPort(host, port)
+	}
+
+	return Config{
+		HTTP: web.HTTP{
+			Request: web.Request{URL: address, BearerTokenFile: defaultTokenFile},
+			Client: web.Client{
+				Timeout:         web.Duration{Duration: defaultHTTPTimeout},
+				ClientTLSConfig: web.ClientTLSConfig{TLSCA: defaultCAFile},
+			},
+		},
+		NodeName:   os.Getenv("NODE_NAME"),
+		RetryEvery: defaultRetryEvery,
+	}
+}
+
+// New creates Kubernetes discoverer.
+func New(config Config) (*Kubernetes, error) {
+	if config.URL == "" {
+		return nil, errors.New("URL is not set")
+	}
+	if len(config.Modules) == 0 {
+		return nil, errors.New("modules are not set")
+	}
+
+	modules := make(map[string]bool)
+	for _, name := range config.Modules {
+		modules[name] = true
+	}
+
+	client, err := web.NewHTTPClient(config.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	// watch requests are long-lived, they are limited by the watch timeout instead
+	watchConfig := config.Client
+	watchConfig.Timeout = web.Duration{}
+	watchClient, err := web.NewHTTPClient(watchConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RetryEvery <= 0 {
+		config.RetryEvery = defaultRetryEvery
+	}
+
+	return &Kubernetes{
+		Logger:      logger.New("go.d", "discovery", "kubernetes"),
+		config:      config,
+		httpClient:  client,
+		watchClient: watchClient,
+		modules:     modules,
+		pods:        make(map[string]apiPod),
+	}, nil
+}
+
+// Kubernetes discovers the pods that have the module annotation.
+type Kubernetes struct {
+	*logger.Logger
+
+	config      Config
+	httpClient  *http.Client
+	watchClient *http.Client
+	// modules are the allowed modules
+	modules map[string]bool
+
+	pods map[string]apiPod
+	sent []*discovery.Group
+}
+
+// Discover lists the pods and then watches the pods changes, the list is repeated if the watch fails.
+func (k *Kubernetes) Discover(ctx context.Context, out chan<- []*discovery.Group) {
+	for {
+		resourceVersion, err := k.list()
+		if err != nil {
+			k.Error(err)
+		} else {
+			k.send(ctx, out)
+			err = k.watch(ctx, resourceVersion, out)
+			if err != nil && ctx.Err() == nil {
+				k.Error(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(k.config.RetryEvery) * time.Second):
+		}
+	}
+}
+
+func (k *Kubernetes) list() (string, error) {
+	req, err := k.newRequest(nil)
+	if err != nil {
+		return "", err
+	}
+
+	var list podList
+	if err := k.doJSON(req, &list); err != nil {
+		return "", err
+	}
+
+	k.pods = make(map[string]apiPod, len(list.Items))
+	for _, pod := range list.Items {
+		k.pods[pod.key()] = pod
+	}
+
+	return list.Metadata.ResourceVersion, nil
+}
+
+// watch applies the pods changes until the context is canceled or the watch fails.
+// The API server closes the watch after the watch timeout, the watch is resumed from the last seen resource version.
+func (k *Kubernetes) watch(ctx context.Context, resourceVersion string, out chan<- []*discovery.Group) error {
+	for {
+		query := url.Values{
+			"watch":           {"true"},
+			"resourceVersion": {resourceVersion},
+			"timeoutSeconds":  {fmt.Sprint(defaultWatchTimeout)},
+		}
+
+		req, err := k.newRequest(query)
+		if err != nil {
+			return err
+		}
+
+		resp, err := k.watchClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("error on watch request to %s : %v", req.URL, err)
+		}
+
+		resourceVersion, err = k.readEvents(ctx, resp, resourceVersion, out)
+		closeBody(resp)
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (k *Kubernetes) readEvents(ctx context.Context, resp *http.Response, resourceVersion string, out chan<- []*discovery.Group) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned HTTP status %d", resp.Request.URL, resp.StatusCode)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+
+	for {
+		var event watchEvent
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				return resourceVersion, nil
+			}
+			return "", fmt.Errorf("error on decoding watch event from %s : %v", resp.Request.URL, err)
+		}
+
+		if event.Type == eventError {
+			var status apiStatus
+			_ = json.Unmarshal(event.Object, &status)
+			return "", fmt.Errorf("watch error (code %d) : %s", status.Code, status.Message)
+		}
+
+		var pod apiPod
+		if err := json.Unmarshal(event.Object, &pod); err != nil {
+			return "", fmt.Errorf("error on decoding watch event object : %v", err)
+		}
+		resourceVersion = pod.Metadata.ResourceVersion
+
+		switch event.Type {
+		case eventAdded, eventModified:
+			k.pods[pod.key()] = pod
+		case eventDeleted:
+			delete(k.pods, pod.key())
+		default:
+			// BOOKMARK events only update the resource version
+			continue
+		}
+
+		k.send(ctx, out)
+	}
+}
+
+// send sends the groups if they have changed since the last sending.
+func (k *Kubernetes) send(ctx context.Context, out chan<- []*discovery.Group) {
+	groups := k.buildGroups()
+	if k.sent != nil && reflect.DeepEqual(groups, k.sent) {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case out <- groups:
+		k.sent = groups
+	}
+}
+
+func (k *Kubernetes) buildGroups() []*discovery.Group {
+	keys := make([]string, 0, len(k.pods))
+	for key, pod := range k.pods {
+		if pod.isServed() && pod.Metadata.Annotations[AnnotationModule] != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	groups := make([]*discovery.Group, 0, len(keys))
+
+	for _, key := range keys {
+		if group := k.buildGroup(newPod(k.pods[key])); group != nil {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+func (k *Kubernetes) buildGroup(pod Pod) *discovery.Group {
+	module := pod.Annotations[AnnotationModule]
+	if !k.modules[module] {
+		k.Warningf("pod '%s/%s' : module '%s' is not allowed", pod.Namespace, pod.Name, module)
+		return nil
+	}
+
+	conf, err := renderConfig(pod)
+	if err != nil {
+		k.Errorf("pod '%s/%s' : %v", pod.Namespace, pod.Name, err)
+		return nil
+	}
+
+	if _, ok := conf["name"]; !ok {
+		conf["name"] = discovery.JobName(pod.Namespace, pod.Name)
+	}
+
+	return &discovery.Group{
+		Source:  "k8s/" + pod.Namespace + "/" + pod.Name,
+		Configs: []discovery.Config{{Module: module, Conf: conf}},
+	}
+}
+
+func renderConfig(pod Pod) (map[string]interface{}, error) {
+	tmpl, err := discovery.NewConfigTemplate(pod.Namespace+"/"+pod.Name, pod.Annotations[AnnotationConfig])
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing config template : %v", err)
+	}
+
+	return discovery.RenderConfig(tmpl, pod)
+}
+
+func (k *Kubernetes) newRequest(query url.Values) (*http.Request, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if k.config.LabelSelector != "" {
+		query.Set("labelSelector", k.config.LabelSelector)
+	}
+	if k.config.NodeName != "" {
+		query.Set("fieldSelector", "spec.nodeName="+k.config.NodeName)
+	}
+
+	r := k.config.Request
+	r.URI = "/api/v1/pods"
+	if k.config.Namespace != "" {
+		r.URI = "/api/v1/namespaces/" + url.PathEscape(k.config.Namespace) + "/pods"
+	}
+	if len(query) > 0 {
+		r.URI += "?" + query.Encode()
+	}
+
+	req, err := web.NewHTTPRequest(r)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request : %v", err)
+	}
+
+	return req, nil
+}
+
+func (k *Kubernetes) doJSON(req *http.Request, dst interface{}) error {
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error on request to %s : %v", req.URL, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %d", req.URL, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("error on decoding resp from %s : %v", req.URL, err)
+	}
+
+	return nil
+}
+
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,211 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/discovery"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	podWeb = `{
+  "metadata": {"name": "web-1", "namespace": "default", "uid": "1", "resourceVersion": "%s",
+    "labels": {"app": "web"},
+    "annotations": {"netdata.cloud/module": "nginx", "netdata.cloud/config": "url: http://{{.HostPort 80}}/stub_status\n"}},
+  "spec": {"nodeName": "node1", "containers": [{"name": "nginx", "ports": [{"name": "http", "containerPort": 80, "protocol": "TCP"}]}]},
+  "status": {"phase": "%s", "podIP": "10.0.0.1"}
+}`
+	podDB = `{
+  "metadata": {"name": "db-0", "namespace": "prod", "uid": "2", "resourceVersion": "%s",
+    "annotations": {"netdata.cloud/module": "mysql", "netdata.cloud/config": "name: {{.Labels.app}}\ndsn: netdata@tcp({{.IP}}:{{(index .Ports 0).Port}})/\n"},
+    "labels": {"app": "db"}},
+  "spec": {"nodeName": "node1", "containers": [{"name": "mysql", "ports": [{"containerPort": 3306}]}]},
+  "status": {"phase": "Running", "podIP": "10.0.0.2"}
+}`
+	podNotAnnotated = `{
+  "metadata": {"name": "app-1", "namespace": "default", "uid": "3", "resourceVersion": "1"},
+  "status": {"phase": "Running", "podIP": "10.0.0.3"}
+}`
+	podPending = `{
+  "metadata": {"name": "web-2", "namespace": "default", "uid": "4", "resourceVersion": "1",
+    "annotations": {"netdata.cloud/module": "nginx"}},
+  "status": {"phase": "Pending"}
+}`
+	podNotAllowed = `{
+  "metadata": {"name": "cert-1", "namespace": "default", "uid": "6", "resourceVersion": "1",
+    "annotations": {"netdata.cloud/module": "x509check", "netdata.cloud/config": "source: file:///etc/shadow\n"}},
+  "status": {"phase": "Running", "podIP": "10.0.0.6"}
+}`
+	podBadTemplate = `{
+  "metadata": {"name": "web-3", "namespace": "default", "uid": "5", "resourceVersion": "1",
+    "annotations": {"netdata.cloud/module": "nginx", "netdata.cloud/config": "url: http://{{.Address}}\n"}},
+  "status": {"phase": "Running", "podIP": "10.0.0.5"}
+}`
+)
+
+var (
+	groupWeb = &discovery.Group{
+		Source: "k8s/default/web-1",
+		Configs: []discovery.Config{
+			{Module: "nginx", Conf: map[string]interface{}{"name": "default_web-1", "url": "http://10.0.0.1:80/stub_status"}},
+		},
+	}
+	groupDB = &discovery.Group{
+		Source: "k8s/prod/db-0",
+		Configs: []discovery.Config{
+			{Module: "mysql", Conf: map[string]interface{}{"name": "db", "dsn": "netdata@tcp(10.0.0.2:3306)/"}},
+		},
+	}
+)
+
+type testAPIServer struct {
+	*httptest.Server
+	events chan string
+}
+
+func newTestAPIServer(t *testing.T) *testAPIServer {
+	ts := &testAPIServer{events: make(chan string)}
+
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/pods" || r.URL.Query().Get("fieldSelector") != "spec.nodeName=node1" {
+			t.Errorf("unexpected request to %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = fmt.Fprintf(w, `{"metadata": {"resourceVersion": "10"}, "items": [%s, %s, %s, %s, %s]}`,
+				fmt.Sprintf(podWeb, "1", "Running"), podNotAnnotated, podPending, podBadTemplate, podNotAllowed)
+			return
+		}
+
+		if v := r.URL.Query().Get("resourceVersion"); v != "10" {
+			t.Errorf("unexpected watch resource version %s", v)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-ts.events:
+				_, _ = w.Write([]byte(event + "\n"))
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+
+	return ts
+}
+
+func newTestConfig(t *testing.T, url string) Config {
+	f, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	_, _ = f.WriteString("secret\n")
+	_ = f.Close()
+
+	cfg := NewDefaultConfig()
+	cfg.URL = url
+	cfg.TLSCA = ""
+	cfg.BearerTokenFile = f.Name()
+	cfg.NodeName = "node1"
+	cfg.Modules = []string{"nginx", "mysql"}
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.TLSCA = ""
+	cfg.Modules = []string{"nginx"}
+	_, err := New(cfg)
+	assert.NoError(t, err)
+
+	cfg.URL = ""
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = NewDefaultConfig()
+	cfg.TLSCA = ""
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg = NewDefaultConfig()
+	cfg.Modules = []string{"nginx"}
+	cfg.TLSCA = "testdata/not-exists.crt"
+	_, err = New(cfg)
+	assert.Error(t, err)
+}
+
+func TestKubernetes_Discover(t *testing.T) {
+	ts := newTestAPIServer(t)
+	defer ts.Close()
+
+	cfg := newTestConfig(t, ts.URL)
+	defer os.Remove(cfg.BearerTokenFile)
+
+	k, err := New(cfg)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan []*discovery.Group)
+	go k.Discover(ctx, out)
+
+	assert.Equal(t, []*discovery.Group{groupWeb}, receive(t, out))
+
+	ts.events <- fmt.Sprintf(`{"type": "ADDED", "object": %s}`, fmt.Sprintf(podDB, "11"))
+	assert.Equal(t, []*discovery.Group{groupWeb, groupDB}, receive(t, out))
+
+	// the pod is terminated
+	ts.events <- fmt.Sprintf(`{"type": "MODIFIED", "object": %s}`, fmt.Sprintf(podWeb, "12", "Succeeded"))
+	assert.Equal(t, []*discovery.Group{groupDB}, receive(t, out))
+
+	// the groups aren't changed, nothing is sent
+	ts.events <- fmt.Sprintf(`{"type": "MODIFIED", "object": %s}`, fmt.Sprintf(podDB, "13"))
+	ts.events <- fmt.Sprintf(`{"type": "DELETED", "object": %s}`, fmt.Sprintf(podDB, "14"))
+	assert.Equal(t, []*discovery.Group{}, receive(t, out))
+}
+
+func TestKubernetes_list_Unauthorized(t *testing.T) {
+	ts := newTestAPIServer(t)
+	defer ts.Close()
+
+	cfg := newTestConfig(t, ts.URL)
+	_ = os.Remove(cfg.BearerTokenFile)
+
+	k, err := New(cfg)
+	require.NoError(t, err)
+
+	_, err = k.list()
+	assert.Error(t, err)
+
+	k.config.BearerTokenFile = ""
+	_, err = k.list()
+	assert.Error(t, err)
+}
+
+func receive(t *testing.T, out chan []*discovery.Group) []*discovery.Group {
+	select {
+	case groups := <-out:
+		return groups
+	case <-time.After(time.Second * 5):
+		t.Fatal("no groups were discovered")
+	}
+	return nil
+}
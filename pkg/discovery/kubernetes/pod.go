@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+)
+
+const (
+	podPhaseRunning = "Running"
+
+	eventAdded    = "ADDED"
+	eventModified = "MODIFIED"
+	eventDeleted  = "DELETED"
+	eventError    = "ERROR"
+)
+
+// Pod is a Kubernetes pod, it is the job configuration template data.
+type Pod struct {
+	Name        string
+	Namespace   string
+	UID         string
+	Node        string
+	IP          string
+	Labels      map[string]string
+	Annotations map[string]string
+	Ports       []Port
+}
+
+// Port is a pod container port.
+type Port struct {
+	Name      string
+	Container string
+	Port      int
+	Protocol  string
+}
+
+// HostPort returns the pod IP and the port joined, it is handy in the config templates.
+func (p Pod) HostPort(port int) string {
+	return net.JoinHostPort(p.IP, strconv.Itoa(port))
+}
+
+type podList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []apiPod `json:"items"`
+}
+
+type apiPod struct {
+	Metadata struct {
+		Name              string            `json:"name"`
+		Namespace         string            `json:"namespace"`
+		UID               string            `json:"uid"`
+		ResourceVersion   string            `json:"resourceVersion"`
+		Labels            map[string]string `json:"labels"`
+		Annotations       map[string]string `json:"annotations"`
+		DeletionTimestamp *string           `json:"deletionTimestamp"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name  string `json:"name"`
+			Ports []struct {
+				Name          string `json:"name"`
+				ContainerPort int    `json:"containerPort"`
+				Protocol      string `json:"protocol"`
+			} `json:"ports"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// apiStatus is the object of the watch ERROR event.
+type apiStatus struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (p apiPod) key() string {
+	return p.Metadata.Namespace + "/" + p.Metadata.Name
+}
+
+// isServed returns whether the pod jobs should be running: the pod is running, has an IP and isn't being deleted.
+func (p apiPod) isServed() bool {
+	return p.Status.Phase == podPhaseRunning && p.Status.PodIP != "" && p.Metadata.DeletionTimestamp == nil
+}
+
+func newPod(p apiPod) Pod {
+	pod := Pod{
+		Name:        p.Metadata.Name,
+		Namespace:   p.Metadata.Namespace,
+		UID:         p.Metadata.UID,
+		Node:        p.Spec.NodeName,
+		IP:          p.Status.PodIP,
+		Labels:      p.Metadata.Labels,
+		Annotations: p.Metadata.Annotations,
+	}
+
+	for _, c := range p.Spec.Containers {
+		for _, port := range c.Ports {
+			pod.Ports = append(pod.Ports, Port{
+				Name:      port.Name,
+				Container: c.Name,
+				Port:      port.ContainerPort,
+				Protocol:  port.Protocol,
+			})
+		}
+	}
+
+	return pod
+}
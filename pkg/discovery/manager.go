@@ -0,0 +1,426 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/netdata/go-orchestrator"
+	"github.com/netdata/go-orchestrator/logger"
+	"github.com/netdata/go-orchestrator/module"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NewManager creates Manager.
+func NewManager(pluginName string, out io.Writer, discoverers ...Discoverer) *Manager {
+	return &Manager{
+		PluginName:     pluginName,
+		Out:            out,
+		Registry:       module.DefaultRegistry,
+		MinUpdateEvery: 1,
+		Logger:         logger.New(pluginName, "discovery", "manager"),
+		discoverers:    discoverers,
+		targets:        make(map[targetKey]*target),
+		running:        make(map[string]bool),
+		checked:        make(chan checkResult),
+		done:           make(chan struct{}),
+		now:            time.Now,
+	}
+}
+
+// Manager runs discoverers, it starts jobs of the discovered targets and stops them when the targets are gone.
+type Manager struct {
+	PluginName string
+	Out        io.Writer
+	Registry   module.Registry
+	// MinUpdateEvery is the plugin update every, jobs are not run more often.
+	MinUpdateEvery int
+	// StaticJobs is a set of the full names of the jobs from the modules configuration files,
+	// discovered jobs with these names are not started.
+	StaticJobs map[string]bool
+
+	*logger.Logger
+
+	discoverers []Discoverer
+	targets     map[targetKey]*target
+	// running is a set of running (or being checked) jobs full names
+	running map[string]bool
+	queue   jobsQueue
+	// checked receives the results of the jobs checks, checks run in separate goroutines
+	// to not block the other discoverers updates.
+	checked  chan checkResult
+	checking int
+	done     chan struct{}
+	now      func() time.Time
+}
+
+type targetKey struct {
+	discoverer int
+	source     string
+}
+
+type target struct {
+	group *Group
+	jobs  []*discoveredJob
+}
+
+type discoveredJob struct {
+	*module.Job
+	mod module.Module
+	// fullName is cached, the job methods are not safe to call while the job is being checked
+	fullName string
+	started  bool
+	// checking is set while the job check is in progress, it is unset if the job is stopped meanwhile
+	checking bool
+	// retryAt is the time of the next check, zero if the job isn't going to be rechecked
+	retryAt time.Time
+}
+
+type checkResult struct {
+	job   *discoveredJob
+	ok    bool
+	retry bool
+}
+
+type update struct {
+	discoverer int
+	groups     []*Group
+}
+
+// Run runs discoverers and manages the jobs until the context is canceled.
+func (m *Manager) Run(ctx context.Context) {
+	updates := make(chan update)
+
+	for i, d := range m.discoverers {
+		out := make(chan []*Group)
+		go d.Discover(ctx, out)
+		go forwardUpdates(ctx, i, out, updates)
+	}
+
+	go m.tickLoop(ctx)
+
+	retry := time.NewTicker(time.Second)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.stopAll()
+			close(m.done)
+			return
+		case u := <-updates:
+			m.processUpdate(u)
+		case r := <-m.checked:
+			m.processCheck(r)
+		case <-retry.C:
+			m.recheck()
+		}
+	}
+}
+
+func forwardUpdates(ctx context.Context, discoverer int, in <-chan []*Group, out chan<- update) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case groups := <-in:
+			select {
+			case <-ctx.Done():
+				return
+			case out <- update{discoverer: discoverer, groups: groups}:
+			}
+		}
+	}
+}
+
+func (m *Manager) tickLoop(ctx context.Context) {
+	tk := orchestrator.NewTicker(time.Second)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case clock := <-tk.C:
+			m.queue.notify(clock)
+		}
+	}
+}
+
+func (m *Manager) processUpdate(u update) {
+	seen := make(map[targetKey]bool)
+
+	for _, group := range u.groups {
+		key := targetKey{discoverer: u.discoverer, source: group.Source}
+		seen[key] = true
+
+		if t, ok := m.targets[key]; ok {
+			if reflect.DeepEqual(t.group.Configs, group.Configs) {
+				continue
+			}
+			m.Infof("target '%s' has changed, restarting its jobs", group.Source)
+			m.stopTarget(t)
+		}
+
+		m.targets[key] = m.startTarget(group)
+	}
+
+	for key, t := range m.targets {
+		if key.discoverer == u.discoverer && !seen[key] {
+			m.Infof("target '%s' is gone, stopping its jobs", key.source)
+			m.stopTarget(t)
+			delete(m.targets, key)
+		}
+	}
+}
+
+func (m *Manager) startTarget(group *Group) *target {
+	t := &target{group: group}
+
+	for _, cfg := range group.Configs {
+		job, err := m.createJob(cfg)
+		if err != nil {
+			m.Errorf("skipping %s job of '%s' : %v", cfg.Module, group.Source, err)
+			continue
+		}
+
+		t.jobs = append(t.jobs, job)
+		m.startJob(job)
+	}
+
+	return t
+}
+
+func (m *Manager) stopTarget(t *target) {
+	for _, job := range t.jobs {
+		m.stopJob(job)
+	}
+}
+
+func (m *Manager) stopAll() {
+	for key, t := range m.targets {
+		m.stopTarget(t)
+		delete(m.targets, key)
+	}
+}
+
+// recheck checks jobs which Check failed and autodetection_retry is set.
+func (m *Manager) recheck() {
+	now := m.now()
+
+	for _, t := range m.targets {
+		for _, job := range t.jobs {
+			if !job.started && !job.checking && !job.retryAt.IsZero() && !now.Before(job.retryAt) {
+				m.startJob(job)
+			}
+		}
+	}
+}
+
+func (m *Manager) createJob(cfg Config) (*discoveredJob, error) {
+	creator, ok := m.Registry[cfg.Module]
+	if !ok {
+		return nil, fmt.Errorf("module '%s' is not registered", cfg.Module)
+	}
+
+	conf := make(map[string]interface{}, len(cfg.Conf)+2)
+	for k, v := range cfg.Conf {
+		conf[k] = v
+	}
+
+	if _, ok := conf["update_every"]; !ok {
+		conf["update_every"] = creator.UpdateEvery
+	}
+	if v, ok := conf["update_every"].(int); !ok || v < m.MinUpdateEvery {
+		conf["update_every"] = m.MinUpdateEvery
+	}
+	if _, ok := conf["autodetection_retry"]; !ok {
+		conf["autodetection_retry"] = 0
+	}
+
+	mod := creator.Create()
+	if err := unmarshal(conf, mod); err != nil {
+		return nil, err
+	}
+
+	job := module.NewJob(m.PluginName, cfg.Module, mod, m.Out)
+	if err := unmarshal(conf, job); err != nil {
+		return nil, err
+	}
+
+	return &discoveredJob{Job: job, mod: mod, fullName: job.FullName()}, nil
+}
+
+// startJob starts the job the same way the orchestrator does: Init, Check, PostCheck and then the main loop.
+// Init, Check and PostCheck run in a separate goroutine, the result is processed in processCheck.
+func (m *Manager) startJob(job *discoveredJob) {
+	job.retryAt = time.Time{}
+
+	if m.StaticJobs[job.fullName] {
+		m.Warningf("skipping %s[%s]: the name is taken by a job from the module configuration file",
+			job.ModuleName(), job.Name())
+		return
+	}
+
+	if m.running[job.fullName] {
+		m.Infof("skipping %s[%s]: already served by another job", job.ModuleName(), job.Name())
+		return
+	}
+
+	// the name is reserved while the job is being checked
+	m.running[job.fullName] = true
+	job.checking = true
+	m.checking++
+
+	go func() {
+		r := m.checkJob(job)
+		select {
+		case m.checked <- r:
+		case <-m.done:
+		}
+	}()
+}
+
+func (m *Manager) checkJob(job *discoveredJob) checkResult {
+	if !job.Init() {
+		m.Errorf("%s[%s] Init failed", job.ModuleName(), job.Name())
+		return checkResult{job: job}
+	}
+
+	if !job.Check() {
+		if job.Panicked() {
+			return checkResult{job: job}
+		}
+		m.Errorf("%s[%s] Check failed", job.ModuleName(), job.Name())
+		return checkResult{job: job, retry: job.AutoDetectionRetry() > 0}
+	}
+
+	if !job.PostCheck() {
+		m.Errorf("%s[%s] PostCheck failed", job.ModuleName(), job.Name())
+		return checkResult{job: job}
+	}
+
+	return checkResult{job: job, ok: true}
+}
+
+func (m *Manager) processCheck(r checkResult) {
+	m.checking--
+	job := r.job
+
+	// the job is stopped while it was being checked
+	if !job.checking {
+		if r.ok {
+			job.mod.Cleanup()
+		}
+		return
+	}
+	job.checking = false
+
+	if !r.ok {
+		delete(m.running, job.fullName)
+		if r.retry {
+			job.retryAt = m.now().Add(time.Duration(job.AutoDetectionRetry()) * time.Second)
+		}
+		return
+	}
+
+	m.Infof("%s[%s] started", job.ModuleName(), job.Name())
+	job.started = true
+	m.queue.add(job.Job)
+	go job.Start()
+}
+
+func (m *Manager) stopJob(job *discoveredJob) {
+	if job.checking {
+		job.checking = false
+		delete(m.running, job.fullName)
+		return
+	}
+
+	if !job.started {
+		return
+	}
+
+	m.Infof("%s[%s] stopped", job.ModuleName(), job.Name())
+	job.started = false
+	delete(m.running, job.fullName)
+	m.queue.remove(job.Job)
+	job.Stop()
+
+	_, _ = m.Out.Write(obsoleteCharts(m.PluginName, job))
+}
+
+// obsoleteCharts returns the plugins.d protocol lines that mark all the job charts obsolete.
+func obsoleteCharts(pluginName string, job *discoveredJob) []byte {
+	var buf bytes.Buffer
+
+	line := func(typeID, id, title, units, fam, ctx, chartType string, priority int) {
+		_, _ = fmt.Fprintf(&buf, "CHART '%s.%s' '' '%s' '%s' '%s' '%s' '%s' '%d' '%d' 'obsolete' '%s' '%s'\n",
+			typeID, id, title, units, fam, ctx, chartType, priority, job.UpdateEvery, pluginName, job.ModuleName())
+	}
+
+	if charts := job.mod.Charts(); charts != nil {
+		for _, chart := range *charts {
+			line(job.FullName(), chart.ID, chart.Title, chart.Units, chart.Fam, chart.Ctx, chart.Type.String(), chart.Priority)
+		}
+	}
+
+	// the job runtime chart, see module.NewJob
+	line("netdata", "execution_time_of_"+job.FullName(), "Execution Time for "+job.FullName(),
+		"ms", pluginName, "netdata.go_plugin_execution_time", "line", 145000)
+
+	return buf.Bytes()
+}
+
+type jobsQueue struct {
+	mux  sync.Mutex
+	jobs []*module.Job
+}
+
+func (q *jobsQueue) add(job *module.Job) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	q.jobs = append(q.jobs, job)
+}
+
+func (q *jobsQueue) remove(job *module.Job) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	for i, j := range q.jobs {
+		if j == job {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *jobsQueue) len() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	return len(q.jobs)
+}
+
+func (q *jobsQueue) notify(clock int) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	for _, job := range q.jobs {
+		job.Tick(clock)
+	}
+}
+
+func unmarshal(conf interface{}, dst interface{}) error {
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, dst)
+}
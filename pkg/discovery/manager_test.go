@@ -0,0 +1,298 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModule struct {
+	module.Base
+	URL string `yaml:"url"`
+
+	mux     *sync.Mutex
+	cleaned *[]string
+	check   func(url string) bool
+}
+
+func (m *testModule) Init() bool  { return true }
+func (m *testModule) Check() bool { return m.check == nil || m.check(m.URL) }
+func (m *testModule) Charts() *module.Charts {
+	return &module.Charts{{ID: "requests", Title: "Requests", Units: "requests/s", Fam: "requests", Ctx: "test.requests"}}
+}
+func (m *testModule) Collect() map[string]int64 { return map[string]int64{"requests": 1} }
+func (m *testModule) Cleanup() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	*m.cleaned = append(*m.cleaned, m.URL)
+}
+
+type testEnv struct {
+	mux     sync.Mutex
+	cleaned []string
+	out     *bytes.Buffer
+	check   func(url string) bool
+}
+
+func newTestManager(env *testEnv) *Manager {
+	env.out = &bytes.Buffer{}
+	mgr := NewManager("go.d", env.out)
+	mgr.Registry = module.Registry{
+		"test": module.Creator{
+			Create: func() module.Module {
+				return &testModule{mux: &env.mux, cleaned: &env.cleaned, check: env.check}
+			},
+		},
+	}
+	return mgr
+}
+
+func (env *testEnv) cleanedURLs() []string {
+	env.mux.Lock()
+	defer env.mux.Unlock()
+	return append([]string(nil), env.cleaned...)
+}
+
+func newGroup(source string, names ...string) *Group {
+	group := &Group{Source: source}
+	for _, name := range names {
+		group.Configs = append(group.Configs, Config{
+			Module: "test",
+			Conf:   map[string]interface{}{"name": name, "url": "http://" + name},
+		})
+	}
+	return group
+}
+
+func TestManager_processUpdate(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "a1", "a2"), newGroup("b", "b1")}})
+	waitChecks(mgr)
+
+	assert.Len(t, mgr.targets, 2)
+	assert.Equal(t, map[string]bool{"test_a1": true, "test_a2": true, "test_b1": true}, mgr.running)
+	assert.Len(t, mgr.queue.jobs, 3)
+
+	// the same snapshot doesn't restart jobs
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "a1", "a2"), newGroup("b", "b1")}})
+	waitChecks(mgr)
+	assert.Len(t, mgr.queue.jobs, 3)
+	assert.Empty(t, env.out.String())
+
+	// 'b' is gone, 'a' has changed
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "a1")}})
+	waitChecks(mgr)
+
+	assert.Len(t, mgr.targets, 1)
+	assert.Equal(t, map[string]bool{"test_a1": true}, mgr.running)
+	assert.Len(t, mgr.queue.jobs, 1)
+
+	out := env.out.String()
+	assert.Contains(t, out, "CHART 'test_b1.requests' '' 'Requests' 'requests/s' 'requests' 'test.requests' '' '0' '1' 'obsolete' 'go.d' 'test'")
+	assert.Contains(t, out, "CHART 'netdata.execution_time_of_test_b1'")
+	assert.Contains(t, out, "CHART 'test_a2.requests'")
+
+	waitFor(t, func() bool { return len(env.cleanedURLs()) == 3 })
+	assert.ElementsMatch(t, []string{"http://a1", "http://a2", "http://b1"}, env.cleanedURLs())
+
+	// other discoverer targets are not affected
+	mgr.processUpdate(update{discoverer: 1, groups: []*Group{newGroup("c", "c1")}})
+	waitChecks(mgr)
+	mgr.processUpdate(update{discoverer: 1})
+	assert.Equal(t, map[string]bool{"test_a1": true}, mgr.running)
+
+	mgr.stopAll()
+	assert.Empty(t, mgr.running)
+	assert.Empty(t, mgr.queue.jobs)
+}
+
+func TestManager_processUpdate_DuplicateName(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "job"), newGroup("b", "job")}})
+	waitChecks(mgr)
+
+	assert.Len(t, mgr.running, 1)
+	assert.Len(t, mgr.queue.jobs, 1)
+}
+
+func TestManager_processUpdate_StaticJobs(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+	mgr.StaticJobs = map[string]bool{"test_static": true}
+
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "static", "a1")}})
+	waitChecks(mgr)
+
+	assert.Equal(t, map[string]bool{"test_a1": true}, mgr.running)
+	assert.Len(t, mgr.queue.jobs, 1)
+
+	mgr.stopAll()
+}
+
+func TestManager_processUpdate_SlowCheck(t *testing.T) {
+	release := make(chan struct{})
+	env := &testEnv{check: func(url string) bool {
+		if url == "http://slow" {
+			<-release
+		}
+		return true
+	}}
+	mgr := newTestManager(env)
+
+	// the slow check doesn't block the other targets
+	mgr.processUpdate(update{groups: []*Group{newGroup("a", "slow")}})
+	mgr.processUpdate(update{discoverer: 1, groups: []*Group{newGroup("b", "b1")}})
+	mgr.processCheck(<-mgr.checked)
+
+	assert.Equal(t, map[string]bool{"test_slow": true, "test_b1": true}, mgr.running)
+	assert.Len(t, mgr.queue.jobs, 1)
+
+	// 'a' is gone while its job is being checked
+	mgr.processUpdate(update{})
+	assert.Equal(t, map[string]bool{"test_b1": true}, mgr.running)
+
+	close(release)
+	waitChecks(mgr)
+	assert.Len(t, mgr.queue.jobs, 1)
+	waitFor(t, func() bool { return len(env.cleanedURLs()) == 1 })
+	assert.Equal(t, []string{"http://slow"}, env.cleanedURLs())
+
+	mgr.stopAll()
+}
+
+func TestManager_processUpdate_UnknownModule(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+
+	group := newGroup("a", "a1")
+	group.Configs[0].Module = "unknown"
+	mgr.processUpdate(update{groups: []*Group{group}})
+	waitChecks(mgr)
+
+	assert.Empty(t, mgr.running)
+	assert.Empty(t, mgr.targets[targetKey{source: "a"}].jobs)
+}
+
+func TestManager_recheck(t *testing.T) {
+	var ready bool
+	env := &testEnv{check: func(string) bool { return ready }}
+	mgr := newTestManager(env)
+
+	now := time.Now()
+	mgr.now = func() time.Time { return now }
+
+	group := newGroup("a", "a1", "a2")
+	group.Configs[0].Conf["autodetection_retry"] = 10
+	mgr.processUpdate(update{groups: []*Group{group}})
+	waitChecks(mgr)
+	assert.Empty(t, mgr.running)
+
+	ready = true
+	now = now.Add(time.Second * 5)
+	mgr.recheck()
+	waitChecks(mgr)
+	assert.Empty(t, mgr.running)
+
+	// only the job with autodetection_retry is rechecked
+	now = now.Add(time.Second * 5)
+	mgr.recheck()
+	waitChecks(mgr)
+	assert.Equal(t, map[string]bool{"test_a1": true}, mgr.running)
+
+	mgr.stopAll()
+}
+
+func TestManager_createJob_UpdateEvery(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+	mgr.MinUpdateEvery = 5
+
+	job, err := mgr.createJob(Config{Module: "test", Conf: map[string]interface{}{"name": "a", "update_every": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 5, job.UpdateEvery)
+
+	job, err = mgr.createJob(Config{Module: "test", Conf: map[string]interface{}{"name": "a", "update_every": 10}})
+	require.NoError(t, err)
+	assert.Equal(t, 10, job.UpdateEvery)
+}
+
+type testDiscoverer struct {
+	groups []*Group
+}
+
+func (d testDiscoverer) Discover(ctx context.Context, out chan<- []*Group) {
+	select {
+	case <-ctx.Done():
+	case out <- d.groups:
+	}
+	<-ctx.Done()
+}
+
+func TestManager_Run(t *testing.T) {
+	env := &testEnv{}
+	mgr := newTestManager(env)
+	mgr.discoverers = []Discoverer{testDiscoverer{groups: []*Group{newGroup("a", "a1")}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { mgr.Run(ctx); close(done) }()
+
+	waitFor(t, func() bool { return len(env.cleanedURLs()) == 0 && mgr.queue.len() == 1 })
+
+	cancel()
+	<-done
+	waitFor(t, func() bool { return len(env.cleanedURLs()) == 1 })
+}
+
+// waitChecks processes the results of the checks in progress, it is what Run does.
+func waitChecks(mgr *Manager) {
+	for mgr.checking > 0 {
+		mgr.processCheck(<-mgr.checked)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatal("condition is not met")
+}
+
+func TestJobName(t *testing.T) {
+	assert.Equal(t, "node_1_web_api", JobName("node.1", "web api"))
+}
+
+func TestRenderConfig(t *testing.T) {
+	tmpl, err := NewConfigTemplate("test", "name: {{.Name}}\nurl: http://{{.Address}}:{{.Port}}\n")
+	require.NoError(t, err)
+
+	conf, err := RenderConfig(tmpl, struct {
+		Name, Address string
+		Port          int
+	}{"web", "10.0.0.1", 80})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "web", "url": "http://10.0.0.1:80"}, conf)
+
+	tmpl, err = NewConfigTemplate("test", "name: {{.Missing}}")
+	require.NoError(t, err)
+	_, err = RenderConfig(tmpl, struct{}{})
+	assert.Error(t, err)
+
+	tmpl, err = NewConfigTemplate("test", "name: [")
+	require.NoError(t, err)
+	_, err = RenderConfig(tmpl, struct{}{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NewConfigTemplate parses the job configuration template.
+func NewConfigTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// RenderConfig executes the job configuration template with the target data and parses the result as YAML.
+func RenderConfig(tmpl *template.Template, data interface{}) (map[string]interface{}, error) {
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error on executing '%s' template : %v", tmpl.Name(), err)
+	}
+
+	conf := make(map[string]interface{})
+
+	if err := yaml.Unmarshal(buf.Bytes(), &conf); err != nil {
+		return nil, fmt.Errorf("error on parsing '%s' template result : %v", tmpl.Name(), err)
+	}
+
+	return conf, nil
+}
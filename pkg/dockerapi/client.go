@@ -0,0 +1,215 @@
+// Package dockerapi is a minimal Docker Engine API client.
+package dockerapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAddress is the Docker daemon default socket.
+const DefaultAddress = "unix:///var/run/docker.sock"
+
+// Container is a container from the containers list.
+type Container struct {
+	ID         string `json:"Id"`
+	Names      []string
+	Image      string
+	State      string
+	Labels     map[string]string
+	Ports      []Port
+	HostConfig struct {
+		NetworkMode string
+	}
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string
+		}
+	}
+}
+
+// Port is a container port.
+type Port struct {
+	IP          string
+	PrivatePort int
+	PublicPort  int
+	Type        string
+}
+
+// Event is a Docker daemon event.
+type Event struct {
+	Type   string
+	Action string
+	Actor  struct {
+		ID         string
+		Attributes map[string]string
+	}
+}
+
+// Name returns the container name without the leading slash.
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// NewClient creates Client. The address is either 'unix://<socket path>', 'tcp://<host>:<port>' or a http(s) URL.
+// The timeout doesn't apply to the events stream.
+func NewClient(address string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing address '%s' : %v", address, err)
+	}
+
+	transport := &http.Transport{}
+	baseURL := address
+
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return nil, fmt.Errorf("address '%s' : socket path is not set", address)
+		}
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// the host is ignored by the dialer
+		baseURL = "http://docker"
+	case "tcp":
+		baseURL = "http://" + u.Host
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("address '%s' : unsupported scheme '%s'", address, u.Scheme)
+	}
+
+	return &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: timeout, Transport: transport},
+		streamClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// Client is a Docker Engine API client.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	streamClient *http.Client
+}
+
+// Containers returns the running containers, or all the containers if all is true.
+func (c *Client) Containers(all bool) ([]Container, error) {
+	uri := "/containers/json"
+	if all {
+		uri += "?all=true"
+	}
+
+	var containers []Container
+	if err := c.getJSON(uri, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// ContainerStats returns the container resource usage statistics snapshot.
+func (c *Client) ContainerStats(id string) (*ContainerStats, error) {
+	var stats ContainerStats
+	// 'one-shot' skips waiting for the second CPU sample, older daemons ignore it
+	if err := c.getJSON("/containers/"+url.PathEscape(id)+"/stats?stream=false&one-shot=true", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Events opens the daemon events stream, the filters are the API 'filters' query parameter.
+// The stream is closed when the context is canceled or by Events.Close.
+func (c *Client) Events(ctx context.Context, filters map[string][]string) (*Events, error) {
+	uri := "/events"
+	if len(filters) > 0 {
+		b, err := json.Marshal(filters)
+		if err != nil {
+			return nil, err
+		}
+		uri += "?filters=" + url.QueryEscape(string(b))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := c.streamClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error on request to %s : %v", uri, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		closeBody(resp)
+		return nil, fmt.Errorf("%s returned HTTP status %d", uri, resp.StatusCode)
+	}
+
+	return &Events{body: resp.Body, dec: json.NewDecoder(resp.Body)}, nil
+}
+
+// Events is the daemon events stream.
+type Events struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// ErrStreamClosed is returned by Events.Next when the daemon has closed the stream.
+var ErrStreamClosed = errors.New("events stream is closed")
+
+// Next blocks until the next event.
+func (e *Events) Next() (Event, error) {
+	var event Event
+	if err := e.dec.Decode(&event); err != nil {
+		if err == io.EOF {
+			return event, ErrStreamClosed
+		}
+		return event, fmt.Errorf("error on decoding event : %v", err)
+	}
+	return event, nil
+}
+
+// Close closes the stream.
+func (e *Events) Close() error {
+	return e.body.Close()
+}
+
+func (c *Client) getJSON(uri string, dst interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+uri, nil)
+	if err != nil {
+		return fmt.Errorf("error on creating request : %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error on request to %s : %v", uri, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned HTTP status %d", uri, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("error on decoding resp from %s : %v", uri, err)
+	}
+
+	return nil
+}
+
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
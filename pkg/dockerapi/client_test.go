@@ -0,0 +1,98 @@
+package dockerapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainers = `[
+  {"Id": "1111", "Names": ["/redis"], "Image": "redis:5", "State": "running", "Labels": {"env": "test"},
+   "Ports": [{"PrivatePort": 6379, "Type": "tcp"}],
+   "HostConfig": {"NetworkMode": "default"},
+   "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.2"}}}}
+]`
+
+func newTestUnixServer(t *testing.T, handler http.Handler) (ts *httptest.Server, address string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "dockerapi")
+	require.NoError(t, err)
+
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	ts = httptest.NewUnstartedServer(handler)
+	_ = ts.Listener.Close()
+	ts.Listener = l
+	ts.Start()
+
+	return ts, "unix://" + socket, func() { ts.Close(); _ = os.RemoveAll(dir) }
+}
+
+func TestNewClient(t *testing.T) {
+	for _, address := range []string{DefaultAddress, "tcp://127.0.0.1:2375", "http://127.0.0.1:2375"} {
+		_, err := NewClient(address, time.Second)
+		assert.NoErrorf(t, err, address)
+	}
+
+	for _, address := range []string{"unix://", "ftp://127.0.0.1", "::"} {
+		_, err := NewClient(address, time.Second)
+		assert.Errorf(t, err, address)
+	}
+}
+
+func TestClient_Containers(t *testing.T) {
+	_, address, cleanup := newTestUnixServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testContainers))
+	}))
+	defer cleanup()
+
+	client, err := NewClient(address, time.Second)
+	require.NoError(t, err)
+
+	containers, err := client.Containers(false)
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+
+	c := containers[0]
+	assert.Equal(t, "1111", c.ID)
+	assert.Equal(t, "redis", c.Name())
+	assert.Equal(t, "172.17.0.2", c.NetworkSettings.Networks["bridge"].IPAddress)
+	assert.Equal(t, []Port{{PrivatePort: 6379, Type: "tcp"}}, c.Ports)
+}
+
+func TestClient_Events(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `{"type":["container"]}`, r.URL.Query().Get("filters"))
+		_, _ = w.Write([]byte(`{"Type": "container", "Action": "start", "Actor": {"ID": "1111", "Attributes": {"name": "redis"}}}` + "\n"))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, time.Second)
+	require.NoError(t, err)
+
+	events, err := client.Events(context.Background(), map[string][]string{"type": {"container"}})
+	require.NoError(t, err)
+	defer events.Close()
+
+	event, err := events.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "start", event.Action)
+	assert.Equal(t, "redis", event.Actor.Attributes["name"])
+
+	_, err = events.Next()
+	assert.Equal(t, ErrStreamClosed, err)
+}
@@ -0,0 +1,53 @@
+package dockerapi
+
+// ContainerStats is the container resource usage statistics.
+type ContainerStats struct {
+	CPUStats    CPUStats                `json:"cpu_stats"`
+	MemoryStats MemoryStats             `json:"memory_stats"`
+	Networks    map[string]NetworkStats `json:"networks"`
+	BlkioStats  BlkioStats              `json:"blkio_stats"`
+}
+
+// CPUStats is the container CPU usage, the usage is in nanoseconds.
+type CPUStats struct {
+	CPUUsage struct {
+		TotalUsage        int64 `json:"total_usage"`
+		UsageInKernelmode int64 `json:"usage_in_kernelmode"`
+		UsageInUsermode   int64 `json:"usage_in_usermode"`
+	} `json:"cpu_usage"`
+	SystemUsage int64 `json:"system_cpu_usage"`
+	OnlineCPUs  int64 `json:"online_cpus"`
+}
+
+// MemoryStats is the container memory usage in bytes.
+type MemoryStats struct {
+	Usage int64 `json:"usage"`
+	Limit int64 `json:"limit"`
+	// Stats are the cgroup memory stats, the keys differ between cgroup v1 and v2.
+	Stats map[string]int64 `json:"stats"`
+}
+
+// NetworkStats is the container network interface usage.
+type NetworkStats struct {
+	RxBytes   int64 `json:"rx_bytes"`
+	RxPackets int64 `json:"rx_packets"`
+	RxErrors  int64 `json:"rx_errors"`
+	RxDropped int64 `json:"rx_dropped"`
+	TxBytes   int64 `json:"tx_bytes"`
+	TxPackets int64 `json:"tx_packets"`
+	TxErrors  int64 `json:"tx_errors"`
+	TxDropped int64 `json:"tx_dropped"`
+}
+
+// BlkioStats is the container block IO usage.
+type BlkioStats struct {
+	IOServiceBytesRecursive []BlkioStatEntry `json:"io_service_bytes_recursive"`
+}
+
+// BlkioStatEntry is a block device operation counter.
+type BlkioStatEntry struct {
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Op    string `json:"op"`
+	Value int64  `json:"value"`
+}
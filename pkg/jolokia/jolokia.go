@@ -0,0 +1,186 @@
+// Package jolokia is a minimal Jolokia (JMX over HTTP) client, it supports only the bulk 'read' requests.
+package jolokia
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+)
+
+// ReadRequest is a Jolokia 'read' request. A MBean pattern reads all the matching MBeans.
+type ReadRequest struct {
+	Type      string   `json:"type"`
+	MBean     string   `json:"mbean"`
+	Attribute []string `json:"attribute,omitempty"`
+	Path      string   `json:"path,omitempty"`
+}
+
+// ReadResponse is a Jolokia 'read' response.
+type ReadResponse struct {
+	Request ReadRequest     `json:"request"`
+	Value   json.RawMessage `json:"value"`
+	Status  int             `json:"status"`
+	Error   string          `json:"error"`
+}
+
+// Attributes are the MBean attributes values. The numbers are float64, the composite values are maps.
+type Attributes map[string]interface{}
+
+// NewReadRequest creates ReadRequest.
+func NewReadRequest(mbean string, attributes ...string) ReadRequest {
+	return ReadRequest{Type: "read", MBean: mbean, Attribute: attributes}
+}
+
+// MBeans returns the attributes values by the MBean name. Jolokia responds with the MBean name keyed
+// values only to the patterns reads, the value of an exact MBean read is keyed by the request MBean name.
+func (r ReadResponse) MBeans() (map[string]Attributes, error) {
+	if r.Status != http.StatusOK {
+		return nil, fmt.Errorf("error on reading '%s' : status %d : %s", r.Request.MBean, r.Status, r.Error)
+	}
+
+	if IsPattern(r.Request.MBean) {
+		var mbeans map[string]Attributes
+		if err := json.Unmarshal(r.Value, &mbeans); err != nil {
+			return nil, fmt.Errorf("error on decoding '%s' value : %v", r.Request.MBean, err)
+		}
+		return mbeans, nil
+	}
+
+	var attrs Attributes
+	if err := json.Unmarshal(r.Value, &attrs); err != nil {
+		return nil, fmt.Errorf("error on decoding '%s' value : %v", r.Request.MBean, err)
+	}
+	return map[string]Attributes{r.Request.MBean: attrs}, nil
+}
+
+// Client is a Jolokia agent client.
+type Client struct {
+	httpClient *http.Client
+	request    web.Request
+}
+
+// New creates Client, the request URL is the Jolokia agent endpoint (e.g. http://127.0.0.1:8161/api/jolokia).
+func New(client *http.Client, request web.Request) *Client {
+	request.Method = http.MethodPost
+	return &Client{httpClient: client, request: request}
+}
+
+// Read makes a bulk read request. The error is returned only if the whole request failed,
+// the single reads errors are in the responses statuses.
+func (c *Client) Read(reqs ...ReadRequest) ([]ReadResponse, error) {
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	req := c.request
+	req.Body = string(body)
+	// missing attributes and the attributes read errors must not fail the whole read
+	req.URI = "?ignoreErrors=true&canonicalNaming=false"
+
+	httpReq, err := web.NewHTTPRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request : %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	defer closeBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to %s : %v", httpReq.URL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %d", httpReq.URL, resp.StatusCode)
+	}
+
+	var responses []ReadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, fmt.Errorf("error on decoding response from %s : %v", httpReq.URL, err)
+	}
+
+	if len(responses) != len(reqs) {
+		return nil, fmt.Errorf("%s returned %d responses to %d requests", httpReq.URL, len(responses), len(reqs))
+	}
+
+	return responses, nil
+}
+
+// IsPattern reports whether the MBean name is a pattern.
+func IsPattern(mbean string) bool {
+	return strings.ContainsAny(mbean, "*?")
+}
+
+// ParseObjectName parses the MBean name ("domain:key1=value1,key2=value2"), the quoted values are unquoted.
+// The property list wildcard ("domain:key1=value1,*") is allowed, it is not in the returned properties.
+func ParseObjectName(name string) (domain string, props map[string]string, err error) {
+	idx := strings.IndexByte(name, ':')
+	if idx <= 0 {
+		return "", nil, fmt.Errorf("invalid object name '%s'", name)
+	}
+	domain, props = name[:idx], make(map[string]string)
+
+	for _, prop := range splitProperties(name[idx+1:]) {
+		if prop == "*" {
+			continue
+		}
+		kv := strings.SplitN(prop, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return "", nil, fmt.Errorf("invalid object name '%s'", name)
+		}
+		props[kv[0]] = unquote(kv[1])
+	}
+
+	return domain, props, nil
+}
+
+// splitProperties splits the properties by commas, the commas in quoted values are not separators.
+func splitProperties(s string) []string {
+	var (
+		props  []string
+		quoted bool
+		start  int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				props = append(props, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(props, s[start:])
+}
+
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
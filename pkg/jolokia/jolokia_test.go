@@ -0,0 +1,97 @@
+package jolokia
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Read(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("ignoreErrors"))
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []ReadRequest
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		require.Len(t, reqs, 3)
+
+		_, _ = w.Write([]byte(`[
+{"request": {"type": "read", "mbean": "java.lang:type=Memory", "attribute": ["HeapMemoryUsage"]},
+ "value": {"HeapMemoryUsage": {"used": 1024, "max": 4096}}, "status": 200},
+{"request": {"type": "read", "mbean": "java.lang:type=GarbageCollector,name=*", "attribute": ["CollectionCount"]},
+ "value": {"java.lang:name=G1 Young Generation,type=GarbageCollector": {"CollectionCount": 10},
+           "java.lang:name=G1 Old Generation,type=GarbageCollector": {"CollectionCount": 1}}, "status": 200},
+{"request": {"type": "read", "mbean": "java.lang:type=Unknown"},
+ "error": "javax.management.InstanceNotFoundException : java.lang:type=Unknown", "status": 404}
+]`))
+	}))
+	defer ts.Close()
+
+	client := New(http.DefaultClient, web.Request{URL: ts.URL + "/jolokia"})
+	resps, err := client.Read(
+		NewReadRequest("java.lang:type=Memory", "HeapMemoryUsage"),
+		NewReadRequest("java.lang:type=GarbageCollector,name=*", "CollectionCount"),
+		NewReadRequest("java.lang:type=Unknown"),
+	)
+	require.NoError(t, err)
+	require.Len(t, resps, 3)
+
+	mbeans, err := resps[0].MBeans()
+	require.NoError(t, err)
+	heap := mbeans["java.lang:type=Memory"]["HeapMemoryUsage"].(map[string]interface{})
+	assert.Equal(t, 1024.0, heap["used"])
+
+	mbeans, err = resps[1].MBeans()
+	require.NoError(t, err)
+	assert.Len(t, mbeans, 2)
+	assert.Equal(t, 10.0, mbeans["java.lang:name=G1 Young Generation,type=GarbageCollector"]["CollectionCount"])
+
+	_, err = resps[2].MBeans()
+	assert.Error(t, err)
+}
+
+func TestClient_Read_404(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	_, err := New(http.DefaultClient, web.Request{URL: ts.URL}).Read(NewReadRequest("java.lang:type=Memory"))
+	assert.Error(t, err)
+}
+
+func TestParseObjectName(t *testing.T) {
+	domain, props, err := ParseObjectName(`org.apache.activemq:type=Broker,brokerName=localhost,destinationType=Queue,destinationName=orders.eu`)
+	require.NoError(t, err)
+	assert.Equal(t, "org.apache.activemq", domain)
+	assert.Equal(t, map[string]string{
+		"type":            "Broker",
+		"brokerName":      "localhost",
+		"destinationType": "Queue",
+		"destinationName": "orders.eu",
+	}, props)
+
+	_, props, err = ParseObjectName(`org.apache.activemq.artemis:broker="0.0.0.0",component=addresses,address="a,b",queue="q\"1"`)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", props["broker"])
+	assert.Equal(t, "a,b", props["address"])
+	assert.Equal(t, `q"1`, props["queue"])
+
+	domain, props, err = ParseObjectName("java.lang:type=GarbageCollector,*")
+	require.NoError(t, err)
+	assert.Equal(t, "java.lang", domain)
+	assert.Equal(t, map[string]string{"type": "GarbageCollector"}, props)
+
+	_, _, err = ParseObjectName("no domain")
+	assert.Error(t, err)
+	_, _, err = ParseObjectName("domain:novalue")
+	assert.Error(t, err)
+}
@@ -28,6 +28,10 @@ func ToMap(s ...interface{}) map[string]int64 {
 }
 
 func toMap(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+	// nil pointers to the metrics implement Value, but can't be written
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return
+	}
 	if value.CanInterface() {
 		val, ok := value.Interface().(Value)
 		if ok {
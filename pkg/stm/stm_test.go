@@ -214,6 +214,23 @@ func TestToMap_ptr(t *testing.T) {
 	assert.EqualValuesf(t, expected, stm.ToMap(&s), "ptr test")
 }
 
+func TestToMap_metricsPtr(t *testing.T) {
+	s := struct {
+		G   *metrics.Gauge `stm:"g"`
+		Nil *metrics.Gauge `stm:"nil"`
+	}{
+		G: new(metrics.Gauge),
+	}
+	s.G.Set(3)
+
+	expected := map[string]int64{
+		"g": 3,
+	}
+
+	assert.EqualValuesf(t, expected, stm.ToMap(s), "value test")
+	assert.EqualValuesf(t, expected, stm.ToMap(&s), "ptr test")
+}
+
 func TestToMap_invalidType(t *testing.T) {
 	s := struct {
 		Str string `stm:"int"`
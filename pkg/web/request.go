@@ -2,22 +2,25 @@ package web
 
 import (
 	"encoding/base64"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
 )
 
 // Request is a struct that contains the fields that are needed to newHTTPClient *http.Request.
 type Request struct {
-	URI           string            `yaml:"-"`
-	URL           string            `yaml:"url" validate:"required,url"`
-	Body          string            `yaml:"body"`
-	Method        string            `yaml:"method" validate:"isdefault|oneof=GET POST HEAD PUT BATCH"`
-	Headers       map[string]string `yaml:"headers"`
-	Username      string            `yaml:"username"`
-	Password      string            `yaml:"password"`
-	ProxyUsername string            `yaml:"proxy_username"`
-	ProxyPassword string            `yaml:"proxy_password"`
+	URI             string            `yaml:"-"`
+	URL             string            `yaml:"url" validate:"required,url"`
+	Body            string            `yaml:"body"`
+	Method          string            `yaml:"method" validate:"isdefault|oneof=GET POST HEAD PUT BATCH"`
+	Headers         map[string]string `yaml:"headers"`
+	Username        string            `yaml:"username"`
+	Password        string            `yaml:"password"`
+	ProxyUsername   string            `yaml:"proxy_username"`
+	ProxyPassword   string            `yaml:"proxy_password"`
+	BearerTokenFile string            `yaml:"bearer_token_file"` // re-read on every request (token rotation)
 }
 
 // NewHTTPRequest creates a new *http.Requests based Request fields
@@ -44,6 +47,14 @@ func NewHTTPRequest(req Request) (*http.Request, error) {
 			"Basic "+base64.StdEncoding.EncodeToString([]byte(req.ProxyUsername+":"+req.ProxyPassword)))
 	}
 
+	if req.BearerTokenFile != "" {
+		token, err := ioutil.ReadFile(req.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("error on reading bearer token file : %v", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
 	for k, v := range req.Headers {
 		if k == "host" {
 			httpReq.Host = v
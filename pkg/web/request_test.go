@@ -2,11 +2,14 @@ package web
 
 import (
 	"encoding/base64"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -45,6 +48,32 @@ func TestRawRequest_CreateRequest(t *testing.T) {
 	assert.Equal(t, headerValue, req.Header.Get(headerKey))
 }
 
+func TestRawRequest_CreateRequest_BearerTokenFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, _ = f.WriteString("token1\n")
+	_ = f.Close()
+
+	r := Request{BearerTokenFile: f.Name()}
+
+	req, err := NewHTTPRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token1", req.Header.Get("Authorization"))
+
+	// the token is rotated
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("token2"), 0600))
+
+	req, err = NewHTTPRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token2", req.Header.Get("Authorization"))
+
+	r.BearerTokenFile = f.Name() + ".not_exists"
+	_, err = NewHTTPRequest(r)
+	assert.Error(t, err)
+}
+
 func parseBasicAuth(auth string) (username, password string, ok bool) {
 	const prefix = "Basic "
 	// Case insensitive prefix match. See Issue 22736.